@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/server"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayTopic       string
+	replayFrom        string
+	replayTo          string
+	replayFilter      string
+	replayPartition   int
+	replayDryRun      bool
+	replayRepublish   bool
+	replayTargetTopic string
+	replayChannel     string
+	replayPriority    string
+)
+
+func init() {
+	replayCmd.Flags().StringVarP(&replayTopic, "topic", "t", "", "Dispatch topic to read (required)")
+	replayCmd.Flags().StringVar(&replayFrom, "from", "", "First offset or RFC 3339 time, default the oldest message")
+	replayCmd.Flags().StringVar(&replayTo, "to", "", "Last offset or RFC 3339 time, default the newest message")
+	replayCmd.Flags().StringVar(&replayFilter, "filter", "", "Only replay matching messages, e.g. category=sms,type=Otp")
+	replayCmd.Flags().IntVar(&replayPartition, "partition", -1, "Only read this partition, -1 reads all")
+	replayCmd.Flags().BoolVar(&replayDryRun, "dry-run", false, "List the matching messages without replaying them")
+	replayCmd.Flags().BoolVar(&replayRepublish, "republish", false, "Publish the messages to the dispatch topic instead of processing them here")
+	replayCmd.Flags().StringVar(&replayTargetTopic, "target-topic", "", "Topic to republish to, default the topic read")
+	replayCmd.Flags().StringVarP(&replayChannel, "channel", "c", "", "Channel name to process as, required unless republishing")
+	replayCmd.Flags().StringVarP(&replayPriority, "priority", "p", "normal", "Set the priority to process as: high or normal")
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Reprocess the messages of a topic range.",
+	Long:  "Read a range of a dispatch topic without a consumer group and process the matching messages again, or republish them to the dispatch topic.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if replayTopic == "" {
+			log.Fatalf("Missing --topic")
+		}
+
+		from, err := kafkaClient.ParseBound(replayFrom)
+		if err != nil {
+			log.Fatalf("Invalid --from: %v", err)
+		}
+		to, err := kafkaClient.ParseBound(replayTo)
+		if err != nil {
+			log.Fatalf("Invalid --to: %v", err)
+		}
+		filter, err := server.ParseReplayFilter(replayFilter)
+		if err != nil {
+			log.Fatalf("Invalid --filter: %v", err)
+		}
+
+		targetTopic := replayTargetTopic
+		if targetTopic == "" {
+			targetTopic = replayTopic
+		}
+
+		cfg := config.LoadConfigFromOS()
+		cfg.Project.Priority = replayPriority
+
+		ip, err := getCurrentIPv4()
+		if err != nil {
+			log.Fatalf("Failed to get IP: %v", err)
+		}
+		cfg.Project.ServerIP = ip
+
+		// Processing needs the providers of a channel, republishing does not.
+		channelName := ""
+		if !replayRepublish && !replayDryRun {
+			if replayPriority != "high" && replayPriority != "normal" {
+				log.Fatalf("Invalid priority for replay: %s", replayPriority)
+			}
+
+			for _, c := range constants.CHANNELS {
+				if strings.EqualFold(replayChannel, c) {
+					channelName = c
+				}
+			}
+			if channelName == "" {
+				log.Fatalf("Invalid channel: %s", replayChannel)
+			}
+			cfg.Project.Channel = channelName
+		}
+
+		s := server.NewServer(cfg)
+		if err := s.Replay(channelName, replayPriority, &server.ReplayOptions{
+			Topic:       replayTopic,
+			Partition:   replayPartition,
+			From:        from,
+			To:          to,
+			Filter:      filter,
+			DryRun:      replayDryRun,
+			Republish:   replayRepublish,
+			TargetTopic: targetTopic,
+		}); err != nil {
+			log.Fatalf("Failed to replay: %v", err)
+		}
+	},
+}
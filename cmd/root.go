@@ -20,7 +20,7 @@ var channel string
 
 func init() {
 	runCmd.Flags().StringVarP(&channel, "channel", "c", "", "Channel name to be handled (required)")
-	runCmd.PersistentFlags().StringVarP(&priority, "priority", "p", "normal", "Set the priority")
+	runCmd.PersistentFlags().StringVarP(&priority, "priority", "p", "normal", "Set the priority: high, normal or mixed to consume both with weighted scheduling")
 }
 
 var rootCmd = &cobra.Command{
@@ -56,17 +56,11 @@ var runCmd = &cobra.Command{
 
 		s := server.NewServer(cfg)
 
-		channels := []string{
-			constants.CHANNEL_JMO,
-			constants.CHANNEL_SMILE,
-			constants.CHANNEL_SIPP,
-			constants.CHANNEL_SIDIA,
-			constants.CHANNEL_PERISAI,
-		}
-
 		found := false
-		for _, c := range channels {
+		for _, c := range constants.CHANNELS {
 			if strings.EqualFold(channel, c) {
+				cfg.Project.Channel = c
+
 				if err := s.Run(c, priority); err != nil {
 					log.Fatalf("Failed to run server: %v", err)
 				}
@@ -83,7 +77,7 @@ var runCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.AddCommand(versionCmd, runCmd)
+	rootCmd.AddCommand(versionCmd, runCmd, replayCmd)
 }
 
 func Execute() {
@@ -2,21 +2,30 @@ package config
 
 import (
 	"os"
+	"strings"
 
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
 	loggerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
 	metricClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/metric"
+	schemaRegistry "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/schema_registry"
 	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
 )
 
 type Config struct {
-	Project        *Project             `mapstructure:"PROJECT"`
-	Logger         *loggerClient.Config `mapstructure:"LOGGER_CLIENT"`
-	Kafka          *kafkaClient.Config  `mapstructure:"KAFKA_CLIENT"`
-	KafkaTopic     *KafkaTopic          `mapstructure:"KAFKA_TOPIC"`
-	Tracer         *tracerClient.Config `mapstructure:"TRACER_CLIENT"`
-	Metric         *metricClient.Config `mapstructure:"METRIC_CLIENT"`
-	ProviderClient *ProviderClient      `mapstructure:"SERVICE_CLIENT"`
+	Project           *Project               `mapstructure:"PROJECT"`
+	Logger            *loggerClient.Config   `mapstructure:"LOGGER_CLIENT"`
+	Kafka             *kafkaClient.Config    `mapstructure:"KAFKA_CLIENT"`
+	KafkaTopic        *KafkaTopic            `mapstructure:"KAFKA_TOPIC"`
+	Tracer            *tracerClient.Config   `mapstructure:"TRACER_CLIENT"`
+	Metric            *metricClient.Config   `mapstructure:"METRIC_CLIENT"`
+	ProviderClient    *ProviderClient        `mapstructure:"SERVICE_CLIENT"`
+	ProviderSelection *ProviderSelection     `mapstructure:"PROVIDER"`
+	Retry             *Retry                 `mapstructure:"RETRY"`
+	Scheduler         *Scheduler             `mapstructure:"SCHEDULER"`
+	Spool             *Spool                 `mapstructure:"SPOOL"`
+	SchemaRegistry    *schemaRegistry.Config `mapstructure:"SCHEMA_REGISTRY"`
+	Sms               *Sms                   `mapstructure:"SMS"`
 }
 
 type Project struct {
@@ -24,21 +33,86 @@ type Project struct {
 	Version     string `mapstructure:"VERSION"`
 	Environment string `mapstructure:"ENVIRONMENT"`
 	Priority    string `mapstructure:"PRIORITY"`
+	Channel     string `mapstructure:"CHANNEL"`
 	ServerIP    string `mapstructure:"SERVER_IP"`
 }
 
+// Retry bounds the rescheduling of messages a provider asked to retry later.
+// A message is dropped after MaxAttempts, and delays above MaxDelay are capped.
+// Rescheduled messages wait on the dispatch topic name plus DelayTopicSuffix
+// until they are due.
+type Retry struct {
+	MaxAttempts      string `mapstructure:"MAX_ATTEMPTS"`
+	MaxDelay         string `mapstructure:"MAX_DELAY"`
+	DelayTopicSuffix string `mapstructure:"DELAY_TOPIC_SUFFIX"`
+}
+
+// Scheduler shares workers between high and regular topics when running with
+// the mixed priority. Workers defaults to the pool size per topic of one
+// priority when empty.
+type Scheduler struct {
+	HighWeight    string `mapstructure:"HIGH_WEIGHT"`
+	RegularWeight string `mapstructure:"REGULAR_WEIGHT"`
+	Workers       string `mapstructure:"WORKERS"`
+}
+
+// Spool keeps tracking events that failed to publish on disk until the
+// flusher gets them out. Each process uses a <channel>_<priority> directory
+// under Dir, an empty Dir disables the spool.
+type Spool struct {
+	Dir           string `mapstructure:"DIR"`
+	MaxBytes      string `mapstructure:"MAX_BYTES"`
+	SegmentBytes  string `mapstructure:"SEGMENT_BYTES"`
+	FlushInterval string `mapstructure:"FLUSH_INTERVAL"`
+}
+
+// Sms shapes messages before they reach the gateway. PhoneFormat is local
+// (08...), the form the gateways were sent before numbers were normalized, or
+// e164 (+628...). LengthPolicy decides what happens to messages over
+// MaxSegments, using the selection syntax of the providers keyed by
+// channel.type, channel or type, e.g. "allow,jmo=truncate,otp=reject".
+type Sms struct {
+	PhoneFormat  string `mapstructure:"PHONE_FORMAT"`
+	MaxSegments  string `mapstructure:"MAX_SEGMENTS"`
+	LengthPolicy string `mapstructure:"LENGTH_POLICY"`
+}
+
+// KafkaTopic lists the topics by kind. ProducerInlineData writes the data of
+// tracking events as a JSON object instead of base64, ProducerContentType
+// selects JSON, Avro or Protobuf for them.
 type KafkaTopic struct {
-	Producer string `mapstructure:"PRODUCER"`
-	Consumer string `mapstructure:"CONSUMER"`
+	Producer            string `mapstructure:"PRODUCER"`
+	Consumer            string `mapstructure:"CONSUMER"`
+	ProducerInlineData  string `mapstructure:"PRODUCER_INLINE_DATA"`
+	ProducerContentType string `mapstructure:"PRODUCER_CONTENT_TYPE"`
 }
 
 type ProviderClient struct {
 	EmailProvider         *EmailProvider         `mapstructure:"EMAIL_PROVIDER"`
 	SmsProvider           *SmsProvider           `mapstructure:"SMS_PROVIDER"`
+	RestSmsProvider       *RestSmsProvider       `mapstructure:"REST_SMS_PROVIDER"`
+	SmtpProvider          *SmtpProvider          `mapstructure:"SMTP_PROVIDER"`
+	WhatsAppProvider      *WhatsAppProvider      `mapstructure:"WHATSAPP_PROVIDER"`
+	WebhookProvider       *WebhookProvider       `mapstructure:"WEBHOOK_PROVIDER"`
+	ApnsProvider          *ApnsProvider          `mapstructure:"APNS_PROVIDER"`
+	WebPushProvider       *WebPushProvider       `mapstructure:"WEBPUSH_PROVIDER"`
 	FcmPushProvider       *FcmPushProvider       `mapstructure:"FCM_PUSH_PROVIDER"`
 	OneSignalPushProvider *OneSignalPushProvider `mapstructure:"ONESIGNAL_PUSH_PROVIDER"`
 }
 
+// ProviderSelection names the provider implementation per category, see
+// provider_client.ResolveProviderName for the per channel and priority syntax.
+type ProviderSelection struct {
+	Email    string `mapstructure:"EMAIL"`
+	Sms      string `mapstructure:"SMS"`
+	Push     string `mapstructure:"PUSH"`
+	PushIos  string `mapstructure:"PUSH_IOS"`
+	InApp    string `mapstructure:"INAPP"`
+	WhatsApp string `mapstructure:"WHATSAPP"`
+	Webhook  string `mapstructure:"WEBHOOK"`
+	WebPush  string `mapstructure:"WEBPUSH"`
+}
+
 type EmailProvider struct {
 	Url     string `mapstructure:"URL"`
 	From    string `mapstructure:"FROM"`
@@ -51,9 +125,109 @@ type SmsProvider struct {
 	Password string `mapstructure:"PASSWORD"`
 }
 
+// SmtpProvider configures direct SMTP delivery. Security is one of starttls,
+// tls (implicit TLS, usually port 465) or none.
+type SmtpProvider struct {
+	Host               string `mapstructure:"HOST"`
+	Port               string `mapstructure:"PORT"`
+	Security           string `mapstructure:"SECURITY"`
+	Username           string `mapstructure:"USERNAME"`
+	Password           string `mapstructure:"PASSWORD"`
+	From               string `mapstructure:"FROM"`
+	FromName           string `mapstructure:"FROM_NAME"`
+	HeloName           string `mapstructure:"HELO_NAME"`
+	Timeout            string `mapstructure:"TIMEOUT"`
+	InsecureSkipVerify string `mapstructure:"INSECURE_SKIP_VERIFY"`
+}
+
+// RestSmsProvider configures the generic JSON SMS gateway. BodyTemplate is a
+// text/template rendered with Msisdn, Text and SenderId, the *Path fields
+// select values from the response using $.a.b[0].c style paths.
+type RestSmsProvider struct {
+	Url           string `mapstructure:"URL"`
+	Method        string `mapstructure:"METHOD"`
+	AuthScheme    string `mapstructure:"AUTH_SCHEME"`
+	Username      string `mapstructure:"USERNAME"`
+	Password      string `mapstructure:"PASSWORD"`
+	Token         string `mapstructure:"TOKEN"`
+	ApiKeyHeader  string `mapstructure:"API_KEY_HEADER"`
+	ApiKey        string `mapstructure:"API_KEY"`
+	SenderId      string `mapstructure:"SENDER_ID"`
+	ContentType   string `mapstructure:"CONTENT_TYPE"`
+	BodyTemplate  string `mapstructure:"BODY_TEMPLATE"`
+	MessageIdPath string `mapstructure:"MESSAGE_ID_PATH"`
+	StatusPath    string `mapstructure:"STATUS_PATH"`
+	SuccessStatus string `mapstructure:"SUCCESS_STATUS"`
+	MessagePath   string `mapstructure:"MESSAGE_PATH"`
+	SegmentsPath  string `mapstructure:"SEGMENTS_PATH"`
+}
+
+// WhatsAppProvider configures the WhatsApp Business Cloud API, Url is the
+// versioned Graph API base such as https://graph.facebook.com/v17.0.
+type WhatsAppProvider struct {
+	Url           string `mapstructure:"URL"`
+	PhoneNumberId string `mapstructure:"PHONE_NUMBER_ID"`
+	AccessToken   string `mapstructure:"ACCESS_TOKEN"`
+}
+
+// WebhookProvider configures delivery to subscriber endpoints. Secrets is a
+// comma separated channel=secret list with an optional bare default secret,
+// AllowedHosts restricts the destinations when set.
+type WebhookProvider struct {
+	Secrets             string `mapstructure:"SECRETS"`
+	SignatureHeader     string `mapstructure:"SIGNATURE_HEADER"`
+	TimestampHeader     string `mapstructure:"TIMESTAMP_HEADER"`
+	Timeout             string `mapstructure:"TIMEOUT"`
+	MaxAttempts         string `mapstructure:"MAX_ATTEMPTS"`
+	MaxConcurrency      string `mapstructure:"MAX_CONCURRENCY"`
+	AcquireTimeout      string `mapstructure:"ACQUIRE_TIMEOUT"`
+	AllowedHosts        string `mapstructure:"ALLOWED_HOSTS"`
+	AllowInsecureScheme string `mapstructure:"ALLOW_INSECURE_SCHEME"`
+}
+
+// ApnsProvider configures token based APNs delivery. KeyFile is the .p8
+// signing key downloaded from the Apple developer account, Host can point at
+// api.sandbox.push.apple.com or a local stub.
+type ApnsProvider struct {
+	Host               string `mapstructure:"HOST"`
+	KeyId              string `mapstructure:"KEY_ID"`
+	TeamId             string `mapstructure:"TEAM_ID"`
+	KeyFile            string `mapstructure:"KEY_FILE"`
+	Topic              string `mapstructure:"TOPIC"`
+	Expiration         string `mapstructure:"EXPIRATION"`
+	Concurrency        string `mapstructure:"CONCURRENCY"`
+	InsecureSkipVerify string `mapstructure:"INSECURE_SKIP_VERIFY"`
+}
+
+// WebPushProvider holds the VAPID key pair as unpadded base64url, the public
+// key is the 65 byte uncompressed point given to the browser as
+// applicationServerKey. Subject is a mailto: or https: contact.
+type WebPushProvider struct {
+	VapidPublicKey  string `mapstructure:"VAPID_PUBLIC_KEY"`
+	VapidPrivateKey string `mapstructure:"VAPID_PRIVATE_KEY"`
+	Subject         string `mapstructure:"SUBJECT"`
+	TimeToLive      string `mapstructure:"TIME_TO_LIVE"`
+	Concurrency     string `mapstructure:"CONCURRENCY"`
+}
+
 type FcmPushProvider struct {
 	Url    string `mapstructure:"URL"`
 	ApiKey string `mapstructure:"API_KEY"`
+	V1Url  string `mapstructure:"V1_URL"`
+	// ChunkSize caps registration_ids per legacy request, Concurrency bounds
+	// the requests in flight for one push.
+	ChunkSize   string `mapstructure:"CHUNK_SIZE"`
+	Concurrency string `mapstructure:"CONCURRENCY"`
+	// Apps holds the credentials per channel name. An app with a
+	// ServiceAccountFile is sent through the HTTP v1 API of ProjectId,
+	// otherwise ApiKey is used against the legacy Url.
+	Apps map[string]*FcmApp `mapstructure:"APPS"`
+}
+
+type FcmApp struct {
+	ProjectId          string `mapstructure:"PROJECT_ID"`
+	ApiKey             string `mapstructure:"API_KEY"`
+	ServiceAccountFile string `mapstructure:"SERVICE_ACCOUNT_FILE"`
 }
 
 type OneSignalPushProvider struct {
@@ -61,6 +235,18 @@ type OneSignalPushProvider struct {
 	ApiKey    string `mapstructure:"API_KEY"`
 	JmoAppId  string `mapstructure:"JMO_APP_ID"`
 	SippAppId string `mapstructure:"SIPP_APP_ID"`
+	InAppUrl  string `mapstructure:"INAPP_URL"`
+	// ChunkSize caps include_player_ids per request, Concurrency bounds the
+	// requests in flight for one push.
+	ChunkSize   string `mapstructure:"CHUNK_SIZE"`
+	Concurrency string `mapstructure:"CONCURRENCY"`
+	// Apps holds the app id and REST key per channel name.
+	Apps map[string]*OneSignalApp `mapstructure:"APPS"`
+}
+
+type OneSignalApp struct {
+	AppId  string `mapstructure:"APP_ID"`
+	ApiKey string `mapstructure:"API_KEY"`
 }
 
 func getEnv(key, fallback string) string {
@@ -70,7 +256,83 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// loadKafkaSecurity reads KAFKA_<PRODUCER|CONSUMER>_{TLS_*,SASL_*} for one
+// cluster.
+func loadKafkaSecurity(cluster string) *kafkaClient.Security {
+	prefix := "KAFKA_" + cluster + "_"
+
+	return &kafkaClient.Security{
+		TLSEnabled:            getEnv(prefix+"TLS_ENABLED", "false"),
+		TLSCAFile:             getEnv(prefix+"TLS_CA_FILE", ""),
+		TLSCertFile:           getEnv(prefix+"TLS_CERT_FILE", ""),
+		TLSKeyFile:            getEnv(prefix+"TLS_KEY_FILE", ""),
+		TLSServerName:         getEnv(prefix+"TLS_SERVER_NAME", ""),
+		TLSInsecureSkipVerify: getEnv(prefix+"TLS_INSECURE_SKIP_VERIFY", "false"),
+		SASLMechanism:         getEnv(prefix+"SASL_MECHANISM", ""),
+		SASLUsername:          getEnv(prefix+"SASL_USERNAME", ""),
+		SASLPassword:          getEnv(prefix+"SASL_PASSWORD", ""),
+	}
+}
+
+// loadFcmApps reads FCM_PUSH_PROVIDER_<CHANNEL>_{PROJECT_ID,API_KEY,
+// SERVICE_ACCOUNT_FILE}. The global FCM_PUSH_PROVIDER_API_KEY predates per
+// channel apps and still serves jmo.
+func loadFcmApps(legacyApiKey string) map[string]*FcmApp {
+	apps := make(map[string]*FcmApp)
+
+	for _, channel := range constants.CHANNELS {
+		prefix := "FCM_PUSH_PROVIDER_" + strings.ToUpper(channel) + "_"
+
+		app := &FcmApp{
+			ProjectId:          getEnv(prefix+"PROJECT_ID", ""),
+			ApiKey:             getEnv(prefix+"API_KEY", ""),
+			ServiceAccountFile: getEnv(prefix+"SERVICE_ACCOUNT_FILE", ""),
+		}
+		if channel == constants.CHANNEL_JMO && app.ApiKey == "" {
+			app.ApiKey = legacyApiKey
+		}
+
+		if app.ApiKey != "" || app.ServiceAccountFile != "" {
+			apps[channel] = app
+		}
+	}
+
+	return apps
+}
+
+// loadOneSignalApps reads ONESIGNAL_PUSH_PROVIDER_<CHANNEL>_{APP_ID,API_KEY},
+// falling back to the older JMO_APP_ID / SIPP_APP_ID with the shared API_KEY.
+func loadOneSignalApps(apiKey string, jmoAppId string, sippAppId string) map[string]*OneSignalApp {
+	apps := make(map[string]*OneSignalApp)
+
+	for _, channel := range constants.CHANNELS {
+		prefix := "ONESIGNAL_PUSH_PROVIDER_" + strings.ToUpper(channel) + "_"
+
+		app := &OneSignalApp{
+			AppId:  getEnv(prefix+"APP_ID", ""),
+			ApiKey: getEnv(prefix+"API_KEY", apiKey),
+		}
+		if app.AppId == "" && channel == constants.CHANNEL_JMO {
+			app.AppId = jmoAppId
+		}
+		if app.AppId == "" && channel == constants.CHANNEL_SIPP {
+			app.AppId = sippAppId
+		}
+
+		if app.AppId != "" && app.ApiKey != "" {
+			apps[channel] = app
+		}
+	}
+
+	return apps
+}
+
 func LoadConfigFromOS() *Config {
+	fcmApiKey := getEnv("FCM_PUSH_PROVIDER_API_KEY", "")
+	oneSignalApiKey := getEnv("ONESIGNAL_PUSH_PROVIDER_API_KEY", "MWE4M2U4OGEtMmRlZi00ODI0LTkxNDYtYjFiZmIyZTAzYzJk")
+	oneSignalJmoAppId := getEnv("ONESIGNAL_PUSH_PROVIDER_JMO_APP_ID", "40b2bca3-fbc3-47b1-a518-df6093404d7f")
+	oneSignalSippAppId := getEnv("ONESIGNAL_PUSH_PROVIDER_SIPP_APP_ID", "")
+
 	return &Config{
 		Project: &Project{
 			ServiceName: getEnv("PROJECT_SERVICE_NAME", "cns-dispatch"),
@@ -78,7 +340,50 @@ func LoadConfigFromOS() *Config {
 			Environment: getEnv("PROJECT_ENVIRONMENT", "dev"),
 		},
 		Logger: &loggerClient.Config{
-			Encoding: getEnv("LOGGER_ENCODING", "console"),
+			Encoding:        getEnv("LOGGER_ENCODING", "console"),
+			Level:           getEnv("LOGGER_LEVEL", "INFO"),
+			SamplingInitial: getEnv("LOGGER_SAMPLING_INITIAL", "100"),
+			Sampling:        getEnv("LOGGER_SAMPLING", ""),
+			MaskRules:       getEnv("LOGGER_MASK_RULES", ""),
+			RawPayload:      getEnv("LOGGER_RAW_PAYLOAD", "false"),
+		},
+		Retry: &Retry{
+			MaxAttempts:      getEnv("RETRY_MAX_ATTEMPTS", "5"),
+			MaxDelay:         getEnv("RETRY_MAX_DELAY", "15m"),
+			DelayTopicSuffix: getEnv("RETRY_DELAY_TOPIC_SUFFIX", "_delay"),
+		},
+		Scheduler: &Scheduler{
+			HighWeight:    getEnv("SCHEDULER_HIGH_WEIGHT", "4"),
+			RegularWeight: getEnv("SCHEDULER_REGULAR_WEIGHT", "1"),
+			Workers:       getEnv("SCHEDULER_WORKERS", ""),
+		},
+		SchemaRegistry: &schemaRegistry.Config{
+			Url:      getEnv("SCHEMA_REGISTRY_URL", ""),
+			Username: getEnv("SCHEMA_REGISTRY_USERNAME", ""),
+			Password: getEnv("SCHEMA_REGISTRY_PASSWORD", ""),
+			File:     getEnv("SCHEMA_REGISTRY_FILE", ""),
+			CacheTTL: getEnv("SCHEMA_REGISTRY_CACHE_TTL", "5m"),
+		},
+		Sms: &Sms{
+			PhoneFormat:  getEnv("SMS_PHONE_FORMAT", "local"),
+			MaxSegments:  getEnv("SMS_MAX_SEGMENTS", "1"),
+			LengthPolicy: getEnv("SMS_LENGTH_POLICY", "allow"),
+		},
+		Spool: &Spool{
+			Dir:           getEnv("SPOOL_DIR", "spool"),
+			MaxBytes:      getEnv("SPOOL_MAX_BYTES", "536870912"),
+			SegmentBytes:  getEnv("SPOOL_SEGMENT_BYTES", "16777216"),
+			FlushInterval: getEnv("SPOOL_FLUSH_INTERVAL", "5s"),
+		},
+		ProviderSelection: &ProviderSelection{
+			Email:    getEnv("PROVIDER_EMAIL", "wscom"),
+			Sms:      getEnv("PROVIDER_SMS", "smsapps"),
+			Push:     getEnv("PROVIDER_PUSH", "fcm,high=onesignal"),
+			PushIos:  getEnv("PROVIDER_PUSH_IOS", "none"),
+			InApp:    getEnv("PROVIDER_INAPP", "none"),
+			WhatsApp: getEnv("PROVIDER_WHATSAPP", "none"),
+			Webhook:  getEnv("PROVIDER_WEBHOOK", "http"),
+			WebPush:  getEnv("PROVIDER_WEBPUSH", "none"),
 		},
 		ProviderClient: &ProviderClient{
 			EmailProvider: &EmailProvider{
@@ -91,37 +396,121 @@ func LoadConfigFromOS() *Config {
 				Username: getEnv("SMS_PROVIDER_USERNAME", "sso"),
 				Password: getEnv("SMS_PROVIDER_PASSWORD", "sso123"),
 			},
+			RestSmsProvider: &RestSmsProvider{
+				Url:           getEnv("REST_SMS_PROVIDER_URL", ""),
+				Method:        getEnv("REST_SMS_PROVIDER_METHOD", "POST"),
+				AuthScheme:    getEnv("REST_SMS_PROVIDER_AUTH_SCHEME", "none"),
+				Username:      getEnv("REST_SMS_PROVIDER_USERNAME", ""),
+				Password:      getEnv("REST_SMS_PROVIDER_PASSWORD", ""),
+				Token:         getEnv("REST_SMS_PROVIDER_TOKEN", ""),
+				ApiKeyHeader:  getEnv("REST_SMS_PROVIDER_API_KEY_HEADER", "X-API-Key"),
+				ApiKey:        getEnv("REST_SMS_PROVIDER_API_KEY", ""),
+				SenderId:      getEnv("REST_SMS_PROVIDER_SENDER_ID", ""),
+				ContentType:   getEnv("REST_SMS_PROVIDER_CONTENT_TYPE", "application/json"),
+				BodyTemplate:  getEnv("REST_SMS_PROVIDER_BODY_TEMPLATE", `{"to":{{json .Msisdn}},"text":{{json .Text}}}`),
+				MessageIdPath: getEnv("REST_SMS_PROVIDER_MESSAGE_ID_PATH", "$.message_id"),
+				StatusPath:    getEnv("REST_SMS_PROVIDER_STATUS_PATH", "$.status"),
+				SuccessStatus: getEnv("REST_SMS_PROVIDER_SUCCESS_STATUS", "success"),
+				MessagePath:   getEnv("REST_SMS_PROVIDER_MESSAGE_PATH", "$.message"),
+				SegmentsPath:  getEnv("REST_SMS_PROVIDER_SEGMENTS_PATH", ""),
+			},
+			SmtpProvider: &SmtpProvider{
+				Host:               getEnv("SMTP_PROVIDER_HOST", ""),
+				Port:               getEnv("SMTP_PROVIDER_PORT", "587"),
+				Security:           getEnv("SMTP_PROVIDER_SECURITY", "starttls"),
+				Username:           getEnv("SMTP_PROVIDER_USERNAME", ""),
+				Password:           getEnv("SMTP_PROVIDER_PASSWORD", ""),
+				From:               getEnv("SMTP_PROVIDER_FROM", "noreply@bpjsketenagakerjaan.go.id"),
+				FromName:           getEnv("SMTP_PROVIDER_FROM_NAME", "BPJS Ketenagakerjaan"),
+				HeloName:           getEnv("SMTP_PROVIDER_HELO_NAME", ""),
+				Timeout:            getEnv("SMTP_PROVIDER_TIMEOUT", "30s"),
+				InsecureSkipVerify: getEnv("SMTP_PROVIDER_INSECURE_SKIP_VERIFY", "false"),
+			},
+			WhatsAppProvider: &WhatsAppProvider{
+				Url:           getEnv("WHATSAPP_PROVIDER_URL", "https://graph.facebook.com/v17.0"),
+				PhoneNumberId: getEnv("WHATSAPP_PROVIDER_PHONE_NUMBER_ID", ""),
+				AccessToken:   getEnv("WHATSAPP_PROVIDER_ACCESS_TOKEN", ""),
+			},
+			WebhookProvider: &WebhookProvider{
+				Secrets:             getEnv("WEBHOOK_PROVIDER_SECRETS", ""),
+				SignatureHeader:     getEnv("WEBHOOK_PROVIDER_SIGNATURE_HEADER", "X-CNS-Signature"),
+				TimestampHeader:     getEnv("WEBHOOK_PROVIDER_TIMESTAMP_HEADER", "X-CNS-Timestamp"),
+				Timeout:             getEnv("WEBHOOK_PROVIDER_TIMEOUT", "10s"),
+				MaxAttempts:         getEnv("WEBHOOK_PROVIDER_MAX_ATTEMPTS", "3"),
+				MaxConcurrency:      getEnv("WEBHOOK_PROVIDER_MAX_CONCURRENCY", "4"),
+				AcquireTimeout:      getEnv("WEBHOOK_PROVIDER_ACQUIRE_TIMEOUT", "5s"),
+				AllowedHosts:        getEnv("WEBHOOK_PROVIDER_ALLOWED_HOSTS", ""),
+				AllowInsecureScheme: getEnv("WEBHOOK_PROVIDER_ALLOW_INSECURE_SCHEME", "false"),
+			},
+			ApnsProvider: &ApnsProvider{
+				Host:               getEnv("APNS_PROVIDER_HOST", "https://api.push.apple.com"),
+				KeyId:              getEnv("APNS_PROVIDER_KEY_ID", ""),
+				TeamId:             getEnv("APNS_PROVIDER_TEAM_ID", ""),
+				KeyFile:            getEnv("APNS_PROVIDER_KEY_FILE", ""),
+				Topic:              getEnv("APNS_PROVIDER_TOPIC", ""),
+				Expiration:         getEnv("APNS_PROVIDER_EXPIRATION", "24h"),
+				Concurrency:        getEnv("APNS_PROVIDER_CONCURRENCY", "8"),
+				InsecureSkipVerify: getEnv("APNS_PROVIDER_INSECURE_SKIP_VERIFY", "false"),
+			},
+			WebPushProvider: &WebPushProvider{
+				VapidPublicKey:  getEnv("WEBPUSH_PROVIDER_VAPID_PUBLIC_KEY", ""),
+				VapidPrivateKey: getEnv("WEBPUSH_PROVIDER_VAPID_PRIVATE_KEY", ""),
+				Subject:         getEnv("WEBPUSH_PROVIDER_SUBJECT", "mailto:cns@bpjsketenagakerjaan.go.id"),
+				TimeToLive:      getEnv("WEBPUSH_PROVIDER_TIME_TO_LIVE", "24h"),
+				Concurrency:     getEnv("WEBPUSH_PROVIDER_CONCURRENCY", "8"),
+			},
 			FcmPushProvider: &FcmPushProvider{
-				Url:    getEnv("FCM_PUSH_PROVIDER_URL", "https://fcm.googleapis.com/fcm/send"),
-				ApiKey: getEnv("FCM_PUSH_PROVIDER_API_KEY", ""),
+				Url:         getEnv("FCM_PUSH_PROVIDER_URL", "https://fcm.googleapis.com/fcm/send"),
+				ApiKey:      fcmApiKey,
+				V1Url:       getEnv("FCM_PUSH_PROVIDER_V1_URL", "https://fcm.googleapis.com/v1"),
+				ChunkSize:   getEnv("FCM_PUSH_PROVIDER_CHUNK_SIZE", "1000"),
+				Concurrency: getEnv("FCM_PUSH_PROVIDER_CONCURRENCY", "4"),
+				Apps:        loadFcmApps(fcmApiKey),
 			},
 			OneSignalPushProvider: &OneSignalPushProvider{
-				Url:       getEnv("ONESIGNAL_PUSH_PROVIDER_URL", "https://onesignal.com/api/v1/notifications"),
-				ApiKey:    getEnv("ONESIGNAL_PUSH_PROVIDER_API_KEY", "MWE4M2U4OGEtMmRlZi00ODI0LTkxNDYtYjFiZmIyZTAzYzJk"),
-				JmoAppId:  getEnv("ONESIGNAL_PUSH_PROVIDER_JMO_APP_ID", "40b2bca3-fbc3-47b1-a518-df6093404d7f"),
-				SippAppId: getEnv("ONESIGNAL_PUSH_PROVIDER_SIPP_APP_ID", ""),
+				Url:         getEnv("ONESIGNAL_PUSH_PROVIDER_URL", "https://onesignal.com/api/v1/notifications"),
+				ApiKey:      oneSignalApiKey,
+				JmoAppId:    oneSignalJmoAppId,
+				SippAppId:   oneSignalSippAppId,
+				InAppUrl:    getEnv("ONESIGNAL_PUSH_PROVIDER_INAPP_URL", ""),
+				ChunkSize:   getEnv("ONESIGNAL_PUSH_PROVIDER_CHUNK_SIZE", "2000"),
+				Concurrency: getEnv("ONESIGNAL_PUSH_PROVIDER_CONCURRENCY", "4"),
+				Apps:        loadOneSignalApps(oneSignalApiKey, oneSignalJmoAppId, oneSignalSippAppId),
 			},
 		},
 		Kafka: &kafkaClient.Config{
-			ProducerBrokers: getEnv("KAFKA_PRODUCER_BROKERS", "localhost:29092"),
-			ConsumerBrokers: getEnv("KAFKA_CONSUMER_BROKERS", "localhost:29093"),
-			GroupID:         getEnv("KAFKA_GROUP_ID", "cns_dispatch_consumer"),
-			PoolSize:        getEnv("KAFKA_POOL_SIZE", "10"),
-			Partition:       getEnv("KAFKA_PARTITION", "10"),
+			ProducerBrokers:  getEnv("KAFKA_PRODUCER_BROKERS", "localhost:29092"),
+			ConsumerBrokers:  getEnv("KAFKA_CONSUMER_BROKERS", "localhost:29093"),
+			GroupID:          getEnv("KAFKA_GROUP_ID", "cns_dispatch_consumer"),
+			PoolSize:         getEnv("KAFKA_POOL_SIZE", "10"),
+			Partition:        getEnv("KAFKA_PARTITION", "10"),
+			StatsInterval:    getEnv("KAFKA_STATS_INTERVAL", "15s"),
+			ProducerSecurity: loadKafkaSecurity("PRODUCER"),
+			ConsumerSecurity: loadKafkaSecurity("CONSUMER"),
 		},
 		KafkaTopic: &KafkaTopic{
-			Producer: getEnv("KAFKA_TOPIC_PRODUCER", "cns_trc_email,cns_trc_sms,cns_trc_inapp,cns_trc_push,cns_trc_sms_pool"),
-			Consumer: getEnv("KAFKA_TOPIC_CONSUMER", "cns_dsp_<channel>_email_<priority>,cns_dsp_<channel>_sms_<priority>,cns_dsp_<channel>_inapp_<priority>,cns_dsp_<channel>_push_<priority>"),
+			Producer:            getEnv("KAFKA_TOPIC_PRODUCER", "cns_trc_email,cns_trc_sms,cns_trc_inapp,cns_trc_push,cns_trc_sms_pool,cns_trc_whatsapp,cns_trc_webhook,cns_trc_webpush,cns_trc_webpush_invalid,cns_trc_push_token_invalid,cns_trc_rejected"),
+			Consumer:            getEnv("KAFKA_TOPIC_CONSUMER", "cns_dsp_<channel>_email_<priority>,cns_dsp_<channel>_sms_<priority>,cns_dsp_<channel>_inapp_<priority>,cns_dsp_<channel>_push_<priority>,cns_dsp_<channel>_whatsapp_<priority>,cns_dsp_<channel>_webhook_<priority>,cns_dsp_<channel>_webpush_<priority>"),
+			ProducerInlineData:  getEnv("KAFKA_TOPIC_PRODUCER_INLINE_DATA", "false"),
+			ProducerContentType: getEnv("KAFKA_TOPIC_PRODUCER_CONTENT_TYPE", "application/json"),
 		},
 		Tracer: &tracerClient.Config{
-			Endpoint: getEnv("TRACER_ENDPOINT", "http://localhost:14268/api/traces"),
-			Prefix:   getEnv("TRACER_PREFIX", "cns_dispatch"),
+			Exporter:      getEnv("TRACER_EXPORTER", "jaeger"),
+			Endpoint:      getEnv("TRACER_ENDPOINT", "http://localhost:14268/api/traces"),
+			Insecure:      getEnv("TRACER_INSECURE", "false"),
+			SamplingRatio: getEnv("TRACER_SAMPLING_RATIO", "1"),
+			Prefix:        getEnv("TRACER_PREFIX", "cns_dispatch"),
 		},
 		Metric: &metricClient.Config{
 			Port:      getEnv("METRIC_PORT", ":8085"),
 			Path:      getEnv("METRIC_PATH", "/metrics"),
 			Prefix:    getEnv("METRIC_PREFIX", "cns_dispatch"),
 			MeterName: getEnv("METRIC_METER_NAME", "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service"),
+			AdminPath: getEnv("METRIC_ADMIN_PATH", "/admin"),
+			Exporter:  getEnv("METRIC_EXPORTER", "none"),
+			Endpoint:  getEnv("METRIC_ENDPOINT", ""),
+			Insecure:  getEnv("METRIC_INSECURE", "false"),
+			Interval:  getEnv("METRIC_INTERVAL", "30s"),
 		},
 	}
 }
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/phone"
+)
+
+func TestSmsPhoneFormatDefault(t *testing.T) {
+	// Restored by t.Setenv once the test ends.
+	t.Setenv("SMS_PHONE_FORMAT", "")
+	os.Unsetenv("SMS_PHONE_FORMAT")
+
+	if got := LoadConfigFromOS().Sms.PhoneFormat; got != phone.FormatLocal {
+		t.Errorf("default phone format = %q, want %q", got, phone.FormatLocal)
+	}
+
+	t.Setenv("SMS_PHONE_FORMAT", phone.FormatE164)
+	if got := LoadConfigFromOS().Sms.PhoneFormat; got != phone.FormatE164 {
+		t.Errorf("phone format = %q, want %q", got, phone.FormatE164)
+	}
+}
@@ -0,0 +1,53 @@
+package messageprocessor
+
+import (
+	"context"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/schema"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	decodeRetryMinDelay = time.Second
+	decodeRetryMaxDelay = 30 * time.Second
+)
+
+// decodeValue returns the message value as JSON, picking the decoder from the
+// content-type header. Values that can never decode are rejected, while an
+// unreachable registry is waited out so the partition keeps its order. It
+// returns false, with the outcome of the message, when the message is done
+// with or ctx is cancelled.
+func (mp *MessageProcessor) decodeValue(ctx context.Context, r *kafka.Reader, fetchedMessage kafka.Message, headers map[string]string) ([]byte, Outcome, bool) {
+	contentType := getValueFromKafkaHeaders(headers, serde.ContentTypeHeader)
+	if serde.IsJSON(contentType) {
+		return fetchedMessage.Value, OutcomeSent, true
+	}
+
+	delay := decodeRetryMinDelay
+	for {
+		value, err := mp.serde.Decode(ctx, contentType, fetchedMessage.Value)
+		if err == nil {
+			return value, OutcomeSent, true
+		}
+
+		if serde.Permanent(err) {
+			mp.rejectMessage(ctx, r, fetchedMessage, nil, []schema.FieldError{{Keyword: "content-type", Message: err.Error()}})
+			return nil, OutcomeRejected, false
+		}
+
+		mp.logKafkaMessage(ctx, false, nil, err, "Error to decode message, retrying in "+delay.String())
+
+		select {
+		case <-ctx.Done():
+			return nil, OutcomeFailed, false
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > decodeRetryMaxDelay {
+			delay = decodeRetryMaxDelay
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package messageprocessor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	forwardRetryMinDelay = time.Second
+	forwardRetryMaxDelay = 30 * time.Second
+)
+
+// ForwardDelayed is the worker of a delay topic. It holds each rescheduled
+// message until its retry time and puts it back on its dispatch topic, so
+// dispatch workers never wait on a message. A delay partition is roughly in
+// due order, a message can hold the ones behind it for at most the retry
+// max delay.
+func (mp *MessageProcessor) ForwardDelayed(ctx context.Context, r *kafka.Reader, workerID int) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		fetchedMessage, ok := mp.fetchMessage(ctx, r)
+		if !ok {
+			continue
+		}
+
+		headersMap := createKafkaHeadersMap(fetchedMessage.Headers)
+		traceID := getValueFromKafkaHeaders(headersMap, "trace_id")
+		attempt, _ := strconv.Atoi(getValueFromKafkaHeaders(headersMap, headerAttempt))
+		msgCtx := contextMd.SetMetadataToNewContext(ctx, traceID, fetchedMessage.Topic, attempt)
+
+		if !waitRetryAt(msgCtx, headersMap) {
+			return
+		}
+
+		if !mp.forwardDelayed(msgCtx, fetchedMessage, headersMap) {
+			return
+		}
+
+		mp.commitAndLogMsg(msgCtx, r, fetchedMessage, "")
+	}
+}
+
+// forwardDelayed publishes msg to the topic it was rescheduled from until it
+// succeeds, the message must not be committed before that. It returns false
+// when ctx is done first.
+func (mp *MessageProcessor) forwardDelayed(ctx context.Context, msg kafka.Message, headers map[string]string) bool {
+	topic := getValueFromKafkaHeaders(headers, headerRetryTopic)
+	if topic == "" {
+		topic = strings.TrimSuffix(msg.Topic, mp.retry.delayTopicSuffix)
+	}
+
+	forwardedMsg := kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: dropHeaders(msg.Headers, headerRetryAt, headerRetryTopic),
+	}
+
+	delay := forwardRetryMinDelay
+	for {
+		err := mp.retry.producer(topic).PublishMessage(ctx, forwardedMsg)
+		if err == nil {
+			return true
+		}
+
+		mp.logKafkaMessage(ctx, false, nil, err, "Error to forward delayed message, retrying in "+delay.String())
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > forwardRetryMaxDelay {
+			delay = forwardRetryMaxDelay
+		}
+	}
+}
+
+// waitRetryAt holds a rescheduled message until its retry time. It returns
+// false when ctx is done first.
+func waitRetryAt(ctx context.Context, headers map[string]string) bool {
+	retryAtMs, err := strconv.ParseInt(getValueFromKafkaHeaders(headers, headerRetryAt), 10, 64)
+	if err != nil {
+		return true
+	}
+
+	wait := time.Until(time.UnixMilli(retryAtMs))
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
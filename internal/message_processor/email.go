@@ -10,22 +10,29 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func (mp *MessageProcessor) processEmail(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) {
-	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg.Headers, "MessageProcessor.processEmail")
+func (mp *MessageProcessor) processEmail(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processEmail")
 	defer span.End()
 
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
 	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
 
 	emailMsg := &model.Email{}
 	if err := json.Unmarshal(consumedKafkaMsg.Data, emailMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
 		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
-		return
+		return OutcomeFailed
 	}
 
+	outcome := OutcomeSent
 	if err := mp.usecase.HandleEmail(ctx, publishedKafkaMsg, emailMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, emailMsg, err, constants.ErrorProcessingMessage)
+		outcome = mp.rescheduleThrottled(ctx, msg, emailMsg, err)
 	}
 
 	mp.commitAndLogMsg(ctx, r, msg, emailMsg)
+	return outcome
 }
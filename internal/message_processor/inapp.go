@@ -10,22 +10,29 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func (mp *MessageProcessor) processInApp(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) {
-	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg.Headers, "MessageProcessor.processInApp")
+func (mp *MessageProcessor) processInApp(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processInApp")
 	defer span.End()
 
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
 	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
 
 	inappMsg := &model.InApp{}
 	if err := json.Unmarshal(consumedKafkaMsg.Data, inappMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
 		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
-		return
+		return OutcomeFailed
 	}
 
+	outcome := OutcomeSent
 	if err := mp.usecase.HandleInApp(ctx, publishedKafkaMsg, inappMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, inappMsg, err, constants.ErrorProcessingMessage)
+		outcome = mp.rescheduleThrottled(ctx, msg, inappMsg, err)
 	}
 
 	mp.commitAndLogMsg(ctx, r, msg, inappMsg)
+	return outcome
 }
@@ -3,14 +3,18 @@ package messageprocessor
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/schema"
 	serviceMetrics "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/service_metrics"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/usecase"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
 	loggerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
 
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel/metric"
@@ -24,9 +28,13 @@ type MessageProcessor struct {
 	tracer           trace.Tracer
 	producerTopicMap map[string]string
 	serviceMetrics   *serviceMetrics.ServiceMetrics
+	consumerStats    *kafkaClient.ConsumerStats
+	retry            *retryScheduler
+	schemas          *schema.Registry
+	serde            *serde.Serde
 }
 
-func NewMessageProcessor(logger *loggerClient.AppLogger, cfg *config.Config, usecase *usecase.Usecase, tracer trace.Tracer, producerTopicMap map[string]string, serviceMetrics *serviceMetrics.ServiceMetrics) *MessageProcessor {
+func NewMessageProcessor(logger *loggerClient.AppLogger, cfg *config.Config, usecase *usecase.Usecase, tracer trace.Tracer, producerTopicMap map[string]string, serviceMetrics *serviceMetrics.ServiceMetrics, consumerStats *kafkaClient.ConsumerStats, consumerAuth *kafkaClient.Auth, schemas *schema.Registry, valueSerde *serde.Serde) *MessageProcessor {
 	return &MessageProcessor{
 		logger:           logger,
 		cfg:              cfg,
@@ -34,9 +42,18 @@ func NewMessageProcessor(logger *loggerClient.AppLogger, cfg *config.Config, use
 		tracer:           tracer,
 		producerTopicMap: producerTopicMap,
 		serviceMetrics:   serviceMetrics,
+		consumerStats:    consumerStats,
+		retry:            newRetryScheduler(cfg, consumerAuth),
+		schemas:          schemas,
+		serde:            valueSerde,
 	}
 }
 
+// Close releases the producers used to reschedule messages.
+func (mp *MessageProcessor) Close() {
+	mp.retry.close()
+}
+
 func (mp *MessageProcessor) ProcessMessage(ctx context.Context, r *kafka.Reader, workerID int) {
 	for {
 		select {
@@ -45,31 +62,105 @@ func (mp *MessageProcessor) ProcessMessage(ctx context.Context, r *kafka.Reader,
 		default:
 		}
 
-		fetchedMessage, err := r.FetchMessage(ctx)
-		if err != nil {
-			mp.serviceMetrics.ErrorKafkaConsume.Add(ctx, 1, metric.WithAttributes())
-			mp.logKafkaMessage(ctx, false, nil, err, "Failed to fetch kafka message")
+		fetchedMessage, ok := mp.fetchMessage(ctx, r)
+		if !ok {
 			continue
 		}
-		mp.serviceMetrics.SuccessKafkaConsume.Add(ctx, 1, metric.WithAttributes())
 
-		traceID := getValueFromKafkaHeaders(createKafkaHeadersMap(fetchedMessage.Headers), "trace_id")
-		ctx = contextMd.SetMetadataToNewContext(ctx, traceID, fetchedMessage.Topic)
+		mp.handleMessage(ctx, r, fetchedMessage, "")
+	}
+}
 
-		consumedKafkaMsg := &model.ConsumedKafkaMsg{}
-		if err := json.Unmarshal(fetchedMessage.Value, consumedKafkaMsg); err != nil {
-			mp.logKafkaMessage(ctx, false, nil, err, constants.ErrorProcessingMessage)
-			mp.commitAndLogMsg(ctx, r, fetchedMessage, "")
-			continue
-		}
+// ScheduleMessages returns a worker that fetches from its reader and queues
+// the messages on scheduler under priority, for processes consuming several
+// priorities with one worker pool.
+func (mp *MessageProcessor) ScheduleMessages(scheduler *kafkaClient.Scheduler, priority string) kafkaClient.Worker {
+	return func(ctx context.Context, r *kafka.Reader, workerID int) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
 
-		mp.logKafkaMessage(ctx, true, consumedKafkaMsg, nil, "Kafka message received and is being processed")
+			fetchedMessage, ok := mp.fetchMessage(ctx, r)
+			if !ok {
+				continue
+			}
 
-		if processorFunc, exists := mp.getProcessorFunc(consumedKafkaMsg.CategoryName); exists {
-			processorFunc(ctx, r, fetchedMessage, consumedKafkaMsg)
-		} else {
-			mp.logKafkaMessage(ctx, false, consumedKafkaMsg, nil, "Unsupported message category")
-			mp.commitAndLogMsg(ctx, r, fetchedMessage, consumedKafkaMsg)
+			if !scheduler.Submit(ctx, priority, r, fetchedMessage) {
+				return
+			}
 		}
 	}
 }
+
+// HandleScheduledMessage processes a message taken from the scheduler.
+func (mp *MessageProcessor) HandleScheduledMessage(ctx context.Context, priority string, r *kafka.Reader, fetchedMessage kafka.Message) {
+	mp.handleMessage(ctx, r, fetchedMessage, priority)
+}
+
+// ReplayMessage runs a message read outside the consumer group through the
+// pipeline and returns what became of it. There is nothing to commit, the
+// retry headers start afresh.
+func (mp *MessageProcessor) ReplayMessage(ctx context.Context, msg kafka.Message) Outcome {
+	msg.Headers = ResetRetryHeaders(msg.Headers)
+	return mp.handleMessage(ctx, nil, msg, "")
+}
+
+func (mp *MessageProcessor) fetchMessage(ctx context.Context, r *kafka.Reader) (kafka.Message, bool) {
+	fetchedMessage, err := r.FetchMessage(ctx)
+	if err != nil {
+		mp.serviceMetrics.ErrorKafkaConsume.Add(ctx, 1, metric.WithAttributes())
+		mp.logKafkaMessage(ctx, false, nil, err, "Failed to fetch kafka message")
+		return kafka.Message{}, false
+	}
+	mp.serviceMetrics.SuccessKafkaConsume.Add(ctx, 1, metric.WithAttributes())
+
+	return fetchedMessage, true
+}
+
+// handleMessage processes one message, priority is empty unless the process
+// consumes several priorities.
+func (mp *MessageProcessor) handleMessage(ctx context.Context, r *kafka.Reader, fetchedMessage kafka.Message, priority string) Outcome {
+	headersMap := createKafkaHeadersMap(fetchedMessage.Headers)
+	traceID := getValueFromKafkaHeaders(headersMap, "trace_id")
+	attempt, _ := strconv.Atoi(getValueFromKafkaHeaders(headersMap, headerAttempt))
+	ctx = contextMd.SetMetadataToNewContext(ctx, traceID, fetchedMessage.Topic, attempt)
+	if priority != "" {
+		ctx = contextMd.WithPriority(ctx, priority)
+	}
+
+	value, outcome, ok := mp.decodeValue(ctx, r, fetchedMessage, headersMap)
+	if !ok {
+		return outcome
+	}
+
+	if errs := mp.schemas.ValidateEnvelope(value); len(errs) > 0 {
+		mp.rejectMessage(ctx, r, fetchedMessage, nil, errs)
+		return OutcomeRejected
+	}
+
+	consumedKafkaMsg := &model.ConsumedKafkaMsg{}
+	if err := json.Unmarshal(value, consumedKafkaMsg); err != nil {
+		mp.logKafkaMessage(ctx, false, nil, err, constants.ErrorProcessingMessage)
+		mp.commitAndLogMsg(ctx, r, fetchedMessage, "")
+		return OutcomeFailed
+	}
+
+	if errs := mp.schemas.ValidatePayload(consumedKafkaMsg.CategoryName, consumedKafkaMsg.SchemaVersion, consumedKafkaMsg.Data); len(errs) > 0 {
+		mp.rejectMessage(ctx, r, fetchedMessage, consumedKafkaMsg, prefixPaths("/data", errs))
+		return OutcomeRejected
+	}
+
+	mp.logKafkaMessage(ctx, true, consumedKafkaMsg, nil, "Kafka message received and is being processed")
+
+	processorFunc, exists := mp.getProcessorFunc(consumedKafkaMsg.CategoryName)
+	if !exists {
+		mp.logKafkaMessage(ctx, false, consumedKafkaMsg, nil, "Unsupported message category")
+		mp.commitAndLogMsg(ctx, r, fetchedMessage, consumedKafkaMsg)
+		return OutcomeFailed
+	}
+
+	return processorFunc(ctx, r, fetchedMessage, consumedKafkaMsg)
+}
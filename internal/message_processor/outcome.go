@@ -0,0 +1,31 @@
+package messageprocessor
+
+// Outcome is what became of a processed message.
+type Outcome int
+
+const (
+	// OutcomeSent means the message was handed to its provider.
+	OutcomeSent Outcome = iota
+	// OutcomeRejected means the message failed validation or decoding and
+	// was published to the reject topic.
+	OutcomeRejected
+	// OutcomeRescheduled means the message was published to its delay topic.
+	OutcomeRescheduled
+	// OutcomeFailed means the message could not be processed and was dropped.
+	OutcomeFailed
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSent:
+		return "sent"
+	case OutcomeRejected:
+		return "rejected"
+	case OutcomeRescheduled:
+		return "rescheduled"
+	case OutcomeFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
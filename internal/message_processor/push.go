@@ -10,22 +10,37 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func (mp *MessageProcessor) processPush(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) {
-	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg.Headers, "MessageProcessor.processPush")
+func (mp *MessageProcessor) processPush(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processPush")
 	defer span.End()
 
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
 	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
 
 	pushMsg := &model.Push{}
 	if err := json.Unmarshal(consumedKafkaMsg.Data, pushMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
 		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
-		return
+		return OutcomeFailed
 	}
 
+	outcome := OutcomeSent
 	if err := mp.usecase.HandlePush(ctx, publishedKafkaMsg, pushMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, pushMsg, err, constants.ErrorProcessingMessage)
+
+		// Only the device tokens that failed are sent again.
+		retryMsg, narrowErr := narrowRecipients(msg, consumedKafkaMsg, "player_ids", err)
+		if narrowErr != nil {
+			mp.logKafkaMessage(ctx, false, pushMsg, narrowErr, "Error to narrow rescheduled message")
+			outcome = OutcomeFailed
+		} else {
+			outcome = mp.rescheduleThrottled(ctx, retryMsg, pushMsg, err)
+		}
 	}
 
 	mp.commitAndLogMsg(ctx, r, msg, pushMsg)
+	return outcome
 }
@@ -0,0 +1,69 @@
+package messageprocessor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/schema"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// rejectMessage reports a message that failed schema validation to the
+// rejection topic and commits it, retrying would fail the same way.
+// consumedKafkaMsg is nil when the envelope itself is invalid.
+func (mp *MessageProcessor) rejectMessage(ctx context.Context, r *kafka.Reader, fetchedMessage kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg, errs []schema.FieldError) {
+	rejected := &model.RejectedKafkaMsg{
+		Topic:      fetchedMessage.Topic,
+		Partition:  fetchedMessage.Partition,
+		Offset:     fetchedMessage.Offset,
+		Errors:     make([]model.RejectionError, len(errs)),
+		Original:   fetchedMessage.Value,
+		RejectedAt: time.Now().UTC(),
+	}
+	if consumedKafkaMsg != nil {
+		rejected.SchemaVersion = consumedKafkaMsg.SchemaVersion
+		rejected.TypeName = consumedKafkaMsg.TypeName
+		rejected.CategoryName = consumedKafkaMsg.CategoryName
+		rejected.ChannelName = consumedKafkaMsg.ChannelName
+	}
+
+	reasons := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		rejected.Errors[i] = model.RejectionError{
+			Path:    fieldErr.Path,
+			Keyword: fieldErr.Keyword,
+			Message: fieldErr.Message,
+		}
+		reasons[i] = fieldErr.String()
+	}
+
+	mp.serviceMetrics.RejectedMessage.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("category", rejected.CategoryName),
+		attribute.String("channel", rejected.ChannelName),
+	))
+
+	// The original value only goes to the rejection topic, it is not valid
+	// enough to be redacted field by field in the logs.
+	logged := *rejected
+	logged.Original = nil
+	mp.logKafkaMessage(ctx, false, &logged, nil, "Message rejected: "+strings.Join(reasons, "; "))
+
+	if err := mp.usecase.PublishRejected(ctx, rejected); err != nil {
+		mp.logKafkaMessage(ctx, false, &logged, err, "Error to publish rejected message")
+	}
+
+	mp.commitAndLogMsg(ctx, r, fetchedMessage, &logged)
+}
+
+// prefixPaths places payload errors under the envelope field they came from.
+func prefixPaths(prefix string, errs []schema.FieldError) []schema.FieldError {
+	for i := range errs {
+		errs[i].Path = prefix + errs[i].Path
+	}
+	return errs
+}
@@ -0,0 +1,246 @@
+package messageprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	headerAttempt    = "attempt"
+	headerRetryAt    = "retry_at"
+	headerRetryTopic = "retry_topic"
+
+	defaultRetryMaxAttempts      = 5
+	defaultRetryMaxDelay         = 15 * time.Minute
+	defaultRetryDelayTopicSuffix = "_delay"
+)
+
+// retryScheduler puts throttled messages on the delay topic of the topic they
+// were read from, tagged with the time they may be processed again.
+type retryScheduler struct {
+	brokers          []string
+	auth             *kafkaClient.Auth
+	maxAttempts      int
+	maxDelay         time.Duration
+	delayTopicSuffix string
+
+	mu        sync.Mutex
+	producers map[string]*kafkaClient.Producer
+}
+
+func newRetryScheduler(cfg *config.Config, auth *kafkaClient.Auth) *retryScheduler {
+	maxAttempts := defaultRetryMaxAttempts
+	maxDelay := defaultRetryMaxDelay
+	delayTopicSuffix := defaultRetryDelayTopicSuffix
+
+	if cfg.Retry != nil {
+		if n, err := strconv.Atoi(cfg.Retry.MaxAttempts); err == nil {
+			maxAttempts = n
+		}
+		if d, err := time.ParseDuration(cfg.Retry.MaxDelay); err == nil {
+			maxDelay = d
+		}
+		if cfg.Retry.DelayTopicSuffix != "" {
+			delayTopicSuffix = cfg.Retry.DelayTopicSuffix
+		}
+	}
+
+	return &retryScheduler{
+		brokers:          strings.Split(cfg.Kafka.ConsumerBrokers, ","),
+		auth:             auth,
+		maxAttempts:      maxAttempts,
+		maxDelay:         maxDelay,
+		delayTopicSuffix: delayTopicSuffix,
+		producers:        make(map[string]*kafkaClient.Producer),
+	}
+}
+
+// DelayTopic returns the topic rescheduled messages of topic wait on.
+func (mp *MessageProcessor) DelayTopic(topic string) string {
+	return topic + mp.retry.delayTopicSuffix
+}
+
+// Dispatch topics live on the consumer brokers, so the producers are kept
+// apart from the tracking producers of the usecase.
+func (rs *retryScheduler) producer(topic string) *kafkaClient.Producer {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	producer, ok := rs.producers[topic]
+	if !ok {
+		producer = kafkaClient.NewProducer(rs.brokers, topic, rs.auth)
+		rs.producers[topic] = producer
+	}
+
+	return producer
+}
+
+func (rs *retryScheduler) close() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, producer := range rs.producers {
+		if err := producer.Close(); err != nil {
+			fmt.Println("Failed to close retry producer:", err)
+		}
+	}
+}
+
+// rescheduleThrottled republishes msg to its delay topic when err carries a
+// provider retry delay and attempts are left. The original is committed by
+// the caller either way. The returned outcome tells whether the message was
+// rescheduled.
+func (mp *MessageProcessor) rescheduleThrottled(ctx context.Context, msg kafka.Message, childMsg interface{}, err error) Outcome {
+	delay, ok := providerClient.RetryAfter(err)
+	if !ok {
+		return OutcomeFailed
+	}
+
+	metadata, _ := contextMd.GetMetadataFromContext(ctx)
+	if metadata.Attempt >= mp.retry.maxAttempts {
+		mp.logKafkaMessage(ctx, false, childMsg, err, fmt.Sprintf("Giving up after %d attempts", metadata.Attempt))
+		return OutcomeFailed
+	}
+
+	if delay > mp.retry.maxDelay {
+		delay = mp.retry.maxDelay
+	}
+	retryAt := time.Now().Add(delay)
+
+	headers := append(ResetRetryHeaders(msg.Headers),
+		kafka.Header{Key: headerAttempt, Value: []byte(strconv.Itoa(metadata.Attempt + 1))},
+		kafka.Header{Key: headerRetryAt, Value: []byte(strconv.FormatInt(retryAt.UnixMilli(), 10))},
+		kafka.Header{Key: headerRetryTopic, Value: []byte(msg.Topic)},
+	)
+
+	retryMsg := kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+
+	if err := mp.retry.producer(mp.DelayTopic(msg.Topic)).PublishMessage(ctx, retryMsg); err != nil {
+		mp.logKafkaMessage(ctx, false, childMsg, err, "Error to reschedule message")
+		return OutcomeFailed
+	}
+
+	mp.logKafkaMessage(ctx, false, childMsg, nil, fmt.Sprintf("Message rescheduled in %s", delay.Round(time.Second)))
+	return OutcomeRescheduled
+}
+
+// narrowRecipients rewrites msg so a reschedule only targets the recipients
+// of a PartialFailure in err, stored in the payload field named field. The
+// field is a list of ids, or of objects keyed by their endpoint like webpush
+// subscriptions. The rewritten value is a JSON envelope. msg is returned as is
+// when err is not a partial failure.
+func narrowRecipients(msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg, field string, err error) (kafka.Message, error) {
+	recipients, ok := providerClient.FailedRecipients(err)
+	if !ok {
+		return msg, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(consumedKafkaMsg.Data))
+	decoder.UseNumber()
+
+	var payload map[string]interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return msg, err
+	}
+	payload[field] = keepRecipients(payload[field], recipients)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return msg, err
+	}
+
+	envelope := *consumedKafkaMsg
+	envelope.Data = data
+
+	value, err := json.Marshal(&envelope)
+	if err != nil {
+		return msg, err
+	}
+
+	// An absent schema_version means 1, zero would fail the envelope schema.
+	if envelope.SchemaVersion == 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(value, &fields); err != nil {
+			return msg, err
+		}
+		delete(fields, "schema_version")
+		if value, err = json.Marshal(fields); err != nil {
+			return msg, err
+		}
+	}
+
+	msg.Value = value
+	msg.Headers = dropHeaders(msg.Headers, serde.ContentTypeHeader)
+
+	return msg, nil
+}
+
+// keepRecipients returns the objects of list whose endpoint is one of
+// recipients, or recipients themselves when list holds ids.
+func keepRecipients(list interface{}, recipients []string) interface{} {
+	items, ok := list.([]interface{})
+	if !ok || len(items) == 0 {
+		return recipients
+	}
+	if _, ok := items[0].(map[string]interface{}); !ok {
+		return recipients
+	}
+
+	failed := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		failed[recipient] = true
+	}
+
+	kept := make([]interface{}, 0, len(recipients))
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			if endpoint, _ := object["endpoint"].(string); failed[endpoint] {
+				kept = append(kept, item)
+			}
+		}
+	}
+
+	return kept
+}
+
+// ResetRetryHeaders drops the attempt count and retry time so a replayed
+// message gets every attempt again.
+func ResetRetryHeaders(headers []kafka.Header) []kafka.Header {
+	return dropHeaders(headers, headerAttempt, headerRetryAt, headerRetryTopic)
+}
+
+func dropHeaders(headers []kafka.Header, keys ...string) []kafka.Header {
+	kept := make([]kafka.Header, 0, len(headers))
+	for _, header := range headers {
+		drop := false
+		for _, key := range keys {
+			if header.Key == key {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, header)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,147 @@
+package messageprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNarrowRecipients(t *testing.T) {
+	consumed := &model.ConsumedKafkaMsg{
+		TypeName:     "Info",
+		CategoryName: "push",
+		ChannelName:  "jmo",
+		Data:         model.Payload(`{"player_ids":["a","b","c"],"content":"hi","time_to_live":3600}`),
+	}
+	msg := kafka.Message{
+		Topic: "cns_dsp_jmo_push_reg",
+		Value: []byte("avro bytes"),
+		Headers: []kafka.Header{
+			{Key: "trace_id", Value: []byte("t1")},
+			{Key: serde.ContentTypeHeader, Value: []byte(serde.ContentTypeAvro)},
+		},
+	}
+
+	err := providerClient.NewPartialFailure([]string{"c"}, []error{errors.New("status code 500")})
+	narrowed, narrowErr := narrowRecipients(msg, consumed, "player_ids", err)
+	if narrowErr != nil {
+		t.Fatal(narrowErr)
+	}
+
+	if narrowed.Topic != msg.Topic {
+		t.Errorf("topic = %s, want %s", narrowed.Topic, msg.Topic)
+	}
+	if !reflect.DeepEqual(narrowed.Headers, []kafka.Header{{Key: "trace_id", Value: []byte("t1")}}) {
+		t.Errorf("headers = %v, want the content type dropped", narrowed.Headers)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(narrowed.Value, &envelope); err != nil {
+		t.Fatalf("value %s is not JSON: %v", narrowed.Value, err)
+	}
+	if _, ok := envelope["schema_version"]; ok {
+		t.Errorf("envelope has schema_version %v, want it absent", envelope["schema_version"])
+	}
+
+	var retried model.ConsumedKafkaMsg
+	if err := json.Unmarshal(narrowed.Value, &retried); err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(retried.Data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"player_ids": []interface{}{"c"}, "content": "hi", "time_to_live": float64(3600)}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+
+	// Anything but a partial failure reschedules the message untouched.
+	same, _ := narrowRecipients(msg, consumed, "player_ids", &providerClient.RetryableError{Err: errors.New("timeout")})
+	if string(same.Value) != "avro bytes" || len(same.Headers) != 2 {
+		t.Errorf("message changed for a full failure: %+v", same)
+	}
+}
+
+func TestNarrowSubscriptions(t *testing.T) {
+	consumed := &model.ConsumedKafkaMsg{
+		TypeName:      "Info",
+		CategoryName:  "webpush",
+		ChannelName:   "jmo",
+		SchemaVersion: 2,
+		Data: model.Payload(`{"subscriptions":[` +
+			`{"endpoint":"https://push.example.com/a","keys":{"p256dh":"pa","auth":"aa"}},` +
+			`{"endpoint":"https://push.example.com/b","keys":{"p256dh":"pb","auth":"ab"}}` +
+			`],"title":"hi","body":"there"}`),
+	}
+	msg := kafka.Message{Topic: "cns_dsp_jmo_webpush_reg", Value: []byte("{}")}
+
+	err := providerClient.NewPartialFailure([]string{"https://push.example.com/b"}, []error{errors.New("status code 503")})
+	narrowed, narrowErr := narrowRecipients(msg, consumed, "subscriptions", err)
+	if narrowErr != nil {
+		t.Fatal(narrowErr)
+	}
+
+	var retried model.ConsumedKafkaMsg
+	if err := json.Unmarshal(narrowed.Value, &retried); err != nil {
+		t.Fatal(err)
+	}
+	if retried.SchemaVersion != 2 {
+		t.Errorf("schema_version = %d, want 2", retried.SchemaVersion)
+	}
+
+	var webpushMsg model.WebPush
+	if err := json.Unmarshal(retried.Data, &webpushMsg); err != nil {
+		t.Fatal(err)
+	}
+	if len(webpushMsg.Subscriptions) != 1 {
+		t.Fatalf("subscriptions = %+v, want only the failed one", webpushMsg.Subscriptions)
+	}
+	subscription := webpushMsg.Subscriptions[0]
+	if subscription.Endpoint != "https://push.example.com/b" || subscription.Keys.P256dh != "pb" || subscription.Keys.Auth != "ab" {
+		t.Errorf("subscription = %+v, want endpoint b with its keys", subscription)
+	}
+	if webpushMsg.Title != "hi" || webpushMsg.Body != "there" {
+		t.Errorf("payload = %+v, want the other fields kept", webpushMsg)
+	}
+}
+
+func TestRescheduleThrottledOutcome(t *testing.T) {
+	mp := &MessageProcessor{
+		cfg:    &config.Config{Project: &config.Project{ServiceName: "dispatch-service"}},
+		logger: logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		retry:  &retryScheduler{maxAttempts: 3, maxDelay: time.Minute, delayTopicSuffix: "_delay"},
+	}
+	msg := kafka.Message{Topic: "cns_dsp_jmo_sms_reg"}
+	throttled := &providerClient.RetryableError{Err: errors.New("status code 429"), After: time.Second}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+	}{
+		{name: "not retryable", err: errors.New("status code 400")},
+		{name: "attempts exhausted", attempt: 3, err: throttled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := contextMd.SetMetadataToNewContext(context.Background(), "t1", msg.Topic, tt.attempt)
+			if got := mp.rescheduleThrottled(ctx, msg, nil, tt.err); got != OutcomeFailed {
+				t.Errorf("outcome = %s, want %s", got, OutcomeFailed)
+			}
+		})
+	}
+}
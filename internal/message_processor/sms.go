@@ -10,22 +10,29 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
-func (mp *MessageProcessor) processSms(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) {
-	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg.Headers, "MessageProcessor.processSms")
+func (mp *MessageProcessor) processSms(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processSms")
 	defer span.End()
 
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
 	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
 
 	smsMsg := &model.Sms{}
 	if err := json.Unmarshal(consumedKafkaMsg.Data, smsMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
 		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
-		return
+		return OutcomeFailed
 	}
 
+	outcome := OutcomeSent
 	if err := mp.usecase.HandleSMS(ctx, publishedKafkaMsg, smsMsg); err != nil {
+		tracerClient.RecordError(span, err)
 		mp.logKafkaMessage(ctx, false, smsMsg, err, constants.ErrorProcessingMessage)
+		outcome = mp.rescheduleThrottled(ctx, msg, smsMsg, err)
 	}
 
 	mp.commitAndLogMsg(ctx, r, msg, smsMsg)
+	return outcome
 }
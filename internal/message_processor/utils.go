@@ -9,25 +9,49 @@ import (
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (mp *MessageProcessor) getProcessorFunc(categoryName string) (func(context.Context, *kafka.Reader, kafka.Message, *model.ConsumedKafkaMsg), bool) {
-	processorFuncMap := map[string]func(context.Context, *kafka.Reader, kafka.Message, *model.ConsumedKafkaMsg){
-		constants.NOTIF_TYPE_EMAIL: mp.processEmail,
-		constants.NOTIF_TYPE_SMS:   mp.processSms,
-		constants.NOTIF_TYPE_INAPP: mp.processInApp,
-		constants.NOTIF_TYPE_PUSH:  mp.processPush,
+func (mp *MessageProcessor) getProcessorFunc(categoryName string) (func(context.Context, *kafka.Reader, kafka.Message, *model.ConsumedKafkaMsg) Outcome, bool) {
+	processorFuncMap := map[string]func(context.Context, *kafka.Reader, kafka.Message, *model.ConsumedKafkaMsg) Outcome{
+		constants.NOTIF_TYPE_EMAIL:    mp.processEmail,
+		constants.NOTIF_TYPE_SMS:      mp.processSms,
+		constants.NOTIF_TYPE_INAPP:    mp.processInApp,
+		constants.NOTIF_TYPE_PUSH:     mp.processPush,
+		constants.NOTIF_TYPE_WHATSAPP: mp.processWhatsApp,
+		constants.NOTIF_TYPE_WEBHOOK:  mp.processWebhook,
+		constants.NOTIF_TYPE_WEBPUSH:  mp.processWebPush,
 	}
 
 	processorFunc, exists := processorFuncMap[categoryName]
 	return processorFunc, exists
 }
 
+func setMessageSpanAttributes(ctx context.Context, span trace.Span, consumedKafkaMsg *model.ConsumedKafkaMsg) {
+	metadata, _ := contextMd.GetMetadataFromContext(ctx)
+
+	span.SetAttributes(
+		tracerClient.ChannelKey.String(consumedKafkaMsg.ChannelName),
+		tracerClient.CategoryKey.String(consumedKafkaMsg.CategoryName),
+		tracerClient.TypeKey.String(consumedKafkaMsg.TypeName),
+		tracerClient.AttemptKey.Int(metadata.Attempt),
+	)
+}
+
 func (mp *MessageProcessor) commitAndLogMsg(ctx context.Context, r *kafka.Reader, kafkaMsg kafka.Message, childMsg interface{}) {
+	// Replayed messages are read without a consumer group.
+	if r == nil {
+		return
+	}
+
 	if err := r.CommitMessages(ctx, kafkaMsg); err != nil {
+		mp.consumerStats.RecordCommit(kafkaMsg.Topic, err)
 		mp.logKafkaMessage(ctx, false, childMsg, err, "Error while committing message")
+		return
 	}
+	mp.consumerStats.RecordCommit(kafkaMsg.Topic, nil)
 	mp.logKafkaMessage(ctx, false, childMsg, nil, "Success to commit kafka message")
 }
 
@@ -72,7 +96,7 @@ func (mp *MessageProcessor) getKafkaLogFields(ctx context.Context, data interfac
 		EventName:         "",
 		TraceID:           fmt.Sprintf("TC%s", metadata.TraceID),
 		PrevTransactionID: "",
-		Body:              fmt.Sprint(data),
+		Body:              mp.logger.Redact(data),
 		Result:            "",
 		Error:             constants.FALSE,
 		FlagStartOrStop:   constants.STOP,
@@ -0,0 +1,38 @@
+package messageprocessor
+
+import (
+	"context"
+	"encoding/json"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+	"github.com/segmentio/kafka-go"
+)
+
+func (mp *MessageProcessor) processWebhook(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processWebhook")
+	defer span.End()
+
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
+	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
+
+	webhookMsg := &model.Webhook{}
+	if err := json.Unmarshal(consumedKafkaMsg.Data, webhookMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
+		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
+		return OutcomeFailed
+	}
+
+	outcome := OutcomeSent
+	if err := mp.usecase.HandleWebhook(ctx, publishedKafkaMsg, webhookMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, webhookMsg, err, constants.ErrorProcessingMessage)
+		outcome = mp.rescheduleThrottled(ctx, msg, webhookMsg, err)
+	}
+
+	mp.commitAndLogMsg(ctx, r, msg, webhookMsg)
+	return outcome
+}
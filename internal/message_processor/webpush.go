@@ -0,0 +1,46 @@
+package messageprocessor
+
+import (
+	"context"
+	"encoding/json"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+	"github.com/segmentio/kafka-go"
+)
+
+func (mp *MessageProcessor) processWebPush(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processWebPush")
+	defer span.End()
+
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
+	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
+
+	webpushMsg := &model.WebPush{}
+	if err := json.Unmarshal(consumedKafkaMsg.Data, webpushMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
+		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
+		return OutcomeFailed
+	}
+
+	outcome := OutcomeSent
+	if err := mp.usecase.HandleWebPush(ctx, publishedKafkaMsg, webpushMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, webpushMsg, err, constants.ErrorProcessingMessage)
+
+		// Only the subscriptions that failed are sent again.
+		retryMsg, narrowErr := narrowRecipients(msg, consumedKafkaMsg, "subscriptions", err)
+		if narrowErr != nil {
+			mp.logKafkaMessage(ctx, false, webpushMsg, narrowErr, "Error to narrow rescheduled message")
+			outcome = OutcomeFailed
+		} else {
+			outcome = mp.rescheduleThrottled(ctx, retryMsg, webpushMsg, err)
+		}
+	}
+
+	mp.commitAndLogMsg(ctx, r, msg, webpushMsg)
+	return outcome
+}
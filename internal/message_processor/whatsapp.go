@@ -0,0 +1,38 @@
+package messageprocessor
+
+import (
+	"context"
+	"encoding/json"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+	"github.com/segmentio/kafka-go"
+)
+
+func (mp *MessageProcessor) processWhatsApp(ctx context.Context, r *kafka.Reader, msg kafka.Message, consumedKafkaMsg *model.ConsumedKafkaMsg) Outcome {
+	ctx, span := tracerClient.StartKafkaConsumerTracerSpan(mp.tracer, ctx, &msg, "MessageProcessor.processWhatsApp")
+	defer span.End()
+
+	setMessageSpanAttributes(ctx, span, consumedKafkaMsg)
+
+	publishedKafkaMsg := createPulishedKafkaMessage(consumedKafkaMsg)
+
+	whatsappMsg := &model.WhatsApp{}
+	if err := json.Unmarshal(consumedKafkaMsg.Data, whatsappMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, publishedKafkaMsg, err, constants.ErrorProcessingMessage)
+		mp.commitAndLogMsg(ctx, r, msg, publishedKafkaMsg)
+		return OutcomeFailed
+	}
+
+	outcome := OutcomeSent
+	if err := mp.usecase.HandleWhatsApp(ctx, publishedKafkaMsg, whatsappMsg); err != nil {
+		tracerClient.RecordError(span, err)
+		mp.logKafkaMessage(ctx, false, whatsappMsg, err, constants.ErrorProcessingMessage)
+		outcome = mp.rescheduleThrottled(ctx, msg, whatsappMsg, err)
+	}
+
+	mp.commitAndLogMsg(ctx, r, msg, whatsappMsg)
+	return outcome
+}
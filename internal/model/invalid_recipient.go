@@ -0,0 +1,15 @@
+package model
+
+// InvalidRecipient is published when a provider reports that a recipient such
+// as a push token or browser subscription no longer exists, so the owning
+// service can stop sending to it. A recipient the provider replaced carries the
+// replacement in CanonicalId and should be updated rather than dropped.
+type InvalidRecipient struct {
+	ChannelName  string `json:"channel_name"`
+	CategoryName string `json:"category_name"`
+	Provider     string `json:"provider"`
+	Recipient    string `json:"recipient"`
+	Reason       string `json:"reason"`
+	CanonicalId  string `json:"canonical_id,omitempty"`
+	DetectedAt   string `json:"detected_at"`
+}
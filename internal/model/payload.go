@@ -0,0 +1,60 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Payload is the category payload of an envelope. Producers send it either
+// as a base64 string, the encoding/json form of []byte, or inline as a JSON
+// object. Either way it holds the raw JSON of the payload once decoded.
+type Payload []byte
+
+func (p *Payload) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		*p = nil
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var encoded string
+		if err := json.Unmarshal(b, &encoded); err != nil {
+			return err
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return err
+		}
+		*p = decoded
+		return nil
+	case len(b) > 0 && (b[0] == '{' || b[0] == '['):
+		*p = append((*p)[:0], b...)
+		return nil
+	}
+
+	return errors.New("data must be a base64 string or a JSON object")
+}
+
+// inlinePublishedKafkaMsg shadows Data so it is written as JSON instead of
+// base64.
+type inlinePublishedKafkaMsg struct {
+	*PublishedKafkaMsg
+	Data json.RawMessage `json:"data"`
+}
+
+// Inline returns msg in the form written when tracking events are emitted
+// with inline data. Data that is not JSON stays base64.
+func (msg *PublishedKafkaMsg) Inline() interface{} {
+	if !json.Valid(msg.Data) {
+		return msg
+	}
+
+	return &inlinePublishedKafkaMsg{
+		PublishedKafkaMsg: msg,
+		Data:              msg.Data,
+	}
+}
@@ -7,6 +7,9 @@ type Push struct {
 	PictureUrl string                 `json:"picture_url"`
 	Data       map[string]interface{} `json:"data"`
 	IsIos      bool                   `json:"is_ios"`
+	CollapseId string                 `json:"collapse_id,omitempty"`
+	PushType   string                 `json:"push_type,omitempty"`
+	TimeToLive int                    `json:"time_to_live,omitempty"`
 	MessageId  string                 `json:"message_id,omitempty"`
 	Status     string                 `json:"status,omitempty"`
 }
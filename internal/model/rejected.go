@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// RejectedKafkaMsg reports a dispatch message that failed validation. It
+// carries the original value so the producer can fix and resend it.
+type RejectedKafkaMsg struct {
+	Topic         string           `json:"topic"`
+	Partition     int              `json:"partition"`
+	Offset        int64            `json:"offset"`
+	SchemaVersion int              `json:"schema_version,omitempty"`
+	TypeName      string           `json:"type_name,omitempty"`
+	CategoryName  string           `json:"category_name,omitempty"`
+	ChannelName   string           `json:"channel_name,omitempty"`
+	Errors        []RejectionError `json:"errors"`
+	Original      []byte           `json:"original"`
+	RejectedAt    time.Time        `json:"rejected_at"`
+}
+
+// RejectionError is one failed schema rule, Path is a JSON pointer into the
+// envelope, payload fields are under /data.
+type RejectionError struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
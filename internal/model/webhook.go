@@ -0,0 +1,12 @@
+package model
+
+import "encoding/json"
+
+type Webhook struct {
+	Url       string            `json:"url"`
+	EventName string            `json:"event_name,omitempty"`
+	Payload   json.RawMessage   `json:"payload"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	MessageId string            `json:"message_id,omitempty"`
+	Status    string            `json:"status,omitempty"`
+}
@@ -0,0 +1,23 @@
+package model
+
+type WebPush struct {
+	Subscriptions []WebPushSubscription  `json:"subscriptions"`
+	Title         string                 `json:"title"`
+	Body          string                 `json:"body"`
+	Icon          string                 `json:"icon,omitempty"`
+	Url           string                 `json:"url,omitempty"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+	Topic         string                 `json:"topic,omitempty"`
+	TimeToLive    int                    `json:"time_to_live,omitempty"`
+	MessageId     string                 `json:"message_id,omitempty"`
+	Status        string                 `json:"status,omitempty"`
+}
+
+// WebPushSubscription is the PushSubscription JSON produced by the browser.
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
@@ -0,0 +1,19 @@
+package model
+
+type WhatsApp struct {
+	RecipientPhoneNumber string         `json:"recipient_phone_number"`
+	TemplateName         string         `json:"template_name"`
+	Language             string         `json:"language"`
+	Parameters           []string       `json:"parameters,omitempty"`
+	Media                *WhatsAppMedia `json:"media,omitempty"`
+	MessageId            string         `json:"message_id,omitempty"`
+	Status               string         `json:"status,omitempty"`
+}
+
+// WhatsAppMedia is sent as the template header, Type is one of image,
+// document or video.
+type WhatsAppMedia struct {
+	Type     string `json:"type"`
+	Url      string `json:"url"`
+	Filename string `json:"filename,omitempty"`
+}
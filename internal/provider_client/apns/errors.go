@@ -0,0 +1,62 @@
+package apns
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ReasonError is an APNs rejection. Compare with errors.Is against the
+// Err* values, which match on Reason only.
+type ReasonError struct {
+	StatusCode int
+	Reason     string
+	Token      string
+	Timestamp  time.Time
+}
+
+func (e *ReasonError) Error() string {
+	if e.StatusCode == 0 {
+		return "apns: " + e.Reason
+	}
+	return fmt.Sprintf("apns: status code %d: %s", e.StatusCode, e.Reason)
+}
+
+func (e *ReasonError) Is(target error) bool {
+	t, ok := target.(*ReasonError)
+	return ok && t.Reason == e.Reason
+}
+
+var (
+	ErrBadDeviceToken              = &ReasonError{Reason: "BadDeviceToken"}
+	ErrUnregistered                = &ReasonError{Reason: "Unregistered"}
+	ErrDeviceTokenNotForTopic      = &ReasonError{Reason: "DeviceTokenNotForTopic"}
+	ErrBadTopic                    = &ReasonError{Reason: "BadTopic"}
+	ErrPayloadTooLarge             = &ReasonError{Reason: "PayloadTooLarge"}
+	ErrExpiredProviderToken        = &ReasonError{Reason: "ExpiredProviderToken"}
+	ErrInvalidProviderToken        = &ReasonError{Reason: "InvalidProviderToken"}
+	ErrTooManyProviderTokenUpdates = &ReasonError{Reason: "TooManyProviderTokenUpdates"}
+	ErrTooManyRequests             = &ReasonError{Reason: "TooManyRequests"}
+	ErrInternalServerError         = &ReasonError{Reason: "InternalServerError"}
+	ErrServiceUnavailable          = &ReasonError{Reason: "ServiceUnavailable"}
+	ErrShutdown                    = &ReasonError{Reason: "Shutdown"}
+)
+
+// IsInvalidToken reports whether the device token should be dropped.
+// DeviceTokenNotForTopic is left out, it points at a misconfigured topic
+// rather than a device that is gone.
+func (e *ReasonError) IsInvalidToken() bool {
+	switch e.Reason {
+	case ErrBadDeviceToken.Reason, ErrUnregistered.Reason:
+		return true
+	}
+	return e.StatusCode == http.StatusGone
+}
+
+func (e *ReasonError) retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package apns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+)
+
+const (
+	PushTypeAlert      = "alert"
+	PushTypeBackground = "background"
+
+	priorityImmediate = "10"
+	priorityConserve  = "5"
+)
+
+func init() {
+	providerClient.RegisterPushSender(constants.PROVIDER_APNS, NewPushSender)
+}
+
+type PushSender struct {
+	deps        *providerClient.Deps
+	cfg         *config.ApnsProvider
+	host        string
+	client      *http.Client
+	tokens      *tokenSource
+	expiration  time.Duration
+	concurrency int
+}
+
+func NewPushSender(deps *providerClient.Deps) (providerClient.PushSender, error) {
+	cfg := deps.Cfg.ProviderClient.ApnsProvider
+	if cfg == nil || cfg.KeyFile == "" {
+		return nil, errors.New("apns key file is not configured")
+	}
+
+	p8, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read apns key: %w", err)
+	}
+
+	insecure, _ := strconv.ParseBool(cfg.InsecureSkipVerify)
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: tracerClient.NewTransport(deps.Tracer, &http.Transport{
+			ForceAttemptHTTP2:   true,
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: insecure},
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     5 * time.Minute,
+		}),
+	}
+
+	return NewSender(deps, cfg, p8, client)
+}
+
+// NewSender builds the sender from an explicit config, signing key and client
+// so it can be pointed at a local HTTP/2 stub.
+func NewSender(deps *providerClient.Deps, cfg *config.ApnsProvider, p8 []byte, client *http.Client) (*PushSender, error) {
+	if cfg.KeyId == "" || cfg.TeamId == "" || cfg.Topic == "" {
+		return nil, errors.New("apns key id, team id and topic are required")
+	}
+
+	tokens, err := newTokenSource(p8, cfg.KeyId, cfg.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	expiration, err := time.ParseDuration(cfg.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apns expiration: %w", err)
+	}
+
+	concurrency, err := strconv.Atoi(cfg.Concurrency)
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("invalid apns concurrency %q", cfg.Concurrency)
+	}
+
+	return &PushSender{
+		deps:        deps,
+		cfg:         cfg,
+		host:        strings.TrimRight(cfg.Host, "/"),
+		client:      client,
+		tokens:      tokens,
+		expiration:  expiration,
+		concurrency: concurrency,
+	}, nil
+}
+
+func (s *PushSender) Name() string {
+	return constants.PROVIDER_APNS
+}
+
+type apsAlert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type aps struct {
+	Alert            *apsAlert `json:"alert,omitempty"`
+	Sound            string    `json:"sound,omitempty"`
+	ContentAvailable int       `json:"content-available,omitempty"`
+	MutableContent   int       `json:"mutable-content,omitempty"`
+}
+
+type errorRes struct {
+	Reason    string `json:"reason"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SendPush delivers to every device token in PlayerIds. The push succeeds if
+// at least one token accepted it, rejections are logged per token and tokens
+// APNs no longer knows are reported in InvalidRecipients. Tokens that failed
+// with a retryable answer next to accepted ones make a PartialFailure.
+func (s *PushSender) SendPush(ctx context.Context, channel string, pushMsg *model.Push) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendApnsPush")
+	defer span.End()
+
+	if len(pushMsg.PlayerIds) == 0 {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: errors.New("push has no device token")})
+	}
+
+	payload, err := getPayload(pushMsg)
+	if err != nil {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: err})
+	}
+
+	header := s.getHeader(ctx, pushMsg)
+
+	result := &providerClient.DeliveryResult{
+		Provider:          constants.PROVIDER_APNS,
+		ResultCode:        strconv.Itoa(http.StatusOK),
+		InvalidRecipients: make(map[string]string),
+	}
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		errs        []error
+		retryTokens []string
+		retryErrs   []error
+		semaphore   = make(chan struct{}, s.concurrency)
+	)
+
+	for _, deviceToken := range pushMsg.PlayerIds {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(deviceToken string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			apnsId, err := s.send(ctx, deviceToken, header, payload)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var reasonErr *ReasonError
+			switch {
+			case err == nil:
+				result.Succeeded++
+				if result.MessageID == "" {
+					result.MessageID = apnsId
+				}
+			case errors.As(err, &reasonErr) && reasonErr.IsInvalidToken():
+				result.InvalidRecipients[deviceToken] = reasonErr.Reason
+				errs = append(errs, err)
+			case providerClient.IsRetryable(err):
+				result.Failed++
+				errs = append(errs, err)
+				retryTokens = append(retryTokens, deviceToken)
+				retryErrs = append(retryErrs, err)
+			default:
+				result.Failed++
+				errs = append(errs, err)
+			}
+		}(deviceToken)
+	}
+	wg.Wait()
+
+	result.Message = fmt.Sprintf("%d accepted, %d rejected", result.Succeeded, len(errs))
+
+	if result.Succeeded == 0 {
+		return result, tracerClient.RecordError(span, errors.Join(errs...))
+	}
+
+	for _, err := range errs {
+		s.deps.LogRestMessage(ctx, s.host, constants.METHOD_POST, pushMsg, err, "Device token rejected")
+	}
+
+	if len(retryTokens) > 0 {
+		return result, tracerClient.RecordError(span, providerClient.NewPartialFailure(retryTokens, retryErrs))
+	}
+
+	return result, nil
+}
+
+func (s *PushSender) send(ctx context.Context, deviceToken string, header http.Header, payload []byte) (string, error) {
+	url := s.host + "/3/device/" + deviceToken
+
+	for attempt := 1; ; attempt++ {
+		token, err := s.tokens.Token()
+		if err != nil {
+			return "", &providerClient.PermanentError{Err: err}
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, url, bytes.NewReader(payload))
+		if err != nil {
+			return "", &providerClient.PermanentError{Err: err}
+		}
+		httpReq.Header = header.Clone()
+		httpReq.Header.Set("Authorization", "bearer "+token)
+
+		httpRes, err := s.client.Do(httpReq)
+		if err != nil {
+			return "", &providerClient.RetryableError{Err: err}
+		}
+
+		httpResBody, _ := io.ReadAll(httpRes.Body)
+		httpRes.Body.Close()
+
+		if httpRes.StatusCode == http.StatusOK {
+			return httpRes.Header.Get("apns-id"), nil
+		}
+
+		res := &errorRes{}
+		_ = json.Unmarshal(httpResBody, res)
+
+		reasonErr := &ReasonError{
+			StatusCode: httpRes.StatusCode,
+			Reason:     res.Reason,
+			Token:      deviceToken,
+		}
+		if res.Timestamp > 0 {
+			reasonErr.Timestamp = time.UnixMilli(res.Timestamp)
+		}
+
+		// A provider token can expire between the cache check and the send,
+		// refresh it once before giving up.
+		if attempt == 1 && (errors.Is(reasonErr, ErrExpiredProviderToken) || errors.Is(reasonErr, ErrInvalidProviderToken)) {
+			s.tokens.Invalidate(token)
+			continue
+		}
+
+		if reasonErr.retryable() {
+			return "", providerClient.RateLimited(httpRes, &providerClient.RetryableError{Err: reasonErr})
+		}
+		return "", &providerClient.PermanentError{Err: reasonErr}
+	}
+}
+
+func (s *PushSender) getHeader(ctx context.Context, pushMsg *model.Push) http.Header {
+	pushType := pushMsg.PushType
+	if pushType == "" {
+		pushType = PushTypeAlert
+		if pushMsg.Heading == "" && pushMsg.Content == "" {
+			pushType = PushTypeBackground
+		}
+	}
+
+	// Background pushes must use priority 5, APNs rejects them otherwise.
+	priority := priorityConserve
+	if pushType != PushTypeBackground && s.deps.Priority(ctx) == "high" {
+		priority = priorityImmediate
+	}
+
+	expiration := s.expiration
+	if pushMsg.TimeToLive > 0 {
+		expiration = time.Duration(pushMsg.TimeToLive) * time.Second
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("apns-topic", s.cfg.Topic)
+	header.Set("apns-push-type", pushType)
+	header.Set("apns-priority", priority)
+	header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(expiration).Unix(), 10))
+	if pushMsg.CollapseId != "" {
+		header.Set("apns-collapse-id", pushMsg.CollapseId)
+	}
+
+	return header
+}
+
+func getPayload(pushMsg *model.Push) ([]byte, error) {
+	payload := make(map[string]interface{}, len(pushMsg.Data)+2)
+	for key, value := range pushMsg.Data {
+		payload[key] = value
+	}
+
+	a := aps{}
+	if pushMsg.Heading == "" && pushMsg.Content == "" {
+		a.ContentAvailable = 1
+	} else {
+		a.Alert = &apsAlert{
+			Title: pushMsg.Heading,
+			Body:  pushMsg.Content,
+		}
+		a.Sound = "default"
+	}
+
+	if pushMsg.PictureUrl != "" {
+		a.MutableContent = 1
+		payload["picture_url"] = pushMsg.PictureUrl
+	}
+
+	payload["aps"] = a
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// APNs limit for regular notifications.
+	if len(b) > 4096 {
+		return nil, ErrPayloadTooLarge
+	}
+
+	return b, nil
+}
@@ -0,0 +1,319 @@
+package apns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stub is an HTTP/2 APNs stand-in answering per device token.
+type stub struct {
+	t      *testing.T
+	answer func(deviceToken string, bearer string) (int, string, http.Header)
+
+	mu      sync.Mutex
+	bearers []string
+	tokens  []string
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 {
+		s.t.Errorf("request over HTTP/%d, want HTTP/2", r.ProtoMajor)
+	}
+	if r.Header.Get("apns-topic") != "id.go.bpjsketenagakerjaan.jmo" {
+		s.t.Errorf("apns-topic = %q", r.Header.Get("apns-topic"))
+	}
+	io.Copy(io.Discard, r.Body)
+
+	deviceToken := strings.TrimPrefix(r.URL.Path, "/3/device/")
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")
+
+	s.mu.Lock()
+	s.bearers = append(s.bearers, bearer)
+	s.tokens = append(s.tokens, deviceToken)
+	s.mu.Unlock()
+
+	status, reason, header := s.answer(deviceToken, bearer)
+	for key, values := range header {
+		w.Header()[key] = values
+	}
+	if status == http.StatusOK {
+		w.Header().Set("apns-id", "id-"+deviceToken)
+		w.WriteHeader(status)
+		return
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, `{"reason":"`+reason+`"}`)
+}
+
+func (s *stub) distinctBearers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, bearer := range s.bearers {
+		seen[bearer] = true
+	}
+	return len(seen)
+}
+
+func TestSendPushFanOut(t *testing.T) {
+	st := &stub{t: t, answer: func(deviceToken string, _ string) (int, string, http.Header) {
+		switch deviceToken {
+		case "gone":
+			return http.StatusGone, "Unregistered", nil
+		case "bad-topic":
+			return http.StatusBadRequest, "BadTopic", nil
+		case "busy":
+			return http.StatusTooManyRequests, "TooManyRequests", http.Header{"Retry-After": {"12"}}
+		case "down":
+			return http.StatusInternalServerError, "InternalServerError", nil
+		}
+		return http.StatusOK, "", nil
+	}}
+	sender := newTestSender(t, st, "4")
+
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{
+		PlayerIds: []string{"ok-1", "gone", "bad-topic", "busy", "ok-2", "down"},
+		Heading:   "Saldo JHT",
+		Content:   "Saldo anda telah diperbarui",
+	})
+
+	if result.Succeeded != 2 || result.Failed != 3 {
+		t.Errorf("succeeded %d failed %d, want 2 and 3", result.Succeeded, result.Failed)
+	}
+	if !reflect.DeepEqual(result.InvalidRecipients, map[string]string{"gone": "Unregistered"}) {
+		t.Errorf("invalid recipients = %v", result.InvalidRecipients)
+	}
+	if !strings.HasPrefix(result.MessageID, "id-ok-") {
+		t.Errorf("message id = %q", result.MessageID)
+	}
+
+	// The accepted tokens must not be retried, the throttled ones must.
+	recipients, ok := providerClient.FailedRecipients(err)
+	if !ok {
+		t.Fatalf("err = %v, want a partial failure", err)
+	}
+	sort.Strings(recipients)
+	if !reflect.DeepEqual(recipients, []string{"busy", "down"}) {
+		t.Errorf("failed recipients = %v, want busy and down", recipients)
+	}
+	if after, _ := providerClient.RetryAfter(err); after != 12*time.Second {
+		t.Errorf("retry after = %s, want 12s", after)
+	}
+
+	st.mu.Lock()
+	sort.Strings(st.tokens)
+	st.mu.Unlock()
+	if !reflect.DeepEqual(st.tokens, []string{"bad-topic", "busy", "down", "gone", "ok-1", "ok-2"}) {
+		t.Errorf("requested tokens = %v", st.tokens)
+	}
+}
+
+func TestSendPushPermanentFailuresOnly(t *testing.T) {
+	st := &stub{t: t, answer: func(deviceToken string, _ string) (int, string, http.Header) {
+		if deviceToken == "bad" {
+			return http.StatusBadRequest, "BadDeviceToken", nil
+		}
+		return http.StatusOK, "", nil
+	}}
+	sender := newTestSender(t, st, "2")
+
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"ok", "bad"}, Content: "hi"})
+	if err != nil {
+		t.Fatalf("err = %v, want nil when nothing is left to retry", err)
+	}
+	if result.InvalidRecipients["bad"] != "BadDeviceToken" {
+		t.Errorf("invalid recipients = %v", result.InvalidRecipients)
+	}
+}
+
+func TestSendPushWrongTopicKeepsTokens(t *testing.T) {
+	st := &stub{t: t, answer: func(deviceToken string, _ string) (int, string, http.Header) {
+		return http.StatusBadRequest, "DeviceTokenNotForTopic", nil
+	}}
+	sender := newTestSender(t, st, "2")
+
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"a", "b"}, Content: "hi"})
+
+	var permanentErr *providerClient.PermanentError
+	if !errors.As(err, &permanentErr) {
+		t.Fatalf("err = %v, want permanent", err)
+	}
+	if !errors.Is(err, ErrDeviceTokenNotForTopic) {
+		t.Errorf("err = %v, want DeviceTokenNotForTopic", err)
+	}
+	if len(result.InvalidRecipients) != 0 || result.Failed != 2 {
+		t.Errorf("result = %+v, want both tokens failed and none invalid", result)
+	}
+}
+
+func TestIsInvalidToken(t *testing.T) {
+	tests := []struct {
+		err  *ReasonError
+		want bool
+	}{
+		{err: &ReasonError{StatusCode: http.StatusBadRequest, Reason: "BadDeviceToken"}, want: true},
+		{err: &ReasonError{StatusCode: http.StatusBadRequest, Reason: "Unregistered"}, want: true},
+		{err: &ReasonError{StatusCode: http.StatusGone, Reason: "ExpiredToken"}, want: true},
+		{err: &ReasonError{StatusCode: http.StatusBadRequest, Reason: "DeviceTokenNotForTopic"}, want: false},
+		{err: &ReasonError{StatusCode: http.StatusBadRequest, Reason: "BadTopic"}, want: false},
+		{err: &ReasonError{StatusCode: http.StatusTooManyRequests, Reason: "TooManyRequests"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.IsInvalidToken(); got != tt.want {
+			t.Errorf("%v: IsInvalidToken = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSendPushAllRetryable(t *testing.T) {
+	st := &stub{t: t, answer: func(string, string) (int, string, http.Header) {
+		return http.StatusInternalServerError, "InternalServerError", nil
+	}}
+	sender := newTestSender(t, st, "2")
+
+	_, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"a", "b"}, Content: "hi"})
+	if !providerClient.IsRetryable(err) {
+		t.Fatalf("err = %v, want retryable", err)
+	}
+	if _, partial := providerClient.FailedRecipients(err); partial {
+		t.Fatalf("err = %v is partial, nothing was accepted", err)
+	}
+}
+
+func TestProviderTokenIsCached(t *testing.T) {
+	st := &stub{t: t, answer: func(string, string) (int, string, http.Header) {
+		return http.StatusOK, "", nil
+	}}
+	sender := newTestSender(t, st, "2")
+
+	for i := 0; i < 3; i++ {
+		if _, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"a", "b"}, Content: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := st.distinctBearers(); n != 1 {
+		t.Fatalf("%d provider tokens used, want 1", n)
+	}
+}
+
+func TestExpiredProviderTokenRefreshesOnce(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		first string
+	)
+	st := &stub{t: t, answer: func(_ string, bearer string) (int, string, http.Header) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if first == "" {
+			first = bearer
+		}
+		if bearer == first {
+			return http.StatusForbidden, "ExpiredProviderToken", nil
+		}
+		return http.StatusOK, "", nil
+	}}
+	sender := newTestSender(t, st, "8")
+
+	deviceTokens := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: deviceTokens, Content: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Succeeded != len(deviceTokens) {
+		t.Errorf("succeeded = %d, want %d", result.Succeeded, len(deviceTokens))
+	}
+
+	// Every rejected request shares the stale token, it is replaced once.
+	if n := st.distinctBearers(); n != 2 {
+		t.Fatalf("%d provider tokens used, want 2", n)
+	}
+}
+
+func TestTokenSourceInvalidate(t *testing.T) {
+	tokens, err := newTokenSource(testKey(t), "KEY123", "TEAM456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale, _ := tokens.Token()
+	tokens.Invalidate(stale)
+	fresh, _ := tokens.Token()
+	if fresh == stale {
+		t.Fatal("Invalidate kept the stale token")
+	}
+
+	// A late answer for the stale token leaves the fresh one alone.
+	tokens.Invalidate(stale)
+	if again, _ := tokens.Token(); again != fresh {
+		t.Fatal("a late Invalidate dropped the fresh token")
+	}
+}
+
+func newTestSender(t *testing.T, handler *stub, concurrency string) *PushSender {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	cfg := &config.ApnsProvider{
+		Host:        server.URL,
+		KeyId:       "KEY123",
+		TeamId:      "TEAM456",
+		Topic:       "id.go.bpjsketenagakerjaan.jmo",
+		Expiration:  "24h",
+		Concurrency: concurrency,
+	}
+
+	deps := providerClient.NewDeps(
+		logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		&config.Config{Project: &config.Project{ServiceName: "dispatch-service", Priority: "reg"}},
+		trace.NewNoopTracerProvider().Tracer("test"),
+	)
+
+	sender, err := NewSender(deps, cfg, testKey(t), server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
@@ -0,0 +1,72 @@
+package apns
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/jws"
+)
+
+// APNs rejects tokens older than an hour and throttles tokens refreshed more
+// often than every 20 minutes, so one token is reused in between.
+const tokenRefreshInterval = 50 * time.Minute
+
+type tokenSource struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func newTokenSource(p8 []byte, keyID string, teamID string) (*tokenSource, error) {
+	key, err := jws.ParseP256PrivateKey(p8)
+	if err != nil {
+		return nil, fmt.Errorf("apns key: %w", err)
+	}
+
+	return &tokenSource{
+		key:    key,
+		keyID:  keyID,
+		teamID: teamID,
+	}, nil
+}
+
+func (t *tokenSource) Token() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Since(t.issuedAt) < tokenRefreshInterval {
+		return t.token, nil
+	}
+
+	now := time.Now()
+	token, err := jws.SignES256(t.key, map[string]interface{}{"kid": t.keyID}, map[string]interface{}{
+		"iss": t.teamID,
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	t.token = token
+	t.issuedAt = now
+
+	return token, nil
+}
+
+// Invalidate drops the cached token after APNs answered ExpiredProviderToken
+// or InvalidProviderToken to a request signed with token. Concurrent answers
+// for the same token refresh it once, later ones find a newer token cached.
+func (t *tokenSource) Invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+	}
+}
@@ -0,0 +1,120 @@
+package provider_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+)
+
+// ChunkSendFunc sends one chunk of recipients. A nil error with a result
+// reports per recipient outcomes, an error fails the whole chunk.
+type ChunkSendFunc func(ctx context.Context, recipients []string) (*DeliveryResult, error)
+
+// SendChunks splits recipients into chunks of at most size and sends up to
+// concurrency chunks at a time. Results are summed into one DeliveryResult
+// whose MessageID is that of the first chunk. An error is returned when every
+// chunk failed. Otherwise chunks that failed permanently are logged and
+// counted as Failed, and the recipients of chunks that failed with a
+// retryable error are returned in a PartialFailure.
+func (d *Deps) SendChunks(ctx context.Context, endpoint string, recipients []string, size int, concurrency int, send ChunkSendFunc) (*DeliveryResult, error) {
+	chunks := chunkRecipients(recipients, size)
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		results   = make([]*DeliveryResult, len(chunks))
+		errs      = make([]error, len(chunks))
+		semaphore = make(chan struct{}, concurrency)
+	)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, err := send(ctx, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i], errs[i] = result, err
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	total := &DeliveryResult{
+		InvalidRecipients:   make(map[string]string),
+		CanonicalRecipients: make(map[string]string),
+	}
+	var (
+		failedChunks    []error
+		retryRecipients []string
+		retryErrs       []error
+	)
+
+	for i, result := range results {
+		if errs[i] != nil {
+			failedChunks = append(failedChunks, errs[i])
+			if IsRetryable(errs[i]) {
+				retryRecipients = append(retryRecipients, chunks[i]...)
+				retryErrs = append(retryErrs, errs[i])
+				continue
+			}
+			total.Failed += len(chunks[i])
+			continue
+		}
+
+		if total.MessageID == "" {
+			total.Provider = result.Provider
+			total.MessageID = result.MessageID
+			total.ResultCode = result.ResultCode
+		}
+		total.Succeeded += result.Succeeded
+		total.Failed += result.Failed
+		for recipient, reason := range result.InvalidRecipients {
+			total.InvalidRecipients[recipient] = reason
+		}
+		for recipient, canonical := range result.CanonicalRecipients {
+			total.CanonicalRecipients[recipient] = canonical
+		}
+	}
+
+	total.Message = fmt.Sprintf("%d succeeded, %d failed, %d to retry, %d invalid in %d chunks",
+		total.Succeeded, total.Failed, len(retryRecipients), len(total.InvalidRecipients), len(chunks))
+
+	if len(failedChunks) == len(chunks) {
+		return total, errors.Join(failedChunks...)
+	}
+
+	for _, err := range failedChunks {
+		d.LogRestMessage(ctx, endpoint, constants.METHOD_POST, total.Message, err, "Chunk failed")
+	}
+
+	if len(retryRecipients) > 0 {
+		return total, NewPartialFailure(retryRecipients, retryErrs)
+	}
+
+	return total, nil
+}
+
+func chunkRecipients(recipients []string, size int) [][]string {
+	if size < 1 || len(recipients) <= size {
+		return [][]string{recipients}
+	}
+
+	chunks := make([][]string, 0, (len(recipients)+size-1)/size)
+	for start := 0; start < len(recipients); start += size {
+		end := start + size
+		if end > len(recipients) {
+			end = len(recipients)
+		}
+		chunks = append(chunks, recipients[start:end])
+	}
+
+	return chunks
+}
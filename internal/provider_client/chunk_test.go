@@ -0,0 +1,106 @@
+package provider_client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSendChunks(t *testing.T) {
+	deps := NewDeps(
+		logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		&config.Config{Project: &config.Project{ServiceName: "dispatch-service"}},
+		trace.NewNoopTracerProvider().Tracer("test"),
+	)
+
+	// Chunks are answered by their first recipient.
+	send := func(ctx context.Context, recipients []string) (*DeliveryResult, error) {
+		switch {
+		case strings.HasPrefix(recipients[0], "throttled"):
+			return nil, &RetryableError{Err: errors.New("status code 429"), After: 20 * time.Second}
+		case strings.HasPrefix(recipients[0], "down"):
+			return nil, &RetryableError{Err: errors.New("status code 502")}
+		case strings.HasPrefix(recipients[0], "bad"):
+			return nil, &PermanentError{Err: errors.New("status code 400")}
+		}
+		return &DeliveryResult{Provider: "stub", MessageID: "m-" + recipients[0], Succeeded: len(recipients)}, nil
+	}
+
+	tests := []struct {
+		name          string
+		recipients    []string
+		wantSucceeded int
+		wantFailed    int
+		wantRetry     []string
+		wantAfter     time.Duration
+		wantErr       bool
+	}{
+		{
+			name:          "all sent",
+			recipients:    []string{"ok1", "ok2", "ok3"},
+			wantSucceeded: 3,
+		},
+		{
+			name:          "retryable chunks are returned for retry",
+			recipients:    []string{"ok1", "ok2", "throttled1", "throttled2", "down1", "down2"},
+			wantSucceeded: 2,
+			wantRetry:     []string{"down1", "down2", "throttled1", "throttled2"},
+			wantAfter:     20 * time.Second,
+			wantErr:       true,
+		},
+		{
+			name:          "permanent chunks are final failures",
+			recipients:    []string{"ok1", "ok2", "bad1", "bad2", "down1", "down2"},
+			wantSucceeded: 2,
+			wantFailed:    2,
+			wantRetry:     []string{"down1", "down2"},
+			wantAfter:     DefaultRetryAfter,
+			wantErr:       true,
+		},
+		{
+			name:          "permanent chunk only",
+			recipients:    []string{"ok1", "ok2", "bad1", "bad2"},
+			wantSucceeded: 2,
+			wantFailed:    2,
+		},
+		{
+			name:       "every chunk failed",
+			recipients: []string{"down1", "down2", "bad1", "bad2"},
+			wantFailed: 2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := deps.SendChunks(context.Background(), "http://stub", tt.recipients, 2, 2, send)
+
+			if result.Succeeded != tt.wantSucceeded || result.Failed != tt.wantFailed {
+				t.Errorf("succeeded %d failed %d, want %d and %d", result.Succeeded, result.Failed, tt.wantSucceeded, tt.wantFailed)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			retry, partial := FailedRecipients(err)
+			sort.Strings(retry)
+			if !reflect.DeepEqual(retry, tt.wantRetry) {
+				t.Errorf("retry recipients = %v, want %v", retry, tt.wantRetry)
+			}
+			if partial {
+				if after, _ := RetryAfter(err); after != tt.wantAfter {
+					t.Errorf("retry after = %s, want %s", after, tt.wantAfter)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package provider_client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCoolingDown = errors.New("provider is cooling down")
+
+// Cooldown remembers, per provider, until when a rate limit asked us to back
+// off so every worker sharing it stops calling that provider meanwhile.
+type Cooldown struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func NewCooldown() *Cooldown {
+	return &Cooldown{
+		until: make(map[string]time.Time),
+	}
+}
+
+// Check returns a RetryableError with the remaining delay while provider is
+// cooling down, nil otherwise.
+func (c *Cooldown) Check(provider string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remaining := time.Until(c.until[provider])
+	if remaining <= 0 {
+		delete(c.until, provider)
+		return nil
+	}
+
+	return &RetryableError{Err: fmt.Errorf("%w: %s", ErrCoolingDown, provider), After: remaining}
+}
+
+// Observe starts or extends the cooldown of provider when err carries a
+// retry delay. Errors raised by Check itself are ignored, and so are partial
+// failures since the provider still accepted part of the send.
+func (c *Cooldown) Observe(provider string, err error) {
+	after, ok := RetryAfter(err)
+	if !ok || errors.Is(err, ErrCoolingDown) {
+		return
+	}
+
+	if _, partial := FailedRecipients(err); partial {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	until := time.Now().Add(after)
+	if until.After(c.until[provider]) {
+		c.until[provider] = until
+	}
+}
@@ -0,0 +1,110 @@
+package provider_client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoPushApp = errors.New("no push app configured")
+
+// NoPushAppError rejects a push for a channel that has no app credentials,
+// retrying cannot help so it is permanent.
+func NoPushAppError(provider string, channel string) error {
+	return &PermanentError{Err: fmt.Errorf("%w for channel %q on %s", ErrNoPushApp, channel, provider)}
+}
+
+// PermanentError marks a failure that will not succeed on a later attempt,
+// such as a rejected payload or an unknown recipient.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// RetryableError marks a transient failure such as a timeout or a 5xx answer.
+// After is the delay the provider asked for before the next attempt, zero
+// when it gave none.
+type RetryableError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+func IsRetryable(err error) bool {
+	var retryableErr *RetryableError
+	return errors.As(err, &retryableErr)
+}
+
+// RetryAfter returns the delay carried by the first RetryableError in err's
+// chain, false when there is none or it has no delay.
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryableErr *RetryableError
+	if errors.As(err, &retryableErr) && retryableErr.After > 0 {
+		return retryableErr.After, true
+	}
+	return 0, false
+}
+
+// PartialFailure is a multicast where some recipients were accepted while
+// Recipients failed with a retryable error. The message is retried for
+// Recipients only, the accepted ones must not get it twice.
+type PartialFailure struct {
+	Recipients []string
+	Err        error
+}
+
+func (e *PartialFailure) Error() string {
+	return fmt.Sprintf("%d recipients failed: %v", len(e.Recipients), e.Err)
+}
+
+func (e *PartialFailure) Unwrap() error {
+	return e.Err
+}
+
+// NewPartialFailure returns the retryable failure of recipients, delayed by
+// the longest delay the provider asked for or DefaultRetryAfter.
+func NewPartialFailure(recipients []string, errs []error) error {
+	after := time.Duration(0)
+	for _, err := range errs {
+		if d, ok := RetryAfter(err); ok && d > after {
+			after = d
+		}
+	}
+	if after == 0 {
+		after = DefaultRetryAfter
+	}
+
+	return &RetryableError{
+		Err:   &PartialFailure{Recipients: recipients, Err: errors.Join(errs...)},
+		After: after,
+	}
+}
+
+// FailedRecipients returns the recipients of the PartialFailure in err's
+// chain, false when err is not a partial failure.
+func FailedRecipients(err error) ([]string, bool) {
+	var partialErr *PartialFailure
+	if errors.As(err, &partialErr) {
+		return partialErr.Recipients, true
+	}
+	return nil, false
+}
@@ -0,0 +1,304 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	MAX_TTL         = 2419200
+	Priority_HIGH   = "high"
+	Priority_NORMAL = "normal"
+)
+
+// Legacy result errors meaning the registration token will never be valid
+// again.
+var invalidTokenErrors = map[string]bool{
+	"NotRegistered":       true,
+	"InvalidRegistration": true,
+}
+
+func init() {
+	providerClient.RegisterPushSender(constants.PROVIDER_FCM, NewPushSender)
+}
+
+type PushSender struct {
+	deps   *providerClient.Deps
+	client *http.Client
+	cfg    *config.FcmPushProvider
+	// chunkSize only applies to the legacy API, concurrency bounds legacy
+	// chunks and v1 requests alike.
+	chunkSize   int
+	concurrency int
+	// v1 holds the HTTP v1 clients of apps configured with a service account.
+	v1 map[string]*v1App
+}
+
+func NewPushSender(deps *providerClient.Deps) (providerClient.PushSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.FcmPushProvider, deps.NewHttpClient())
+}
+
+// NewSender builds the sender from an explicit config and client so it can be
+// pointed at a stub FCM endpoint.
+func NewSender(deps *providerClient.Deps, cfg *config.FcmPushProvider, client *http.Client) (*PushSender, error) {
+	if cfg == nil {
+		return nil, errors.New("fcm provider is not configured")
+	}
+
+	chunkSize, err := strconv.Atoi(cfg.ChunkSize)
+	if err != nil || chunkSize < 1 || chunkSize > 1000 {
+		return nil, fmt.Errorf("invalid fcm chunk size %q, must be 1 to 1000", cfg.ChunkSize)
+	}
+
+	concurrency, err := strconv.Atoi(cfg.Concurrency)
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("invalid fcm concurrency %q", cfg.Concurrency)
+	}
+
+	s := &PushSender{
+		deps:        deps,
+		client:      client,
+		cfg:         cfg,
+		chunkSize:   chunkSize,
+		concurrency: concurrency,
+		v1:          make(map[string]*v1App),
+	}
+
+	for channel, app := range cfg.Apps {
+		if app.ServiceAccountFile == "" {
+			continue
+		}
+
+		v1, err := newV1App(client, app)
+		if err != nil {
+			return nil, fmt.Errorf("fcm app %s: %w", channel, err)
+		}
+		s.v1[channel] = v1
+	}
+
+	return s, nil
+}
+
+func (s *PushSender) Name() string {
+	return constants.PROVIDER_FCM
+}
+
+func (s *PushSender) SendPush(ctx context.Context, channel string, pushMsg *model.Push) (*providerClient.DeliveryResult, error) {
+	channel = strings.ToLower(channel)
+
+	app, ok := s.cfg.Apps[channel]
+	if !ok {
+		return nil, providerClient.NoPushAppError(constants.PROVIDER_FCM, channel)
+	}
+
+	if v1, ok := s.v1[channel]; ok {
+		return s.sendV1(ctx, v1, pushMsg)
+	}
+
+	return s.deps.SendChunks(ctx, s.cfg.Url, pushMsg.PlayerIds, s.chunkSize, s.concurrency, func(ctx context.Context, registrationIds []string) (*providerClient.DeliveryResult, error) {
+		fcmPushRes, msgId, err := s.send(ctx, app.ApiKey, pushMsg, registrationIds)
+		if err != nil {
+			return nil, err
+		}
+
+		return getDeliveryResult(fcmPushRes, msgId, registrationIds), nil
+	})
+}
+
+// TokenOutcome is the legacy API answer for one registration token.
+// CanonicalId is set when FCM delivered to a token that has been replaced.
+type TokenOutcome struct {
+	Token       string
+	MessageId   string
+	Error       string
+	CanonicalId string
+}
+
+func (o *TokenOutcome) Invalid() bool {
+	return invalidTokenErrors[o.Error]
+}
+
+// Outcomes pairs the multicast results, which are in registration_ids order,
+// with the tokens they answer.
+func (r *FcmPushRes) Outcomes(registrationIds []string) []TokenOutcome {
+	outcomes := make([]TokenOutcome, 0, len(r.Results))
+	for i, res := range r.Results {
+		if i >= len(registrationIds) {
+			break
+		}
+
+		outcomes = append(outcomes, TokenOutcome{
+			Token:       registrationIds[i],
+			MessageId:   res["message_id"],
+			Error:       res["error"],
+			CanonicalId: res["registration_id"],
+		})
+	}
+
+	return outcomes
+}
+
+func getDeliveryResult(fcmPushRes *FcmPushRes, msgId string, registrationIds []string) *providerClient.DeliveryResult {
+	result := &providerClient.DeliveryResult{
+		Provider:            constants.PROVIDER_FCM,
+		MessageID:           msgId,
+		ResultCode:          strconv.Itoa(fcmPushRes.StatusCode),
+		Succeeded:           fcmPushRes.Success,
+		Failed:              fcmPushRes.Fail,
+		InvalidRecipients:   make(map[string]string),
+		CanonicalRecipients: make(map[string]string),
+	}
+
+	for _, outcome := range fcmPushRes.Outcomes(registrationIds) {
+		switch {
+		case outcome.Invalid():
+			result.InvalidRecipients[outcome.Token] = outcome.Error
+			result.Failed--
+		case outcome.CanonicalId != "" && outcome.CanonicalId != outcome.Token:
+			result.CanonicalRecipients[outcome.Token] = outcome.CanonicalId
+		}
+	}
+
+	return result
+}
+
+type FcmPushReq struct {
+	Data                  map[string]interface{} `json:"data,omitempty"`
+	To                    string                 `json:"to,omitempty"`
+	RegistrationIds       []string               `json:"registration_ids,omitempty"`
+	CollapseKey           string                 `json:"collapse_key,omitempty"`
+	Priority              string                 `json:"priority,omitempty"`
+	Notification          NotificationPayload    `json:"notification,omitempty"`
+	ContentAvailable      bool                   `json:"content_available,omitempty"`
+	DelayWhileIdle        bool                   `json:"delay_while_idle,omitempty"`
+	TimeToLive            int                    `json:"time_to_live,omitempty"`
+	RestrictedPackageName string                 `json:"restricted_package_name,omitempty"`
+	DryRun                bool                   `json:"dry_run,omitempty"`
+	Condition             string                 `json:"condition,omitempty"`
+	MutableContent        bool                   `json:"mutable_content,omitempty"`
+}
+
+type NotificationPayload struct {
+	Title            string `json:"title,omitempty"`
+	Body             string `json:"body,omitempty"`
+	Icon             string `json:"icon,omitempty"`
+	Image            string `json:"image,omitempty"`
+	Sound            string `json:"sound,omitempty"`
+	Badge            string `json:"badge,omitempty"`
+	Tag              string `json:"tag,omitempty"`
+	Color            string `json:"color,omitempty"`
+	ClickAction      string `json:"click_action,omitempty"`
+	BodyLocKey       string `json:"body_loc_key,omitempty"`
+	BodyLocArgs      string `json:"body_loc_args,omitempty"`
+	TitleLocKey      string `json:"title_loc_key,omitempty"`
+	TitleLocArgs     string `json:"title_loc_args,omitempty"`
+	AndroidChannelID string `json:"android_channel_id,omitempty"`
+}
+
+type FcmPushRes struct {
+	Ok           bool
+	StatusCode   int
+	MulticastId  int64               `json:"multicast_id"`
+	Success      int                 `json:"success"`
+	Fail         int                 `json:"failure"`
+	CanonicalIds int                 `json:"canonical_ids"`
+	Results      []map[string]string `json:"results,omitempty"`
+	MessageId    int64               `json:"message_id,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	RetryAfter   string
+}
+
+func (s *PushSender) send(ctx context.Context, apiKey string, pushMsg *model.Push, registrationIds []string) (*FcmPushRes, string, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.FCMPush")
+	defer span.End()
+
+	client := s.client
+
+	httpReqBody, err := buildPushReqBody(pushMsg, registrationIds)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, pushMsg, err, "Create request body")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, s.cfg.Url, bytes.NewBuffer(httpReqBody))
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpReqBody), err, "Create new request")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	httpReq.Header.Set("Authorization", fmt.Sprintf("key=%v", apiKey))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpReqBody), err, "Get http result")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpReqBody), err, "Get result body")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+
+	fcmPushRes := new(FcmPushRes)
+	fcmPushRes.StatusCode = httpRes.StatusCode
+	fcmPushRes.RetryAfter = httpRes.Header.Get("Retry-After")
+
+	if fcmPushRes.StatusCode != http.StatusOK {
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", fcmPushRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	if err := json.Unmarshal(httpResBody, &fcmPushRes); err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpResBody), err, "Unmarshal response body")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	s.deps.LogRestMessage(ctx, s.cfg.Url, constants.METHOD_POST, string(httpResBody), nil, "Success to send sms request")
+
+	// message_id is only set for topic sends, multicast answers carry
+	// multicast_id instead.
+	msgId := fmt.Sprint(fcmPushRes.MessageId)
+	if fcmPushRes.MessageId == 0 {
+		msgId = fmt.Sprint(fcmPushRes.MulticastId)
+	}
+
+	return fcmPushRes, msgId, nil
+}
+
+func buildPushReqBody(pushMsg *model.Push, registrationIds []string) ([]byte, error) {
+	body := &FcmPushReq{
+		Data:             pushMsg.Data,
+		RegistrationIds:  registrationIds,
+		Priority:         Priority_NORMAL,
+		ContentAvailable: true,
+		TimeToLive:       MAX_TTL,
+		Notification: NotificationPayload{
+			Title: pushMsg.Heading,
+			Body:  pushMsg.Content,
+			Image: pushMsg.PictureUrl,
+		},
+	}
+
+	return json.Marshal(body)
+}
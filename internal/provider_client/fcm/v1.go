@@ -0,0 +1,307 @@
+package fcm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const scopeMessaging = "https://www.googleapis.com/auth/firebase.messaging"
+
+type serviceAccount struct {
+	ProjectId   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenUri    string `json:"token_uri"`
+}
+
+// v1App is an app sent through the HTTP v1 API, authenticated with a service
+// account access token that oauth2 caches until expiry.
+type v1App struct {
+	projectId string
+	tokens    oauth2.TokenSource
+}
+
+func newV1App(client *http.Client, app *config.FcmApp) (*v1App, error) {
+	b, err := os.ReadFile(app.ServiceAccountFile)
+	if err != nil {
+		return nil, fmt.Errorf("read service account: %w", err)
+	}
+
+	account := &serviceAccount{}
+	if err := json.Unmarshal(b, account); err != nil {
+		return nil, fmt.Errorf("parse service account: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("service account has no client_email or private_key")
+	}
+
+	projectId := app.ProjectId
+	if projectId == "" {
+		projectId = account.ProjectId
+	}
+	if projectId == "" {
+		return nil, errors.New("project id is not configured")
+	}
+
+	tokenUri := account.TokenUri
+	if tokenUri == "" {
+		tokenUri = "https://oauth2.googleapis.com/token"
+	}
+
+	jwtCfg := &jwt.Config{
+		Email:      account.ClientEmail,
+		PrivateKey: []byte(account.PrivateKey),
+		Scopes:     []string{scopeMessaging},
+		TokenURL:   tokenUri,
+	}
+
+	return &v1App{
+		projectId: projectId,
+		tokens:    jwtCfg.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client)),
+	}, nil
+}
+
+type v1Req struct {
+	Message v1Message `json:"message"`
+}
+
+type v1Message struct {
+	Token        string            `json:"token"`
+	Data         map[string]string `json:"data,omitempty"`
+	Notification *v1Notification   `json:"notification,omitempty"`
+	Android      *v1Android        `json:"android,omitempty"`
+}
+
+type v1Notification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+type v1Android struct {
+	Priority string `json:"priority,omitempty"`
+	Ttl      string `json:"ttl,omitempty"`
+}
+
+type v1Res struct {
+	Name string `json:"name"`
+}
+
+type v1ErrorRes struct {
+	Error struct {
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// v1Error is a rejected v1 request, ErrorCode is the FcmError detail such as
+// UNREGISTERED or QUOTA_EXCEEDED when present.
+type v1Error struct {
+	StatusCode int
+	ErrorCode  string
+	Body       string
+}
+
+func (e *v1Error) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *v1Error) unregistered() bool {
+	return e.ErrorCode == "UNREGISTERED"
+}
+
+// sendV1 sends one request per registration token since the v1 API has no
+// multicast. The push succeeds if at least one token accepted it, the tokens
+// that failed with a retryable error are then returned in a PartialFailure.
+func (s *PushSender) sendV1(ctx context.Context, app *v1App, pushMsg *model.Push) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.FCMPushV1")
+	defer span.End()
+
+	if len(pushMsg.PlayerIds) == 0 {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: errors.New("push has no registration token")})
+	}
+
+	data, err := stringifyData(pushMsg.Data)
+	if err != nil {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: err})
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/messages:send", s.cfg.V1Url, app.projectId)
+
+	result := &providerClient.DeliveryResult{
+		Provider:          constants.PROVIDER_FCM,
+		ResultCode:        strconv.Itoa(http.StatusOK),
+		InvalidRecipients: make(map[string]string),
+	}
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		errs        []error
+		retryTokens []string
+		retryErrs   []error
+		semaphore   = make(chan struct{}, s.concurrency)
+	)
+
+	for _, token := range pushMsg.PlayerIds {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(token string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			name, err := s.sendV1Token(ctx, app, url, &v1Message{
+				Token: token,
+				Data:  data,
+				Notification: &v1Notification{
+					Title: pushMsg.Heading,
+					Body:  pushMsg.Content,
+					Image: pushMsg.PictureUrl,
+				},
+				Android: &v1Android{
+					Priority: Priority_NORMAL,
+					Ttl:      strconv.Itoa(MAX_TTL) + "s",
+				},
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var v1Err *v1Error
+			switch {
+			case err == nil:
+				result.Succeeded++
+				if result.MessageID == "" {
+					result.MessageID = name
+				}
+			case errors.As(err, &v1Err) && v1Err.unregistered():
+				result.InvalidRecipients[token] = v1Err.ErrorCode
+				errs = append(errs, err)
+			case providerClient.IsRetryable(err):
+				result.Failed++
+				errs = append(errs, err)
+				retryTokens = append(retryTokens, token)
+				retryErrs = append(retryErrs, err)
+			default:
+				result.Failed++
+				errs = append(errs, err)
+			}
+		}(token)
+	}
+	wg.Wait()
+
+	result.Message = fmt.Sprintf("%d accepted, %d rejected", result.Succeeded, len(errs))
+
+	if result.Succeeded == 0 {
+		return result, tracerClient.RecordError(span, errors.Join(errs...))
+	}
+
+	for _, err := range errs {
+		s.deps.LogRestMessage(ctx, url, constants.METHOD_POST, pushMsg, err, "Registration token rejected")
+	}
+
+	if len(retryTokens) > 0 {
+		return result, tracerClient.RecordError(span, providerClient.NewPartialFailure(retryTokens, retryErrs))
+	}
+
+	return result, nil
+}
+
+func (s *PushSender) sendV1Token(ctx context.Context, app *v1App, url string, message *v1Message) (string, error) {
+	token, err := app.tokens.Token()
+	if err != nil {
+		return "", &providerClient.RetryableError{Err: fmt.Errorf("fcm access token: %w", err)}
+	}
+
+	httpReqBody, err := json.Marshal(&v1Req{Message: *message})
+	if err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, url, bytes.NewReader(httpReqBody))
+	if err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(httpReq)
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		return "", &providerClient.RetryableError{Err: err}
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, _ := io.ReadAll(io.LimitReader(httpRes.Body, 4096))
+
+	if httpRes.StatusCode != http.StatusOK {
+		err := &v1Error{StatusCode: httpRes.StatusCode, Body: string(httpResBody)}
+
+		errorRes := &v1ErrorRes{}
+		if json.Unmarshal(httpResBody, errorRes) == nil {
+			err.ErrorCode = errorRes.Error.Status
+			for _, detail := range errorRes.Error.Details {
+				if detail.ErrorCode != "" {
+					err.ErrorCode = detail.ErrorCode
+				}
+			}
+		}
+
+		switch {
+		case httpRes.StatusCode == http.StatusTooManyRequests || httpRes.StatusCode == http.StatusServiceUnavailable:
+			return "", providerClient.RateLimited(httpRes, err)
+		case httpRes.StatusCode >= http.StatusInternalServerError:
+			return "", &providerClient.RetryableError{Err: err}
+		}
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	res := &v1Res{}
+	if err := json.Unmarshal(httpResBody, res); err != nil {
+		return "", err
+	}
+
+	return res.Name, nil
+}
+
+// The v1 API only takes string data values, anything else is sent as JSON.
+func stringifyData(data map[string]interface{}) (map[string]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	out := make(map[string]string, len(data))
+	for key, value := range data {
+		if str, ok := value.(string); ok {
+			out[key] = str
+			continue
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("data %s: %w", key, err)
+		}
+		out[key] = string(b)
+	}
+
+	return out, nil
+}
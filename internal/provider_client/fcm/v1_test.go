@@ -0,0 +1,132 @@
+package fcm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+)
+
+// newV1Stub answers the v1 send requests per registration token: "ok" is
+// accepted, "throttled" gets a 429 and "gone" is unregistered.
+func newV1Stub(t *testing.T) *PushSender {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/proj/messages:send" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer access" {
+			t.Errorf("authorization = %q", got)
+		}
+
+		req := &v1Req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("bad request body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Message.Token {
+		case "ok":
+			w.Write([]byte(`{"name":"projects/proj/messages/1"}`))
+		case "throttled":
+			w.Header().Set("Retry-After", "9")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"status":"RESOURCE_EXHAUSTED","details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`))
+		case "gone":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"status":"INVALID_ARGUMENT"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	deps := providerClient.NewDeps(
+		logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		&config.Config{Project: &config.Project{ServiceName: "dispatch-service", Priority: "reg"}},
+		trace.NewNoopTracerProvider().Tracer("test"),
+	)
+
+	cfg := &config.FcmPushProvider{
+		V1Url:       server.URL,
+		ChunkSize:   "500",
+		Concurrency: "2",
+		Apps:        map[string]*config.FcmApp{"jmo": {ProjectId: "proj"}},
+	}
+
+	sender, err := NewSender(deps, cfg, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender.v1["jmo"] = &v1App{
+		projectId: "proj",
+		tokens:    oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "access", TokenType: "Bearer"}),
+	}
+
+	return sender
+}
+
+func TestSendV1RetriesThrottledTokens(t *testing.T) {
+	sender := newV1Stub(t)
+
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{
+		PlayerIds: []string{"ok", "throttled", "gone"},
+		Heading:   "hi",
+		Content:   "there",
+	})
+
+	if result == nil || result.Succeeded != 1 || result.Failed != 1 {
+		t.Fatalf("result = %+v, want 1 succeeded and 1 failed", result)
+	}
+	if result.MessageID != "projects/proj/messages/1" {
+		t.Errorf("message id = %q", result.MessageID)
+	}
+	if !reflect.DeepEqual(result.InvalidRecipients, map[string]string{"gone": "UNREGISTERED"}) {
+		t.Errorf("invalid recipients = %v", result.InvalidRecipients)
+	}
+
+	recipients, ok := providerClient.FailedRecipients(err)
+	if !ok || !reflect.DeepEqual(recipients, []string{"throttled"}) {
+		t.Fatalf("err = %v, want a partial failure of the throttled token", err)
+	}
+	if after, ok := providerClient.RetryAfter(err); !ok || after != 9*time.Second {
+		t.Errorf("retry after = %s, want 9s", after)
+	}
+}
+
+func TestSendV1PermanentOnly(t *testing.T) {
+	sender := newV1Stub(t)
+
+	result, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"ok", "bad"}})
+	if err != nil {
+		t.Fatalf("err = %v, want none for a permanent rejection next to an accepted token", err)
+	}
+	if result.Succeeded != 1 || result.Failed != 1 {
+		t.Errorf("result = %+v, want 1 succeeded and 1 failed", result)
+	}
+}
+
+func TestSendV1AllThrottled(t *testing.T) {
+	sender := newV1Stub(t)
+
+	_, err := sender.SendPush(context.Background(), "jmo", &model.Push{PlayerIds: []string{"throttled"}})
+	if !providerClient.IsRetryable(err) {
+		t.Fatalf("err = %v, want retryable", err)
+	}
+	if _, ok := providerClient.FailedRecipients(err); ok {
+		t.Errorf("err = %v, want a full failure rather than a partial one", err)
+	}
+}
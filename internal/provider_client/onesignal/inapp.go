@@ -1,4 +1,4 @@
-package provider_client
+package onesignal
 
 import (
 	"bytes"
@@ -6,10 +6,36 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
 	"github.com/pkg/errors"
 )
 
+func init() {
+	providerClient.RegisterInAppSender(constants.PROVIDER_ONESIGNAL, NewInAppSender)
+}
+
+type InAppSender struct {
+	deps *providerClient.Deps
+}
+
+func NewInAppSender(deps *providerClient.Deps) (providerClient.InAppSender, error) {
+	if deps.Cfg.ProviderClient.OneSignalPushProvider.InAppUrl == "" {
+		return nil, errors.New("onesignal inapp url is not configured")
+	}
+
+	return &InAppSender{
+		deps: deps,
+	}, nil
+}
+
+func (s *InAppSender) Name() string {
+	return constants.PROVIDER_ONESIGNAL
+}
+
 type SendInAppReq struct {
 	InAppMessage InAppMessage `json:"in_app_message"`
 	IsDraft      bool         `json:"is_draft"`
@@ -103,53 +129,54 @@ type Payload struct {
 	Success bool `json:"success"`
 }
 
-func (pc *ProviderClient) SendInApp(ctx context.Context, url string) (string, error) {
-	_, span := pc.tracer.Start(ctx, "ProviderClient.SendInApp")
+func (s *InAppSender) SendInApp(ctx context.Context, inappMsg *model.InApp) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendInApp")
 	defer span.End()
 
-	client := pc.NewHttpClient()
+	client := s.deps.NewHttpClient()
 
-	req := getInAppRequest()
+	req := getInAppRequest(inappMsg)
 	httpReqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", errors.Wrap(err, "json.Marshal")
+		return nil, tracerClient.RecordError(span, errors.Wrap(err, "json.Marshal"))
 	}
 
-	httpReq, err := http.NewRequest(constants.METHOD_POST, url, bytes.NewBuffer(httpReqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, s.deps.Cfg.ProviderClient.OneSignalPushProvider.InAppUrl, bytes.NewBuffer(httpReqBody))
 	if err != nil {
-		return "", errors.Wrap(err, "http.NewRequest")
+		return nil, tracerClient.RecordError(span, errors.Wrap(err, "http.NewRequest"))
 	}
 
 	httpReq.Header.Add("Content-Type", "application/json")
 
 	httpRes, err := client.Do(httpReq)
 	if err != nil {
-		return "", errors.Wrap(err, "client.Do")
+		return nil, tracerClient.RecordError(span, errors.Wrap(err, "client.Do"))
 	}
 	defer httpRes.Body.Close()
 
 	if httpRes.StatusCode != http.StatusOK {
-		return "", errors.New("request failed")
+		return nil, tracerClient.RecordError(span, providerClient.RateLimited(httpRes, errors.New("request failed")))
 	}
 
 	var httpResData SendInAppRes
 	err = json.NewDecoder(httpRes.Body).Decode(&httpResData)
 	if err != nil {
-		return "", errors.Wrap(err, "jsonDecoder.Decode")
+		return nil, tracerClient.RecordError(span, errors.Wrap(err, "jsonDecoder.Decode"))
 	}
 
-	result := "failed"
-	if httpResData.Payload.Success {
-		result = "success"
+	if !httpResData.Payload.Success {
+		return nil, tracerClient.RecordError(span, errors.New("inapp message was not accepted"))
 	}
 
-	return result, nil
+	return &providerClient.DeliveryResult{
+		Provider: constants.PROVIDER_ONESIGNAL,
+	}, nil
 }
 
-func getInAppRequest() *SendInAppReq {
+func getInAppRequest(inappMsg *model.InApp) *SendInAppReq {
 	return &SendInAppReq{
 		InAppMessage: InAppMessage{
-			Name:         "Test",
+			Name:         inappMsg.Heading,
 			Location:     "center_modal",
 			RemoveMargin: false,
 			Contents: Contents{
@@ -173,7 +200,7 @@ func getInAppRequest() *SendInAppReq {
 									Weight:     "",
 								},
 								Id:   "e5198a3f-ca91-4d1b-a9ef-51466880279a",
-								Text: "Heading",
+								Text: inappMsg.Heading,
 								Type: "title",
 								Margin: Margin{
 									Top:    0,
@@ -196,7 +223,7 @@ func getInAppRequest() *SendInAppReq {
 									Bottom: 0,
 									Left:   0,
 								},
-								Url: "https://media.onesignal.com/iam/default_image_20200320.png",
+								Url: inappMsg.PictureUrl,
 							},
 							{
 								Action: Action{
@@ -252,6 +279,7 @@ func getInAppRequest() *SendInAppReq {
 							},
 							{
 								Type: "body",
+								Text: inappMsg.Content,
 								Action: Action{
 									Close:     false,
 									Prompts:   []string{},
@@ -267,7 +295,7 @@ func getInAppRequest() *SendInAppReq {
 			RedisplayDelay:    "",
 			IsTemplate:        false,
 			DisplayDuration:   0,
-			IncludeSegmentIds: []string{},
+			IncludeSegmentIds: inappMsg.Segments,
 			ExcludeSegmentIds: []string{},
 			Triggers:          []string{},
 			StartTime:         "2023-07-17T03:38:15.197Z",
@@ -0,0 +1,152 @@
+package onesignal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	onesignalApi "github.com/OneSignal/onesignal-go-api"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const reasonInvalidPlayerId = "invalid_player_id"
+
+func init() {
+	providerClient.RegisterPushSender(constants.PROVIDER_ONESIGNAL, NewPushSender)
+}
+
+type PushSender struct {
+	deps        *providerClient.Deps
+	client      *onesignalApi.APIClient
+	chunkSize   int
+	concurrency int
+}
+
+func NewPushSender(deps *providerClient.Deps) (providerClient.PushSender, error) {
+	cfg := deps.Cfg.ProviderClient.OneSignalPushProvider
+
+	chunkSize, err := strconv.Atoi(cfg.ChunkSize)
+	if err != nil || chunkSize < 1 || chunkSize > 2000 {
+		return nil, fmt.Errorf("invalid onesignal chunk size %q, must be 1 to 2000", cfg.ChunkSize)
+	}
+
+	concurrency, err := strconv.Atoi(cfg.Concurrency)
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("invalid onesignal concurrency %q", cfg.Concurrency)
+	}
+
+	return &PushSender{
+		deps:        deps,
+		client:      newApiClient(deps),
+		chunkSize:   chunkSize,
+		concurrency: concurrency,
+	}, nil
+}
+
+func (s *PushSender) Name() string {
+	return constants.PROVIDER_ONESIGNAL
+}
+
+func (s *PushSender) SendPush(ctx context.Context, channel string, pushMsg *model.Push) (*providerClient.DeliveryResult, error) {
+	app, ok := s.deps.Cfg.ProviderClient.OneSignalPushProvider.Apps[strings.ToLower(channel)]
+	if !ok {
+		return nil, providerClient.NoPushAppError(constants.PROVIDER_ONESIGNAL, channel)
+	}
+
+	url := s.deps.Cfg.ProviderClient.OneSignalPushProvider.Url
+
+	return s.deps.SendChunks(ctx, url, pushMsg.PlayerIds, s.chunkSize, s.concurrency, func(ctx context.Context, playerIds []string) (*providerClient.DeliveryResult, error) {
+		notifSuccesRes, msgId, err := s.send(ctx, app, pushMsg, playerIds)
+		if err != nil {
+			return nil, err
+		}
+
+		return getDeliveryResult(notifSuccesRes, msgId, playerIds), nil
+	})
+}
+
+// getDeliveryResult counts a chunk from the recipients OneSignal reports and
+// the player ids it rejected, the remaining ids are not subscribed.
+func getDeliveryResult(notifSuccesRes *onesignalApi.CreateNotificationSuccessResponse, msgId string, playerIds []string) *providerClient.DeliveryResult {
+	result := &providerClient.DeliveryResult{
+		Provider:          constants.PROVIDER_ONESIGNAL,
+		MessageID:         msgId,
+		ResultCode:        strconv.Itoa(http.StatusOK),
+		InvalidRecipients: make(map[string]string),
+	}
+
+	if errs := notifSuccesRes.Errors; errs != nil && errs.InvalidIdentifierError != nil {
+		for _, playerId := range errs.InvalidIdentifierError.InvalidPlayerIds {
+			result.InvalidRecipients[playerId] = reasonInvalidPlayerId
+		}
+	}
+
+	valid := len(playerIds) - len(result.InvalidRecipients)
+	result.Succeeded = int(notifSuccesRes.Recipients)
+	if result.Succeeded > valid {
+		result.Succeeded = valid
+	}
+	result.Failed = valid - result.Succeeded
+
+	return result
+}
+
+func newApiClient(deps *providerClient.Deps) *onesignalApi.APIClient {
+	onesignalCfg := onesignalApi.NewConfiguration()
+	onesignalCfg.HTTPClient = &http.Client{
+		Transport: tracerClient.NewTransport(deps.Tracer, http.DefaultTransport),
+	}
+
+	return onesignalApi.NewAPIClient(onesignalCfg)
+}
+
+func (s *PushSender) send(ctx context.Context, app *config.OneSignalApp, pushMsg *model.Push, playerIds []string) (*onesignalApi.CreateNotificationSuccessResponse, string, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.OneSignalPush")
+	defer span.End()
+
+	notification := *onesignalApi.NewNotification(app.AppId)
+	notification.SetIncludePlayerIds(playerIds)
+	notification.SetHeadings(onesignalApi.StringMap{En: &pushMsg.Heading})
+	notification.SetContents(onesignalApi.StringMap{En: &pushMsg.Content})
+	notification.SetBigPicture(pushMsg.PictureUrl)
+	notification.SetIsIos(pushMsg.IsIos)
+
+	appAuth := context.WithValue(ctx, onesignalApi.AppAuth, app.ApiKey)
+
+	notifSuccesRes, httpRes, err := s.client.DefaultApi.CreateNotification(appAuth).Notification(notification).Execute()
+	if httpRes != nil {
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+	}
+	if err != nil {
+		err = providerClient.RateLimited(httpRes, err)
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, pushMsg, err, "Error to send push notification")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	httpResBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, "", err, "Get result body")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	if httpRes.StatusCode != http.StatusOK {
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return nil, "", tracerClient.RecordError(span, err)
+	}
+
+	span.SetAttributes(tracerClient.ProviderMessageIDKey.String(notifSuccesRes.Id))
+
+	s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, string(httpResBody), nil, "Success to send sms request")
+
+	return notifSuccesRes, notifSuccesRes.Id, nil
+}
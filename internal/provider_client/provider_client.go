@@ -10,44 +10,93 @@ import (
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
 
-	"github.com/OneSignal/onesignal-go-api"
 	"go.opentelemetry.io/otel/trace"
 )
 
-type IProviderClient interface {
-	SendEmail(ctx context.Context, replyCfg string, email *model.Email) (string, string, error)
-	SendSms(ctx context.Context, sms *model.Sms) (string, string, error)
-	FcmPush(ctx context.Context, pushMsg *model.Push) (*FcmPushRes, string, error)
-	OneSignalPush(ctx context.Context, appId string, pushMsg *model.Push) (*onesignal.CreateNotificationSuccessResponse, string, error)
+type DeliveryResult struct {
+	Provider   string
+	MessageID  string
+	ResultCode string
+	Message    string
+	Segments   int
+	// Succeeded and Failed count the recipients of a multicast, Failed
+	// excludes the recipients listed in InvalidRecipients.
+	Succeeded int
+	Failed    int
+	// InvalidRecipients lists recipients the provider reported as gone, keyed
+	// by recipient with the provider reason as value.
+	InvalidRecipients map[string]string
+	// CanonicalRecipients maps recipients that were delivered under an
+	// outdated id to the id the provider wants used from now on.
+	CanonicalRecipients map[string]string
 }
 
-type ProviderClient struct {
-	logger          *logger.AppLogger
-	cfg             *config.Config
-	tracer          trace.Tracer
-	onesignalClient *onesignal.APIClient
+type Sender interface {
+	Name() string
 }
 
-func NewProviderClient(logger *logger.AppLogger, cfg *config.Config, tracer trace.Tracer) *ProviderClient {
-	return &ProviderClient{
-		logger:          logger,
-		cfg:             cfg,
-		tracer:          tracer,
-		onesignalClient: onesignal.NewAPIClient(onesignal.NewConfiguration()),
+type EmailSender interface {
+	Sender
+	SendEmail(ctx context.Context, email *model.Email) (*DeliveryResult, error)
+}
+
+type SmsSender interface {
+	Sender
+	SendSms(ctx context.Context, sms *model.Sms) (*DeliveryResult, error)
+}
+
+type PushSender interface {
+	Sender
+	// SendPush delivers with the app configured for channel.
+	SendPush(ctx context.Context, channel string, push *model.Push) (*DeliveryResult, error)
+}
+
+type InAppSender interface {
+	Sender
+	SendInApp(ctx context.Context, inapp *model.InApp) (*DeliveryResult, error)
+}
+
+type WhatsAppSender interface {
+	Sender
+	SendWhatsApp(ctx context.Context, whatsapp *model.WhatsApp) (*DeliveryResult, error)
+}
+
+type WebhookSender interface {
+	Sender
+	SendWebhook(ctx context.Context, channel string, webhook *model.Webhook) (*DeliveryResult, error)
+}
+
+type WebPushSender interface {
+	Sender
+	SendWebPush(ctx context.Context, webpush *model.WebPush) (*DeliveryResult, error)
+}
+
+type Deps struct {
+	Logger *logger.AppLogger
+	Cfg    *config.Config
+	Tracer trace.Tracer
+}
+
+func NewDeps(logger *logger.AppLogger, cfg *config.Config, tracer trace.Tracer) *Deps {
+	return &Deps{
+		Logger: logger,
+		Cfg:    cfg,
+		Tracer: tracer,
 	}
 }
 
-func (pc *ProviderClient) NewHttpClient() *http.Client {
+func (d *Deps) NewHttpClient() *http.Client {
 	return &http.Client{
 		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
+		Transport: tracerClient.NewTransport(d.Tracer, &http.Transport{
 			Dial: (&net.Dialer{
 				Timeout: 10 * time.Second,
 			}).Dial,
 			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
 			TLSHandshakeTimeout: 10 * time.Second,
 			IdleConnTimeout:     30 * time.Second,
-		},
+		}),
 	}
 }
@@ -0,0 +1,119 @@
+package provider_client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+)
+
+// ErrForbiddenAddress rejects a destination taken from a message that
+// resolves inside our network, such as a webhook url pointing at an internal
+// service or the cloud metadata endpoint.
+var ErrForbiddenAddress = errors.New("destination address is not allowed")
+
+var forbiddenNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"198.18.0.0/15",
+	"64:ff9b::/96",
+)
+
+var metadataAddresses = []net.IP{
+	net.ParseIP("169.254.169.254"),
+	net.ParseIP("fd00:ec2::254"),
+}
+
+// IsPublicAddress reports whether ip may be dialed for a destination taken
+// from a message.
+func IsPublicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, metadata := range metadataAddresses {
+		if ip.Equal(metadata) {
+			return false
+		}
+	}
+
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckPublicHost rejects a host given as a literal IP that is not public so
+// the message fails before dialing. Hostnames are checked once resolved, by
+// the dialer of NewPublicHttpClient.
+func CheckPublicHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil && !IsPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// RequestError classifies an error returned by Client.Do, a forbidden
+// destination will stay forbidden while anything else may be transient.
+func RequestError(err error) error {
+	if errors.Is(err, ErrForbiddenAddress) {
+		return &PermanentError{Err: err}
+	}
+	return &RetryableError{Err: err}
+}
+
+// NewPublicHttpClient returns a client for urls supplied by producers. It
+// verifies certificates, does not follow redirects and refuses to connect to
+// addresses that are not public after DNS resolution.
+func (d *Deps) NewPublicHttpClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: tracerClient.NewTransport(d.Tracer, &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: 10 * time.Second,
+				Control: denyForbiddenAddress,
+			}).DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     30 * time.Second,
+		}),
+	}
+}
+
+// denyForbiddenAddress runs for every address the dialer tries, after the
+// hostname was resolved, so a name cannot be pointed at an internal address.
+func denyForbiddenAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,84 @@
+package provider_client
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "8.8.8.8", want: true},
+		{ip: "2001:4860:4860::8888", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.1.2.3", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "169.254.10.1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "::", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "fc00::1", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "::ffff:127.0.0.1", want: false},
+		{ip: "::ffff:10.0.0.1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicAddress(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestPublicHttpClientRefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	deps := &Deps{Tracer: trace.NewNoopTracerProvider().Tracer("test")}
+
+	_, err := deps.NewPublicHttpClient().Get(server.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("err = %v, want ErrForbiddenAddress", err)
+	}
+	if !IsPermanent(RequestError(err)) {
+		t.Fatalf("RequestError(%v) is not permanent", err)
+	}
+
+	// The literal check fails the same address before dialing.
+	if err := CheckPublicHost("127.0.0.1"); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("CheckPublicHost err = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestPublicHttpClientDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := (&Deps{Tracer: trace.NewNoopTracerProvider().Tracer("test")}).NewPublicHttpClient()
+	// Reach the stub over loopback, only the redirect policy is under test.
+	client.Transport = http.DefaultTransport
+
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusFound)
+	}
+}
@@ -0,0 +1,252 @@
+package provider_client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ProviderNone = "none"
+)
+
+type EmailSenderFactory func(deps *Deps) (EmailSender, error)
+type SmsSenderFactory func(deps *Deps) (SmsSender, error)
+type PushSenderFactory func(deps *Deps) (PushSender, error)
+type InAppSenderFactory func(deps *Deps) (InAppSender, error)
+type WhatsAppSenderFactory func(deps *Deps) (WhatsAppSender, error)
+type WebhookSenderFactory func(deps *Deps) (WebhookSender, error)
+type WebPushSenderFactory func(deps *Deps) (WebPushSender, error)
+
+var registry = struct {
+	mu       sync.RWMutex
+	email    map[string]EmailSenderFactory
+	sms      map[string]SmsSenderFactory
+	push     map[string]PushSenderFactory
+	inapp    map[string]InAppSenderFactory
+	whatsapp map[string]WhatsAppSenderFactory
+	webhook  map[string]WebhookSenderFactory
+	webpush  map[string]WebPushSenderFactory
+}{
+	email:    make(map[string]EmailSenderFactory),
+	sms:      make(map[string]SmsSenderFactory),
+	push:     make(map[string]PushSenderFactory),
+	inapp:    make(map[string]InAppSenderFactory),
+	whatsapp: make(map[string]WhatsAppSenderFactory),
+	webhook:  make(map[string]WebhookSenderFactory),
+	webpush:  make(map[string]WebPushSenderFactory),
+}
+
+// Provider packages register their factories from init, so a new provider
+// only needs to be imported by the server to become selectable in config.
+func RegisterEmailSender(name string, factory EmailSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.email[name] = factory
+}
+
+func RegisterSmsSender(name string, factory SmsSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.sms[name] = factory
+}
+
+func RegisterPushSender(name string, factory PushSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.push[name] = factory
+}
+
+func RegisterInAppSender(name string, factory InAppSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.inapp[name] = factory
+}
+
+func RegisterWhatsAppSender(name string, factory WhatsAppSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.whatsapp[name] = factory
+}
+
+func RegisterWebhookSender(name string, factory WebhookSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.webhook[name] = factory
+}
+
+func RegisterWebPushSender(name string, factory WebPushSenderFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.webpush[name] = factory
+}
+
+type Senders struct {
+	Email    EmailSender
+	Sms      SmsSender
+	Push     PushSender
+	PushIos  PushSender
+	InApp    InAppSender
+	WhatsApp WhatsAppSender
+	Webhook  WebhookSender
+	WebPush  WebPushSender
+}
+
+// NewSenders builds the senders selected in config for the channel and
+// priority this process is handling. A category without a selection is left
+// nil so the usecase can reject its messages.
+func NewSenders(deps *Deps, channel string, priority string) (*Senders, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	selection := deps.Cfg.ProviderSelection
+	senders := &Senders{}
+
+	if name := ResolveProviderName(selection.Email, channel, priority); name != "" {
+		factory, ok := registry.email[name]
+		if !ok {
+			return nil, unknownProviderError("email", name, registry.email)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create email provider %s: %w", name, err)
+		}
+		senders.Email = sender
+	}
+
+	if name := ResolveProviderName(selection.Sms, channel, priority); name != "" {
+		factory, ok := registry.sms[name]
+		if !ok {
+			return nil, unknownProviderError("sms", name, registry.sms)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sms provider %s: %w", name, err)
+		}
+		senders.Sms = sender
+	}
+
+	if name := ResolveProviderName(selection.Push, channel, priority); name != "" {
+		factory, ok := registry.push[name]
+		if !ok {
+			return nil, unknownProviderError("push", name, registry.push)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create push provider %s: %w", name, err)
+		}
+		senders.Push = sender
+	}
+
+	if name := ResolveProviderName(selection.PushIos, channel, priority); name != "" {
+		factory, ok := registry.push[name]
+		if !ok {
+			return nil, unknownProviderError("push", name, registry.push)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ios push provider %s: %w", name, err)
+		}
+		senders.PushIos = sender
+	}
+
+	if name := ResolveProviderName(selection.InApp, channel, priority); name != "" {
+		factory, ok := registry.inapp[name]
+		if !ok {
+			return nil, unknownProviderError("inapp", name, registry.inapp)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create inapp provider %s: %w", name, err)
+		}
+		senders.InApp = sender
+	}
+
+	if name := ResolveProviderName(selection.WhatsApp, channel, priority); name != "" {
+		factory, ok := registry.whatsapp[name]
+		if !ok {
+			return nil, unknownProviderError("whatsapp", name, registry.whatsapp)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create whatsapp provider %s: %w", name, err)
+		}
+		senders.WhatsApp = sender
+	}
+
+	if name := ResolveProviderName(selection.Webhook, channel, priority); name != "" {
+		factory, ok := registry.webhook[name]
+		if !ok {
+			return nil, unknownProviderError("webhook", name, registry.webhook)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook provider %s: %w", name, err)
+		}
+		senders.Webhook = sender
+	}
+
+	if name := ResolveProviderName(selection.WebPush, channel, priority); name != "" {
+		factory, ok := registry.webpush[name]
+		if !ok {
+			return nil, unknownProviderError("webpush", name, registry.webpush)
+		}
+		sender, err := factory(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webpush provider %s: %w", name, err)
+		}
+		senders.WebPush = sender
+	}
+
+	return senders, nil
+}
+
+// ResolveProviderName picks a provider from a comma separated selection such
+// as "fcm,high=onesignal,sipp.high=fcm". The most specific key wins:
+// channel.priority, then channel, then priority, then the bare default.
+func ResolveProviderName(selection string, channel string, priority string) string {
+	byKey := make(map[string]string)
+	defaultName := ""
+
+	for _, entry := range strings.Split(selection, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, name, found := strings.Cut(entry, "=")
+		if !found {
+			defaultName = strings.ToLower(entry)
+			continue
+		}
+		byKey[strings.ToLower(strings.TrimSpace(key))] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	channel = strings.ToLower(channel)
+	priority = strings.ToLower(priority)
+
+	name := defaultName
+	for _, key := range []string{channel + "." + priority, channel, priority} {
+		if n, ok := byKey[key]; ok {
+			name = n
+			break
+		}
+	}
+
+	if name == ProviderNone {
+		return ""
+	}
+
+	return name
+}
+
+func unknownProviderError[T any](category string, name string, factories map[string]T) error {
+	names := make([]string, 0, len(factories))
+	for n := range factories {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("unknown %s provider %q, available: %s", category, name, strings.Join(names, ","))
+}
@@ -0,0 +1,110 @@
+package restsms
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupPath resolves a small JSONPath subset against a decoded document:
+// "$.data.messages[0].id", "data.id" and "$['message-id']" are supported.
+// An empty path resolves to nothing.
+func lookupPath(doc interface{}, path string) (interface{}, bool, error) {
+	if path == "" {
+		return nil, false, nil
+	}
+
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, false, nil
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, false, fmt.Errorf("path %s: %q is not an array index", path, token)
+			}
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, false, nil
+			}
+			current = node[index]
+		default:
+			return nil, false, nil
+		}
+	}
+
+	return current, true, nil
+}
+
+func parsePath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+
+	tokens := make([]string, 0)
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in path")
+			}
+			token := strings.Trim(path[1:end], `'"`)
+			tokens = append(tokens, token)
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			tokens = append(tokens, path[:end])
+			path = path[end:]
+		}
+	}
+
+	return tokens, nil
+}
+
+func lookupString(doc interface{}, path string) (string, error) {
+	value, found, err := lookupPath(doc, path)
+	if err != nil || !found || value == nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+func lookupInt(doc interface{}, path string) (int, error) {
+	s, err := lookupString(doc, path)
+	if err != nil || s == "" {
+		return 0, err
+	}
+
+	return strconv.Atoi(s)
+}
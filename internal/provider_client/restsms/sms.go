@@ -0,0 +1,206 @@
+package restsms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthApiKey = "api-key"
+)
+
+func init() {
+	providerClient.RegisterSmsSender(constants.PROVIDER_RESTSMS, NewSmsSender)
+}
+
+type SmsSender struct {
+	deps   *providerClient.Deps
+	cfg    *config.RestSmsProvider
+	client *http.Client
+	body   *template.Template
+}
+
+type bodyTemplateData struct {
+	Msisdn   string
+	Text     string
+	SenderId string
+}
+
+func NewSmsSender(deps *providerClient.Deps) (providerClient.SmsSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.RestSmsProvider, deps.NewHttpClient())
+}
+
+// NewSender builds the sender from an explicit config and client so it can be
+// pointed at a stub gateway.
+func NewSender(deps *providerClient.Deps, cfg *config.RestSmsProvider, client *http.Client) (*SmsSender, error) {
+	if cfg == nil || cfg.Url == "" {
+		return nil, errors.New("rest sms provider url is not configured")
+	}
+
+	switch strings.ToLower(cfg.AuthScheme) {
+	case "", AuthNone, AuthBasic, AuthBearer, AuthApiKey:
+	default:
+		return nil, fmt.Errorf("unknown rest sms auth scheme %q", cfg.AuthScheme)
+	}
+
+	body, err := template.New("body").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(cfg.BodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rest sms body template: %w", err)
+	}
+
+	if _, err := parsePath(cfg.MessageIdPath); err != nil {
+		return nil, fmt.Errorf("invalid rest sms message id path: %w", err)
+	}
+	if _, err := parsePath(cfg.StatusPath); err != nil {
+		return nil, fmt.Errorf("invalid rest sms status path: %w", err)
+	}
+
+	return &SmsSender{
+		deps:   deps,
+		cfg:    cfg,
+		client: client,
+		body:   body,
+	}, nil
+}
+
+func (s *SmsSender) Name() string {
+	return constants.PROVIDER_RESTSMS
+}
+
+func (s *SmsSender) SendSms(ctx context.Context, smsMsg *model.Sms) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendRestSMS")
+	defer span.End()
+
+	reqBody := &bytes.Buffer{}
+	if err := s.body.Execute(reqBody, &bodyTemplateData{
+		Msisdn:   smsMsg.RecipientPhoneNumber,
+		Text:     smsMsg.Content,
+		SenderId: s.cfg.SenderId,
+	}); err != nil {
+		return nil, tracerClient.RecordError(span, fmt.Errorf("render body template: %w", err))
+	}
+
+	method := s.cfg.Method
+	if method == "" {
+		method = constants.METHOD_POST
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), s.cfg.Url, reqBody)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, method, smsMsg, err, "Create new request")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	httpReq.Header.Set("Content-Type", s.cfg.ContentType)
+	httpReq.Header.Set("Accept", "application/json")
+	s.setAuth(httpReq)
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, method, smsMsg, err, "Get http result")
+		return nil, tracerClient.RecordError(span, err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, method, smsMsg, err, "Get result body")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+
+	if httpRes.StatusCode < http.StatusOK || httpRes.StatusCode >= http.StatusMultipleChoices {
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.cfg.Url, method, string(httpResBody), err, "Check HTTP result code")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	result, err := s.parseResponse(httpResBody)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.cfg.Url, method, string(httpResBody), err, "Parse result body")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	s.deps.LogRestMessage(ctx, s.cfg.Url, method, string(httpResBody), nil, "Success to send sms request")
+
+	return result, nil
+}
+
+func (s *SmsSender) setAuth(req *http.Request) {
+	switch strings.ToLower(s.cfg.AuthScheme) {
+	case AuthBasic:
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	case AuthApiKey:
+		req.Header.Set(s.cfg.ApiKeyHeader, s.cfg.ApiKey)
+	}
+}
+
+func (s *SmsSender) parseResponse(body []byte) (*providerClient.DeliveryResult, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	messageID, err := lookupString(doc, s.cfg.MessageIdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := lookupString(doc, s.cfg.StatusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := lookupString(doc, s.cfg.MessagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := lookupInt(doc, s.cfg.SegmentsPath)
+	if err != nil {
+		return nil, fmt.Errorf("segments: %w", err)
+	}
+
+	result := &providerClient.DeliveryResult{
+		Provider:   constants.PROVIDER_RESTSMS,
+		MessageID:  messageID,
+		ResultCode: status,
+		Message:    message,
+		Segments:   segments,
+	}
+
+	if s.cfg.StatusPath != "" && s.cfg.SuccessStatus != "" && !strings.EqualFold(status, s.cfg.SuccessStatus) {
+		return result, fmt.Errorf("send sms failed with status: %s", status)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,211 @@
+package restsms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type gatewayRequest struct {
+	To     string `json:"to"`
+	Text   string `json:"text"`
+	Sender string `json:"sender"`
+}
+
+func TestSendSms(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		retryAfter    string
+		response      string
+		wantErr       bool
+		wantRetryable bool
+		wantAfter     time.Duration
+		wantResult    *providerClient.DeliveryResult
+	}{
+		{
+			name:     "success",
+			status:   http.StatusOK,
+			response: `{"data":{"messages":[{"id":"msg-1","status":"ACCEPTED","parts":2}]},"message-text":"queued"}`,
+			wantResult: &providerClient.DeliveryResult{
+				Provider:   "restsms",
+				MessageID:  "msg-1",
+				ResultCode: "ACCEPTED",
+				Message:    "queued",
+				Segments:   2,
+			},
+		},
+		{
+			name:     "rejected status",
+			status:   http.StatusOK,
+			response: `{"data":{"messages":[{"id":"msg-2","status":"REJECTED"}]}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "missing status path",
+			status:   http.StatusOK,
+			response: `{"data":{"messages":[]}}`,
+			wantErr:  true,
+		},
+		{
+			name:          "throttled with retry after",
+			status:        http.StatusTooManyRequests,
+			retryAfter:    "7",
+			response:      `{"error":"slow down"}`,
+			wantErr:       true,
+			wantRetryable: true,
+			wantAfter:     7 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got gatewayRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Api-Key") != "secret" {
+					t.Errorf("X-Api-Key = %q", r.Header.Get("X-Api-Key"))
+				}
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+				}
+
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Errorf("body %s is not valid JSON: %v", body, err)
+				}
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			sender := newTestSender(t, server)
+
+			// Quotes and newlines in the text must survive the json func.
+			text := "Kode \"OTP\" 123456\nJangan dibagikan"
+			result, err := sender.SendSms(context.Background(), &model.Sms{
+				RecipientPhoneNumber: "+6281234567890",
+				Content:              text,
+			})
+
+			if got.To != "+6281234567890" || got.Text != text || got.Sender != "BPJSTK" {
+				t.Errorf("rendered body = %+v", got)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("SendSms succeeded, want an error")
+				}
+				if providerClient.IsRetryable(err) != tt.wantRetryable {
+					t.Fatalf("IsRetryable(%v) = %v, want %v", err, !tt.wantRetryable, tt.wantRetryable)
+				}
+				if after, _ := providerClient.RetryAfter(err); after != tt.wantAfter {
+					t.Fatalf("RetryAfter = %s, want %s", after, tt.wantAfter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.wantResult) {
+				t.Fatalf("result = %+v, want %+v", result, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestNewSenderRejectsBadConfig(t *testing.T) {
+	cfg := testConfig("http://gateway")
+	cfg.StatusPath = "$.data['status"
+	if _, err := NewSender(testDeps(), cfg, http.DefaultClient); err == nil {
+		t.Error("NewSender accepted an unterminated status path")
+	}
+
+	cfg = testConfig("http://gateway")
+	cfg.BodyTemplate = "{{ .Msisdn "
+	if _, err := NewSender(testDeps(), cfg, http.DefaultClient); err == nil {
+		t.Error("NewSender accepted a broken body template")
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{"a":{"b":[{"c":"x"},{"c":42}]},"message-id":true}`))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "$.a.b[0].c", want: "x"},
+		{path: "a.b[1].c", want: "42"},
+		{path: "$.a.b[-1].c", want: "42"},
+		{path: "$['message-id']", want: "true"},
+		{path: "$.a.b[5].c", want: ""},
+		{path: "$.missing", want: ""},
+		{path: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		got, err := lookupString(doc, tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("lookupString(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	if _, err := lookupString(doc, "$.a.b.c"); err == nil {
+		t.Error("lookupString accepted a key as an array index")
+	}
+}
+
+func newTestSender(t *testing.T, server *httptest.Server) *SmsSender {
+	t.Helper()
+
+	sender, err := NewSender(testDeps(), testConfig(server.URL), server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
+
+func testConfig(url string) *config.RestSmsProvider {
+	return &config.RestSmsProvider{
+		Url:           url,
+		Method:        "POST",
+		AuthScheme:    AuthApiKey,
+		ApiKeyHeader:  "X-Api-Key",
+		ApiKey:        "secret",
+		SenderId:      "BPJSTK",
+		ContentType:   "application/json",
+		BodyTemplate:  `{"to":{{ json .Msisdn }},"text":{{ json .Text }},"sender":{{ json .SenderId }}}`,
+		MessageIdPath: "$.data.messages[0].id",
+		StatusPath:    "$.data.messages[0].status",
+		SuccessStatus: "accepted",
+		MessagePath:   "$['message-text']",
+		SegmentsPath:  "data.messages[0].parts",
+	}
+}
+
+func testDeps() *providerClient.Deps {
+	cfg := &config.Config{Project: &config.Project{ServiceName: "dispatch-service"}}
+	return providerClient.NewDeps(logger.NewAppLogger(&logger.Config{Level: "FATAL"}), cfg, trace.NewNoopTracerProvider().Tracer("test"))
+}
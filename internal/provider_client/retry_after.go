@@ -0,0 +1,60 @@
+package provider_client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryAfter is used when a provider signals a rate limit or quota
+// error without saying how long to wait.
+const DefaultRetryAfter = 30 * time.Second
+
+// ParseRetryAfter reads a Retry-After value given either in seconds or as an
+// HTTP date. It returns zero for a missing, invalid or past value.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+
+	return 0
+}
+
+// RateLimited wraps err in a RetryableError carrying the provider delay when
+// httpRes is a 429 or 503 answer, otherwise err is returned unchanged.
+func RateLimited(httpRes *http.Response, err error) error {
+	if httpRes == nil || err == nil {
+		return err
+	}
+
+	if httpRes.StatusCode != http.StatusTooManyRequests && httpRes.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+
+	return Throttled(httpRes, err)
+}
+
+// Throttled wraps err in a RetryableError delayed by the Retry-After header of
+// httpRes, or by DefaultRetryAfter, for quota errors a provider reports
+// outside of the 429 and 503 status codes.
+func Throttled(httpRes *http.Response, err error) error {
+	after := ParseRetryAfter(httpRes.Header.Get("Retry-After"), time.Now())
+	if after == 0 {
+		after = DefaultRetryAfter
+	}
+
+	return &RetryableError{Err: err, After: after}
+}
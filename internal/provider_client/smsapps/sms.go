@@ -0,0 +1,155 @@
+package smsapps
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/utils"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	resultSuccess = "SUCCESS"
+)
+
+func init() {
+	providerClient.RegisterSmsSender(constants.PROVIDER_SMSAPPS, NewSmsSender)
+}
+
+type SmsSender struct {
+	deps *providerClient.Deps
+}
+
+func NewSmsSender(deps *providerClient.Deps) (providerClient.SmsSender, error) {
+	return &SmsSender{
+		deps: deps,
+	}, nil
+}
+
+func (s *SmsSender) Name() string {
+	return constants.PROVIDER_SMSAPPS
+}
+
+func (s *SmsSender) SendSms(ctx context.Context, smsMsg *model.Sms) (*providerClient.DeliveryResult, error) {
+	msg, kode, err := s.send(ctx, smsMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(msg, resultSuccess) {
+		return nil, fmt.Errorf("send sms failed with message: %s", msg)
+	}
+
+	kodeStruct := model.SendSmsResCode{}
+	if err := utils.StringToStruct(kode, &kodeStruct); err != nil {
+		return nil, err
+	}
+
+	return &providerClient.DeliveryResult{
+		Provider:   constants.PROVIDER_SMSAPPS,
+		MessageID:  kodeStruct.MsgID,
+		ResultCode: kodeStruct.Code,
+		Message:    kodeStruct.Message,
+		Segments:   kodeStruct.NumSms,
+	}, nil
+}
+
+type SmsEnvelopeReq struct {
+	XMLName  xml.Name   `xml:"x:Envelope"`
+	XmlnsX   string     `xml:"xmlns:x,attr"`
+	XmlnsBpj string     `xml:"xmlns:bpj,attr"`
+	Header   string     `xml:"x:Header"`
+	Body     SmsBodyReq `xml:"x:Body"`
+}
+
+type SmsBodyReq struct {
+	SendSms SendSms `xml:"bpj:sendSMS"`
+}
+
+type SendSms struct {
+	Username string `xml:"bpj:username"`
+	Password string `xml:"bpj:password"`
+	Msisdn   string `xml:"bpj:msisdn"`
+	Txt      string `xml:"bpj:txt"`
+	Avl      string `xml:"bpj:avl"`
+}
+
+func (s *SmsSender) send(ctx context.Context, smsMsg *model.Sms) (string, string, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendSMS")
+	defer span.End()
+
+	client := s.deps.NewHttpClient()
+
+	xmlReq := getSmsRequestXml(s.deps.Cfg.ProviderClient.SmsProvider.Username, s.deps.Cfg.ProviderClient.SmsProvider.Password, smsMsg.RecipientPhoneNumber, smsMsg.Content)
+
+	httpReqBody := strings.NewReader(xmlReq)
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, s.deps.Cfg.ProviderClient.SmsProvider.Url, httpReqBody)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.SmsProvider.Url, constants.METHOD_POST, smsMsg, err, "Create new request")
+		return "", "", tracerClient.RecordError(span, err)
+	}
+
+	httpReq.Header.Add("Content-Type", "text/xml; charset=utf-8")
+	httpReq.Header.Add("SOAPAction", "")
+
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.SmsProvider.Url, constants.METHOD_POST, smsMsg, err, "Get http result")
+		return "", "", tracerClient.RecordError(span, err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.SmsProvider.Url, constants.METHOD_POST, smsMsg, err, "Get result body")
+		return "", "", tracerClient.RecordError(span, err)
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+
+	if httpRes.StatusCode != http.StatusOK {
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.SmsProvider.Url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return "", "", tracerClient.RecordError(span, err)
+	}
+
+	resMsg := strings.Split(string(httpResBody), "<ax21:msg>")
+	resMsg = strings.Split(resMsg[1], "</ax21:msg>")
+	msg := resMsg[0]
+
+	resKode := strings.Split(string(httpResBody), "<ax21:kode>")
+	resKode = strings.Split(resKode[1], "</ax21:kode>")
+	kode := resKode[0]
+
+	s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, string(httpResBody), nil, "Success to send sms request")
+
+	return msg, kode, nil
+}
+
+func getSmsRequestXml(username string, password string, msisdn string, txt string) string {
+	payload := `
+	<x:Envelope xmlns:x="http://schemas.xmlsoap.org/soap/envelope/" xmlns:bpj="http://bpjs.com">
+    <x:Header/>
+    <x:Body>
+        <bpj:sendSMS>
+            <bpj:username>` + username + `</bpj:username>
+            <bpj:password>` + password + `</bpj:password>
+            <bpj:msisdn>` + msisdn + `</bpj:msisdn>
+            <bpj:txt>` + txt + `</bpj:txt>
+            <bpj:avl></bpj:avl>
+        </bpj:sendSMS>
+    </x:Body>
+  </x:Envelope>
+  `
+	return payload
+}
@@ -0,0 +1,269 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	netSmtp "net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	SecurityStartTLS = "starttls"
+	SecurityTLS      = "tls"
+	SecurityNone     = "none"
+)
+
+func init() {
+	providerClient.RegisterEmailSender(constants.PROVIDER_SMTP, NewEmailSender)
+}
+
+type EmailSender struct {
+	deps     *providerClient.Deps
+	cfg      *config.SmtpProvider
+	addr     string
+	from     mail.Address
+	security string
+	timeout  time.Duration
+	tlsCfg   *tls.Config
+}
+
+func NewEmailSender(deps *providerClient.Deps) (providerClient.EmailSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.SmtpProvider)
+}
+
+// NewSender builds the sender from an explicit config so it can be pointed at
+// a local SMTP stand-in.
+func NewSender(deps *providerClient.Deps, cfg *config.SmtpProvider) (*EmailSender, error) {
+	if cfg == nil || cfg.Host == "" {
+		return nil, errors.New("smtp provider host is not configured")
+	}
+
+	security := strings.ToLower(cfg.Security)
+	switch security {
+	case SecurityStartTLS, SecurityTLS, SecurityNone:
+	case "":
+		security = SecurityStartTLS
+	default:
+		return nil, fmt.Errorf("unknown smtp security %q", cfg.Security)
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address: %w", err)
+	}
+	if cfg.FromName != "" {
+		from.Name = cfg.FromName
+	}
+
+	timeout := 30 * time.Second
+	if cfg.Timeout != "" {
+		timeout, err = time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smtp timeout: %w", err)
+		}
+	}
+
+	insecure, _ := strconv.ParseBool(cfg.InsecureSkipVerify)
+
+	return &EmailSender{
+		deps:     deps,
+		cfg:      cfg,
+		addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		from:     *from,
+		security: security,
+		timeout:  timeout,
+		tlsCfg: &tls.Config{
+			ServerName:         cfg.Host,
+			InsecureSkipVerify: insecure,
+		},
+	}, nil
+}
+
+func (s *EmailSender) Name() string {
+	return constants.PROVIDER_SMTP
+}
+
+func (s *EmailSender) SendEmail(ctx context.Context, emailMsg *model.Email) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendSmtpEmail")
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.NetPeerNameKey.String(s.cfg.Host),
+		semconv.NetPeerPortKey.String(s.cfg.Port),
+	)
+
+	recipients := make([]string, 0, len(emailMsg.RecipientTo)+len(emailMsg.RecipientCc)+len(emailMsg.RecipientBcc))
+	recipients = append(recipients, emailMsg.RecipientTo...)
+	recipients = append(recipients, emailMsg.RecipientCc...)
+	recipients = append(recipients, emailMsg.RecipientBcc...)
+	if len(recipients) == 0 {
+		return nil, tracerClient.RecordError(span, errors.New("email has no recipient"))
+	}
+
+	messageID, err := newMessageID(s.from.Address[strings.LastIndex(s.from.Address, "@")+1:])
+	if err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	msg, err := buildMessage(s.from, messageID, emailMsg)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.addr, constants.PROTOCOL_SMTP, emailMsg, err, "Build MIME message")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	accepted, rejected, err := s.send(ctx, recipients, msg)
+	if err != nil {
+		err = classify(err)
+		s.deps.LogRestMessage(ctx, s.addr, constants.PROTOCOL_SMTP, emailMsg, err, "Send smtp message")
+		if len(rejected) == 0 {
+			return nil, tracerClient.RecordError(span, err)
+		}
+
+		return &providerClient.DeliveryResult{
+			Provider:          constants.PROVIDER_SMTP,
+			Message:           fmt.Sprintf("0 accepted, %d rejected", len(rejected)),
+			InvalidRecipients: rejected,
+		}, tracerClient.RecordError(span, err)
+	}
+
+	for rcpt, reason := range rejected {
+		s.deps.LogRestMessage(ctx, s.addr, constants.PROTOCOL_SMTP, rcpt, errors.New(reason), "Recipient rejected")
+	}
+
+	s.deps.LogRestMessage(ctx, s.addr, constants.PROTOCOL_SMTP, messageID, nil, "Success to send smtp message")
+
+	return &providerClient.DeliveryResult{
+		Provider:          constants.PROVIDER_SMTP,
+		MessageID:         messageID,
+		Message:           fmt.Sprintf("%d accepted, %d rejected", accepted, len(rejected)),
+		Succeeded:         accepted,
+		InvalidRecipients: rejected,
+	}, nil
+}
+
+// send runs one SMTP transaction and returns how many recipients were
+// accepted. Recipients the server refuses with a 5yz reply are skipped and
+// returned with the reply as reason, the message is only aborted when no
+// recipient was accepted.
+func (s *EmailSender) send(ctx context.Context, recipients []string, msg []byte) (int, map[string]string, error) {
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.security == SecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsCfg}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("dial %s: %w", s.addr, err)
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := netSmtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return 0, nil, err
+	}
+	defer client.Close()
+
+	if s.cfg.HeloName != "" {
+		if err := client.Hello(s.cfg.HeloName); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if s.security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return 0, nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(s.tlsCfg); err != nil {
+			return 0, nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		auth := netSmtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			return 0, nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return 0, nil, err
+	}
+
+	accepted := 0
+	rejected := make(map[string]string)
+	var rcptErrs []error
+	for _, rcpt := range recipients {
+		err := client.Rcpt(rcpt)
+		if err == nil {
+			accepted++
+			continue
+		}
+
+		var protoErr *textproto.Error
+		if !errors.As(err, &protoErr) || protoErr.Code < 500 {
+			return 0, nil, fmt.Errorf("rcpt %s: %w", rcpt, err)
+		}
+		rejected[rcpt] = protoErr.Error()
+		rcptErrs = append(rcptErrs, fmt.Errorf("rcpt %s: %w", rcpt, err))
+	}
+	if accepted == 0 {
+		return 0, rejected, errors.Join(rcptErrs...)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return 0, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, nil, err
+	}
+
+	return accepted, rejected, client.Quit()
+}
+
+// classify delays transient 4yz replies, which servers use for rate limits
+// and greylisting, since SMTP has no Retry-After of its own, and marks
+// permanent 5yz replies as not worth retrying.
+func classify(err error) error {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return err
+	}
+
+	switch protoErr.Code {
+	case 421, 450, 451, 452:
+		return &providerClient.RetryableError{Err: err, After: providerClient.DefaultRetryAfter}
+	}
+	if protoErr.Code >= 500 && protoErr.Code < 600 {
+		return &providerClient.PermanentError{Err: err}
+	}
+
+	return err
+}
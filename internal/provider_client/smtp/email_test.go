@@ -0,0 +1,180 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// serveSmtp answers one SMTP session on a local port, refusing the RCPT of
+// every address in rejected with a 550. It returns the address to dial and a
+// channel that receives the recipients the server accepted once DATA ends.
+func serveSmtp(t *testing.T, rejected ...string) (string, <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	delivered := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP")
+
+		var accepted []string
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "MAIL":
+				tp.PrintfLine("250 ok")
+			case "RCPT":
+				rcpt := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+				if contains(rejected, rcpt) {
+					tp.PrintfLine("550 5.1.1 no such user")
+					continue
+				}
+				accepted = append(accepted, rcpt)
+				tp.PrintfLine("250 ok")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				io.Copy(io.Discard, tp.DotReader())
+				tp.PrintfLine("250 queued")
+				delivered <- accepted
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), delivered
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestSender(t *testing.T, addr string) *EmailSender {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(addr)
+	deps := providerClient.NewDeps(
+		logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		&config.Config{Project: &config.Project{ServiceName: "dispatch-service"}},
+		trace.NewNoopTracerProvider().Tracer("test"),
+	)
+	sender, err := NewSender(deps, &config.SmtpProvider{
+		Host:     host,
+		Port:     port,
+		Security: SecurityNone,
+		From:     "noreply@example.com",
+		Timeout:  "5s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sender
+}
+
+func TestSendEmailSkipsRejectedRecipient(t *testing.T) {
+	addr, delivered := serveSmtp(t, "gone@example.com")
+	sender := newTestSender(t, addr)
+
+	result, err := sender.SendEmail(context.Background(), &model.Email{
+		RecipientTo:  []string{"to@example.com"},
+		RecipientBcc: []string{"gone@example.com"},
+		Subject:      "subject",
+		ContentText:  "body",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := <-delivered; !reflect.DeepEqual(got, []string{"to@example.com"}) {
+		t.Errorf("delivered to %v", got)
+	}
+	if result.Succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", result.Succeeded)
+	}
+	if _, ok := result.InvalidRecipients["gone@example.com"]; !ok || len(result.InvalidRecipients) != 1 {
+		t.Errorf("invalid recipients = %v", result.InvalidRecipients)
+	}
+}
+
+func TestSendEmailAllRejectedIsPermanent(t *testing.T) {
+	addr, _ := serveSmtp(t, "a@example.com", "b@example.com")
+	sender := newTestSender(t, addr)
+
+	result, err := sender.SendEmail(context.Background(), &model.Email{
+		RecipientTo: []string{"a@example.com", "b@example.com"},
+		Subject:     "subject",
+		ContentText: "body",
+	})
+
+	var permanentErr *providerClient.PermanentError
+	if !errors.As(err, &permanentErr) {
+		t.Fatalf("err = %v, want PermanentError", err)
+	}
+	if result == nil || len(result.InvalidRecipients) != 2 || result.Succeeded != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		code      int
+		retryable bool
+		permanent bool
+	}{
+		{code: 421, retryable: true},
+		{code: 452, retryable: true},
+		{code: 454},
+		{code: 550, permanent: true},
+		{code: 554, permanent: true},
+	}
+
+	for _, c := range cases {
+		err := classify(&textproto.Error{Code: c.code, Msg: "reply"})
+
+		var permanentErr *providerClient.PermanentError
+		if got := providerClient.IsRetryable(err); got != c.retryable {
+			t.Errorf("%d retryable = %v, want %v", c.code, got, c.retryable)
+		}
+		if got := errors.As(err, &permanentErr); got != c.permanent {
+			t.Errorf("%d permanent = %v, want %v", c.code, got, c.permanent)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+)
+
+// Headers that are produced by the message builder and may not be overridden
+// through model.Email.Headers.
+var reservedHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Reply-To":                  true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+type attachment struct {
+	name    string
+	content []byte
+}
+
+// buildMessage renders the email as RFC 5322 / MIME. The body is a
+// multipart/alternative of text and HTML, wrapped in multipart/mixed when
+// there are attachments.
+func buildMessage(from mail.Address, messageID string, email *model.Email) ([]byte, error) {
+	attachments, err := decodeAttachments(email)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(email.RecipientTo, ", "))
+	if len(email.RecipientCc) > 0 {
+		header.Set("Cc", strings.Join(email.RecipientCc, ", "))
+	}
+	if email.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(email.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply-to: %w", err)
+		}
+		header.Set("Reply-To", replyTo.String())
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-Id", messageID)
+	header.Set("Mime-Version", "1.0")
+
+	for key, value := range email.Headers {
+		key = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))
+		if key == "" || reservedHeaders[key] || strings.ContainsAny(key, "\r\n: ") {
+			return nil, fmt.Errorf("header %q is not allowed", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header %q contains a line break", key)
+		}
+		header.Set(key, mime.QEncoding.Encode("utf-8", value))
+	}
+
+	if len(attachments) == 0 {
+		if err := writeBody(email, func(h textproto.MIMEHeader) (io.Writer, error) {
+			for k, v := range h {
+				header[k] = v
+			}
+			writeHeader(buf, header)
+			return buf, nil
+		}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	writeHeader(buf, header)
+
+	if err := writeBody(email, mixed.CreatePart); err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		if err := writeAttachment(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the text or text+HTML body as a single part opened through
+// create, which either starts the top level message or a multipart/mixed part.
+func writeBody(email *model.Email, create func(textproto.MIMEHeader) (io.Writer, error)) error {
+	if !email.IsHTML || email.ContentHTML == "" {
+		w, err := create(textproto.MIMEHeader{
+			"Content-Type":              {"text/plain; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		return writeQuotedPrintable(w, email.ContentText)
+	}
+
+	bodyBuf := &bytes.Buffer{}
+	alternative := multipart.NewWriter(bodyBuf)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", email.ContentText},
+		{"text/html; charset=utf-8", email.ContentHTML},
+	} {
+		w, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	if err := alternative.Close(); err != nil {
+		return err
+	}
+
+	w, err := create(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bodyBuf.Bytes())
+	return err
+}
+
+func writeAttachment(mixed *multipart.Writer, a attachment) error {
+	contentType := mime.TypeByExtension(filepath.Ext(a.name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	filename := mime.QEncoding.Encode("utf-8", a.name)
+
+	w, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": filename})},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.content)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(w, encoded+"\r\n")
+
+	return err
+}
+
+// decodeAttachments pairs the base64 attachments with their AttachName, an
+// attachment without a name gets a generated one.
+func decodeAttachments(email *model.Email) ([]attachment, error) {
+	if !email.IsAttach {
+		return nil, nil
+	}
+
+	attachments := make([]attachment, 0, len(email.Attachment))
+	for i, encoded := range email.Attachment {
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("attachment %d is not valid base64: %w", i, err)
+		}
+
+		name := fmt.Sprintf("attachment-%d", i+1)
+		if i < len(email.AttachName) && strings.TrimSpace(email.AttachName[i]) != "" {
+			name = filepath.Base(strings.TrimSpace(email.AttachName[i]))
+		}
+
+		attachments = append(attachments, attachment{
+			name:    name,
+			content: content,
+		})
+	}
+
+	return attachments, nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range header[k] {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, content); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func newMessageID(domain string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain), nil
+}
@@ -10,24 +10,33 @@ import (
 	loggerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
 )
 
-func (pc *ProviderClient) logRestMessage(ctx context.Context, endpoint, method string, data interface{}, err error, activity string) {
-	logFields := pc.getRestLogFields(ctx, endpoint, method, data, err, activity)
+// Priority is the priority of the message being sent, the process priority
+// unless the message was consumed in mixed priority mode.
+func (d *Deps) Priority(ctx context.Context) string {
+	if metadata, ok := contextMD.GetMetadataFromContext(ctx); ok && metadata.Priority != "" {
+		return metadata.Priority
+	}
+	return d.Cfg.Project.Priority
+}
+
+func (d *Deps) LogRestMessage(ctx context.Context, endpoint, method string, data interface{}, err error, activity string) {
+	logFields := d.getRestLogFields(ctx, endpoint, method, data, err, activity)
 	if err != nil {
 		logFields.LogLevel = constants.LEVEL_ERROR
 		logFields.Error = constants.TRUE
 		logFields.Message.ErrorMessage = err.Error()
 	}
-	pc.logger.StructuredPrint(logFields)
+	d.Logger.StructuredPrint(logFields)
 }
 
-func (pc *ProviderClient) getRestLogFields(ctx context.Context, endpoint, method string, data interface{}, err error, activity string) *loggerClient.LogFields {
+func (d *Deps) getRestLogFields(ctx context.Context, endpoint, method string, data interface{}, err error, activity string) *loggerClient.LogFields {
 	metadata, _ := contextMD.GetMetadataFromContext(ctx)
 
 	return &loggerClient.LogFields{
 		Timestamp:     time.Now().Format(constants.TIME_LAYOUT_FORMAT),
 		LogLevel:      getLoggerLogLevel(err),
 		TransactionID: fmt.Sprintf("TR%s", metadata.TransactionID),
-		ServiceName:   pc.cfg.Project.ServiceName,
+		ServiceName:   d.Cfg.Project.ServiceName,
 		Endpoint:      endpoint,
 		Protocol:      constants.PROTOCOL_HTTP,
 		MethodType:    method,
@@ -40,12 +49,12 @@ func (pc *ProviderClient) getRestLogFields(ctx context.Context, endpoint, method
 			Others:   "",
 		},
 		ExecutionTime:     fmt.Sprint(time.Since(time.UnixMilli(metadata.StartTime)).Milliseconds()),
-		ServerIP:          pc.cfg.Project.ServerIP,
+		ServerIP:          d.Cfg.Project.ServerIP,
 		ClientIP:          "",
 		EventName:         "",
 		TraceID:           fmt.Sprintf("TC%s", metadata.TraceID),
 		PrevTransactionID: "",
-		Body:              fmt.Sprint(data),
+		Body:              d.Logger.Redact(data),
 		Result:            "",
 		Error:             constants.FALSE,
 		FlagStartOrStop:   constants.STOP,
@@ -0,0 +1,245 @@
+package wacloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func init() {
+	providerClient.RegisterWhatsAppSender(constants.PROVIDER_WACLOUD, NewWhatsAppSender)
+}
+
+type WhatsAppSender struct {
+	deps   *providerClient.Deps
+	cfg    *config.WhatsAppProvider
+	url    string
+	client *http.Client
+}
+
+func NewWhatsAppSender(deps *providerClient.Deps) (providerClient.WhatsAppSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.WhatsAppProvider, deps.NewHttpClient())
+}
+
+// NewSender builds the sender from an explicit config and client so it can be
+// pointed at a stub Cloud API.
+func NewSender(deps *providerClient.Deps, cfg *config.WhatsAppProvider, client *http.Client) (*WhatsAppSender, error) {
+	if cfg == nil || cfg.Url == "" || cfg.PhoneNumberId == "" {
+		return nil, errors.New("whatsapp provider url and phone number id are required")
+	}
+
+	return &WhatsAppSender{
+		deps:   deps,
+		cfg:    cfg,
+		url:    strings.TrimRight(cfg.Url, "/") + "/" + cfg.PhoneNumberId + "/messages",
+		client: client,
+	}, nil
+}
+
+func (s *WhatsAppSender) Name() string {
+	return constants.PROVIDER_WACLOUD
+}
+
+type messageReq struct {
+	MessagingProduct string      `json:"messaging_product"`
+	RecipientType    string      `json:"recipient_type"`
+	To               string      `json:"to"`
+	Type             string      `json:"type"`
+	Template         templateReq `json:"template"`
+}
+
+type templateReq struct {
+	Name       string         `json:"name"`
+	Language   languageReq    `json:"language"`
+	Components []componentReq `json:"components,omitempty"`
+}
+
+type languageReq struct {
+	Code string `json:"code"`
+}
+
+type componentReq struct {
+	Type       string         `json:"type"`
+	Parameters []parameterReq `json:"parameters"`
+}
+
+type parameterReq struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	Image    *mediaReq `json:"image,omitempty"`
+	Document *mediaReq `json:"document,omitempty"`
+	Video    *mediaReq `json:"video,omitempty"`
+}
+
+type mediaReq struct {
+	Link     string `json:"link"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type messageRes struct {
+	Messages []struct {
+		ID            string `json:"id"`
+		MessageStatus string `json:"message_status"`
+	} `json:"messages"`
+	Error *struct {
+		Message   string `json:"message"`
+		Type      string `json:"type"`
+		Code      int    `json:"code"`
+		FbtraceID string `json:"fbtrace_id"`
+	} `json:"error"`
+}
+
+func (s *WhatsAppSender) SendWhatsApp(ctx context.Context, whatsappMsg *model.WhatsApp) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendWhatsApp")
+	defer span.End()
+
+	reqBody, err := getMessageRequest(whatsappMsg)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, whatsappMsg, err, "Create request body")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	reqBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, s.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, whatsappMsg, err, "Create new request")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+s.cfg.AccessToken)
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, whatsappMsg, err, "Get http result")
+		return nil, tracerClient.RecordError(span, err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, whatsappMsg, err, "Get result body")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+
+	res := &messageRes{}
+	if err := json.Unmarshal(httpResBody, res); err != nil && httpRes.StatusCode == http.StatusOK {
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, string(httpResBody), err, "Parse result body")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	if res.Error != nil {
+		err = fmt.Errorf("status code %v: %s (code %d)", httpRes.StatusCode, res.Error.Message, res.Error.Code)
+		if rateLimitCodes[res.Error.Code] {
+			err = providerClient.Throttled(httpRes, err)
+		} else {
+			err = providerClient.RateLimited(httpRes, err)
+		}
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return &providerClient.DeliveryResult{
+			Provider:   constants.PROVIDER_WACLOUD,
+			ResultCode: strconv.Itoa(res.Error.Code),
+			Message:    res.Error.Message,
+		}, tracerClient.RecordError(span, err)
+	}
+
+	if httpRes.StatusCode != http.StatusOK || len(res.Messages) == 0 {
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	s.deps.LogRestMessage(ctx, s.url, constants.METHOD_POST, string(httpResBody), nil, "Success to send whatsapp request")
+
+	return &providerClient.DeliveryResult{
+		Provider:   constants.PROVIDER_WACLOUD,
+		MessageID:  res.Messages[0].ID,
+		ResultCode: res.Messages[0].MessageStatus,
+	}, nil
+}
+
+// Graph API throttling codes, answered with 400 or 429 depending on the
+// limit hit, that clear by themselves after a while.
+var rateLimitCodes = map[int]bool{
+	4:      true,
+	80007:  true,
+	130429: true,
+	131048: true,
+	131056: true,
+}
+
+func getMessageRequest(whatsappMsg *model.WhatsApp) (*messageReq, error) {
+	if whatsappMsg.TemplateName == "" {
+		return nil, errors.New("whatsapp template name is required")
+	}
+
+	language := whatsappMsg.Language
+	if language == "" {
+		language = "id"
+	}
+
+	components := []componentReq{}
+
+	if media := whatsappMsg.Media; media != nil && media.Url != "" {
+		param := parameterReq{Type: media.Type}
+		switch media.Type {
+		case "image":
+			param.Image = &mediaReq{Link: media.Url}
+		case "document":
+			param.Document = &mediaReq{Link: media.Url, Filename: media.Filename}
+		case "video":
+			param.Video = &mediaReq{Link: media.Url}
+		default:
+			return nil, fmt.Errorf("unsupported whatsapp media type %q", media.Type)
+		}
+
+		components = append(components, componentReq{
+			Type:       "header",
+			Parameters: []parameterReq{param},
+		})
+	}
+
+	if len(whatsappMsg.Parameters) > 0 {
+		params := make([]parameterReq, 0, len(whatsappMsg.Parameters))
+		for _, p := range whatsappMsg.Parameters {
+			params = append(params, parameterReq{Type: "text", Text: p})
+		}
+
+		components = append(components, componentReq{
+			Type:       "body",
+			Parameters: params,
+		})
+	}
+
+	return &messageReq{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               strings.TrimPrefix(whatsappMsg.RecipientPhoneNumber, "+"),
+		Type:             "template",
+		Template: templateReq{
+			Name:       whatsappMsg.TemplateName,
+			Language:   languageReq{Code: language},
+			Components: components,
+		},
+	}, nil
+}
@@ -0,0 +1,348 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"github.com/google/uuid"
+)
+
+const (
+	deliveryIdHeader = "X-CNS-Delivery-Id"
+	eventHeader      = "X-CNS-Event"
+
+	// hostIdleTimeout is how long a destination keeps its concurrency slots
+	// after its last request.
+	hostIdleTimeout = 10 * time.Minute
+)
+
+func init() {
+	providerClient.RegisterWebhookSender(constants.PROVIDER_HTTP, NewWebhookSender)
+}
+
+type WebhookSender struct {
+	deps           *providerClient.Deps
+	cfg            *config.WebhookProvider
+	client         *http.Client
+	defaultSecret  string
+	secrets        map[string]string
+	allowedHosts   map[string]bool
+	allowInsecure  bool
+	maxAttempts    int
+	maxConcurrency int
+	acquireTimeout time.Duration
+
+	mu      sync.Mutex
+	hosts   map[string]*hostSlots
+	sweptAt time.Time
+}
+
+type hostSlots struct {
+	slots    chan struct{}
+	lastUsed time.Time
+}
+
+func NewWebhookSender(deps *providerClient.Deps) (providerClient.WebhookSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.WebhookProvider, deps.NewPublicHttpClient())
+}
+
+// NewSender builds the sender from an explicit config and client. The client
+// is expected to refuse internal addresses like NewPublicHttpClient does.
+func NewSender(deps *providerClient.Deps, cfg *config.WebhookProvider, client *http.Client) (*WebhookSender, error) {
+	if cfg == nil {
+		return nil, errors.New("webhook provider is not configured")
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook timeout: %w", err)
+	}
+	client.Timeout = timeout
+
+	acquireTimeout, err := time.ParseDuration(cfg.AcquireTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook acquire timeout: %w", err)
+	}
+
+	maxAttempts, err := strconv.Atoi(cfg.MaxAttempts)
+	if err != nil || maxAttempts < 1 {
+		return nil, fmt.Errorf("invalid webhook max attempts %q", cfg.MaxAttempts)
+	}
+
+	maxConcurrency, err := strconv.Atoi(cfg.MaxConcurrency)
+	if err != nil || maxConcurrency < 1 {
+		return nil, fmt.Errorf("invalid webhook max concurrency %q", cfg.MaxConcurrency)
+	}
+
+	allowInsecure, _ := strconv.ParseBool(cfg.AllowInsecureScheme)
+
+	s := &WebhookSender{
+		deps:           deps,
+		cfg:            cfg,
+		client:         client,
+		secrets:        make(map[string]string),
+		allowedHosts:   make(map[string]bool),
+		allowInsecure:  allowInsecure,
+		maxAttempts:    maxAttempts,
+		maxConcurrency: maxConcurrency,
+		acquireTimeout: acquireTimeout,
+		hosts:          make(map[string]*hostSlots),
+		sweptAt:        time.Now(),
+	}
+
+	for _, entry := range strings.Split(cfg.Secrets, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if channel, secret, found := strings.Cut(entry, "="); found {
+			s.secrets[strings.ToLower(strings.TrimSpace(channel))] = strings.TrimSpace(secret)
+			continue
+		}
+		s.defaultSecret = entry
+	}
+
+	for _, host := range strings.Split(cfg.AllowedHosts, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			s.allowedHosts[host] = true
+		}
+	}
+
+	return s, nil
+}
+
+func (s *WebhookSender) Name() string {
+	return constants.PROVIDER_HTTP
+}
+
+// SendWebhook posts the payload to the subscriber, retrying retryable
+// failures up to MaxAttempts. Errors are wrapped in PermanentError or
+// RetryableError so the caller can decide what to do with the message.
+func (s *WebhookSender) SendWebhook(ctx context.Context, channel string, webhookMsg *model.Webhook) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendWebhook")
+	defer span.End()
+
+	target, err := s.validateUrl(webhookMsg.Url)
+	if err != nil {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: err})
+	}
+
+	secret := s.secretFor(channel)
+	if secret == "" {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: fmt.Errorf("no webhook secret configured for channel %s", channel)})
+	}
+
+	if !json.Valid(webhookMsg.Payload) {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: errors.New("webhook payload is not valid json")})
+	}
+
+	release, err := s.acquire(ctx, target.Host)
+	if err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+	defer release()
+
+	deliveryID := uuid.New().String()
+
+	var result *providerClient.DeliveryResult
+	for attempt := 1; attempt <= s.maxAttempts; attempt++ {
+		result, err = s.post(ctx, target.String(), secret, deliveryID, webhookMsg)
+		if err == nil || !providerClient.IsRetryable(err) || attempt == s.maxAttempts {
+			break
+		}
+
+		// A subscriber asking to back off is rescheduled by the caller rather
+		// than retried right away.
+		if _, ok := providerClient.RetryAfter(err); ok {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, tracerClient.RecordError(span, &providerClient.RetryableError{Err: ctx.Err()})
+		case <-time.After(time.Duration(attempt*attempt) * 500 * time.Millisecond):
+		}
+	}
+	if err != nil {
+		// Out of attempts, the message waits on the delay topic instead of
+		// being dropped.
+		var retryableErr *providerClient.RetryableError
+		if errors.As(err, &retryableErr) && retryableErr.After == 0 {
+			retryableErr.After = providerClient.DefaultRetryAfter
+		}
+		return result, tracerClient.RecordError(span, err)
+	}
+
+	return result, nil
+}
+
+func (s *WebhookSender) post(ctx context.Context, target string, secret string, deliveryID string, webhookMsg *model.Webhook) (*providerClient.DeliveryResult, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, target, bytes.NewReader(webhookMsg.Payload))
+	if err != nil {
+		return nil, &providerClient.PermanentError{Err: err}
+	}
+
+	for key, value := range webhookMsg.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(deliveryIdHeader, deliveryID)
+	if webhookMsg.EventName != "" {
+		httpReq.Header.Set(eventHeader, webhookMsg.EventName)
+	}
+	httpReq.Header.Set(s.cfg.TimestampHeader, timestamp)
+	httpReq.Header.Set(s.cfg.SignatureHeader, "sha256="+Sign(secret, timestamp, webhookMsg.Payload))
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, target, constants.METHOD_POST, webhookMsg, err, "Get http result")
+		return nil, providerClient.RequestError(err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, _ := io.ReadAll(io.LimitReader(httpRes.Body, 4096))
+
+	result := &providerClient.DeliveryResult{
+		Provider:   constants.PROVIDER_HTTP,
+		MessageID:  deliveryID,
+		ResultCode: strconv.Itoa(httpRes.StatusCode),
+		Message:    string(httpResBody),
+	}
+
+	switch {
+	case httpRes.StatusCode >= http.StatusOK && httpRes.StatusCode < http.StatusMultipleChoices:
+		s.deps.LogRestMessage(ctx, target, constants.METHOD_POST, string(httpResBody), nil, "Success to send webhook request")
+		return result, nil
+	case httpRes.StatusCode == http.StatusTooManyRequests || httpRes.StatusCode == http.StatusServiceUnavailable:
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+	case httpRes.StatusCode == http.StatusRequestTimeout || httpRes.StatusCode >= http.StatusInternalServerError:
+		err = &providerClient.RetryableError{Err: fmt.Errorf("status code %v", httpRes.StatusCode)}
+	default:
+		err = &providerClient.PermanentError{Err: fmt.Errorf("status code %v", httpRes.StatusCode)}
+	}
+
+	s.deps.LogRestMessage(ctx, target, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+
+	return result, err
+}
+
+// acquire takes a slot for host. A destination that already has
+// MaxConcurrency requests in flight only blocks its own messages, and only
+// for AcquireTimeout, after which the message is deferred.
+func (s *WebhookSender) acquire(ctx context.Context, host string) (func(), error) {
+	s.mu.Lock()
+	now := time.Now()
+	s.evictIdleHosts(now)
+	entry, ok := s.hosts[host]
+	if !ok {
+		entry = &hostSlots{slots: make(chan struct{}, s.maxConcurrency)}
+		s.hosts[host] = entry
+	}
+	entry.lastUsed = now
+	slots := entry.slots
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.acquireTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return func() {
+			<-slots
+
+			s.mu.Lock()
+			entry.lastUsed = time.Now()
+			s.mu.Unlock()
+		}, nil
+	case <-timer.C:
+		return nil, &providerClient.RetryableError{
+			Err:   fmt.Errorf("webhook destination %s is at its concurrency limit", host),
+			After: providerClient.DefaultRetryAfter,
+		}
+	case <-ctx.Done():
+		return nil, &providerClient.RetryableError{Err: ctx.Err()}
+	}
+}
+
+// evictIdleHosts drops destinations with no request in flight that were not
+// used for hostIdleTimeout, subscriber urls are unbounded and the map would
+// otherwise only grow. Callers hold s.mu.
+func (s *WebhookSender) evictIdleHosts(now time.Time) {
+	if now.Sub(s.sweptAt) < hostIdleTimeout {
+		return
+	}
+	s.sweptAt = now
+
+	for host, entry := range s.hosts {
+		if len(entry.slots) == 0 && now.Sub(entry.lastUsed) >= hostIdleTimeout {
+			delete(s.hosts, host)
+		}
+	}
+}
+
+func (s *WebhookSender) validateUrl(rawUrl string) (*url.URL, error) {
+	target, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+
+	switch {
+	case target.Scheme == "https":
+	case target.Scheme == "http" && s.allowInsecure:
+	default:
+		return nil, fmt.Errorf("webhook url scheme %q is not allowed", target.Scheme)
+	}
+
+	if target.Host == "" {
+		return nil, errors.New("webhook url has no host")
+	}
+
+	if len(s.allowedHosts) > 0 && !s.allowedHosts[strings.ToLower(target.Hostname())] {
+		return nil, fmt.Errorf("webhook host %s is not allowed", target.Hostname())
+	}
+
+	if err := providerClient.CheckPublicHost(target.Hostname()); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+func (s *WebhookSender) secretFor(channel string) string {
+	if secret, ok := s.secrets[strings.ToLower(channel)]; ok {
+		return secret
+	}
+	return s.defaultSecret
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>", receivers
+// recompute it with the shared secret and reject stale timestamps.
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
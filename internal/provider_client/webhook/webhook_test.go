@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAcquireTimeoutDefers(t *testing.T) {
+	deps := providerClient.NewDeps(
+		logger.NewAppLogger(&logger.Config{Level: "FATAL"}),
+		&config.Config{Project: &config.Project{ServiceName: "dispatch-service"}},
+		trace.NewNoopTracerProvider().Tracer("test"),
+	)
+	sender, err := NewSender(deps, &config.WebhookProvider{
+		Timeout:        "1s",
+		AcquireTimeout: "10ms",
+		MaxAttempts:    "1",
+		MaxConcurrency: "1",
+	}, &http.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release, err := sender.acquire(context.Background(), "partner.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// The only slot is taken, the next message must be deferred rather than
+	// failed.
+	_, err = sender.acquire(context.Background(), "partner.example.com")
+	if after, ok := providerClient.RetryAfter(err); !ok || after != providerClient.DefaultRetryAfter {
+		t.Fatalf("err = %v with retry after %s, want %s", err, after, providerClient.DefaultRetryAfter)
+	}
+
+	// Other destinations are not blocked.
+	releaseOther, err := sender.acquire(context.Background(), "other.example.com")
+	if err != nil {
+		t.Fatalf("other destination: %v", err)
+	}
+	releaseOther()
+}
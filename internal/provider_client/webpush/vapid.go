@@ -0,0 +1,97 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/jws"
+)
+
+// Push services accept VAPID tokens valid for at most 24 hours, tokens are
+// issued for 12 and reused per push service origin until close to expiry.
+const (
+	vapidTokenLifetime = 12 * time.Hour
+	vapidTokenRenewal  = 1 * time.Hour
+)
+
+type vapidToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+type vapidSigner struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+
+	mu     sync.Mutex
+	tokens map[string]vapidToken
+}
+
+func newVapidSigner(publicKey string, privateKey string, subject string) (*vapidSigner, error) {
+	publicBytes, err := decodeBase64(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid public key: %w", err)
+	}
+
+	privateBytes, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+	if len(privateBytes) != 32 {
+		return nil, errors.New("vapid private key must be 32 bytes")
+	}
+
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(privateBytes)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(privateBytes)
+
+	if string(elliptic.Marshal(curve, key.PublicKey.X, key.PublicKey.Y)) != string(publicBytes) {
+		return nil, errors.New("vapid public key does not match the private key")
+	}
+
+	return &vapidSigner{
+		key:       key,
+		publicKey: publicKey,
+		subject:   subject,
+		tokens:    make(map[string]vapidToken),
+	}, nil
+}
+
+// Authorization returns the RFC 8292 header value for a subscription endpoint.
+func (v *vapidSigner) Authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	audience := u.Scheme + "://" + u.Host
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	cached, ok := v.tokens[audience]
+	if !ok || time.Until(cached.expiresAt) < vapidTokenRenewal {
+		expiresAt := time.Now().Add(vapidTokenLifetime)
+
+		token, err := jws.SignES256(v.key, nil, map[string]interface{}{
+			"aud": audience,
+			"exp": expiresAt.Unix(),
+			"sub": v.subject,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		cached = vapidToken{token: token, expiresAt: expiresAt}
+		v.tokens[audience] = cached
+	}
+
+	return "vapid t=" + cached.token + ", k=" + v.publicKey, nil
+}
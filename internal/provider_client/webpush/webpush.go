@@ -0,0 +1,270 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+)
+
+const (
+	reasonExpired = "SubscriptionExpired"
+)
+
+func init() {
+	providerClient.RegisterWebPushSender(constants.PROVIDER_VAPID, NewWebPushSender)
+}
+
+type WebPushSender struct {
+	deps        *providerClient.Deps
+	client      *http.Client
+	vapid       *vapidSigner
+	timeToLive  time.Duration
+	concurrency int
+}
+
+func NewWebPushSender(deps *providerClient.Deps) (providerClient.WebPushSender, error) {
+	return NewSender(deps, deps.Cfg.ProviderClient.WebPushProvider, deps.NewPublicHttpClient())
+}
+
+// NewSender builds the sender from an explicit config and client. The client
+// is expected to refuse internal addresses like NewPublicHttpClient does.
+func NewSender(deps *providerClient.Deps, cfg *config.WebPushProvider, client *http.Client) (*WebPushSender, error) {
+	if cfg == nil || cfg.VapidPublicKey == "" || cfg.VapidPrivateKey == "" {
+		return nil, errors.New("vapid keys are not configured")
+	}
+
+	vapid, err := newVapidSigner(cfg.VapidPublicKey, cfg.VapidPrivateKey, cfg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	timeToLive, err := time.ParseDuration(cfg.TimeToLive)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webpush time to live: %w", err)
+	}
+
+	concurrency, err := strconv.Atoi(cfg.Concurrency)
+	if err != nil || concurrency < 1 {
+		return nil, fmt.Errorf("invalid webpush concurrency %q", cfg.Concurrency)
+	}
+
+	return &WebPushSender{
+		deps:        deps,
+		client:      client,
+		vapid:       vapid,
+		timeToLive:  timeToLive,
+		concurrency: concurrency,
+	}, nil
+}
+
+func (s *WebPushSender) Name() string {
+	return constants.PROVIDER_VAPID
+}
+
+type notificationPayload struct {
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	Icon  string                 `json:"icon,omitempty"`
+	Url   string                 `json:"url,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// SendWebPush delivers to every subscription. Subscriptions answered with
+// 404 or 410 are reported in InvalidRecipients, the push fails only when no
+// subscription accepted it. Otherwise the endpoints that failed with a
+// retryable error are returned in a PartialFailure.
+func (s *WebPushSender) SendWebPush(ctx context.Context, webpushMsg *model.WebPush) (*providerClient.DeliveryResult, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendWebPush")
+	defer span.End()
+
+	if len(webpushMsg.Subscriptions) == 0 {
+		return nil, tracerClient.RecordError(span, &providerClient.PermanentError{Err: errors.New("webpush has no subscription")})
+	}
+
+	payload, err := json.Marshal(&notificationPayload{
+		Title: webpushMsg.Title,
+		Body:  webpushMsg.Body,
+		Icon:  webpushMsg.Icon,
+		Url:   webpushMsg.Url,
+		Data:  webpushMsg.Data,
+	})
+	if err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	timeToLive := s.timeToLive
+	if webpushMsg.TimeToLive > 0 {
+		timeToLive = time.Duration(webpushMsg.TimeToLive) * time.Second
+	}
+
+	urgency := "normal"
+	if s.deps.Priority(ctx) == "high" {
+		urgency = "high"
+	}
+
+	result := &providerClient.DeliveryResult{
+		Provider:          constants.PROVIDER_VAPID,
+		InvalidRecipients: make(map[string]string),
+	}
+
+	var (
+		mu             sync.Mutex
+		wg             sync.WaitGroup
+		errs           []error
+		retryEndpoints []string
+		retryErrs      []error
+		semaphore      = make(chan struct{}, s.concurrency)
+	)
+
+	for _, subscription := range webpushMsg.Subscriptions {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(subscription model.WebPushSubscription) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			location, err := s.send(ctx, subscription, payload, timeToLive, urgency, webpushMsg.Topic)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var statusErr *statusError
+			switch {
+			case err == nil:
+				result.Succeeded++
+				if result.MessageID == "" {
+					result.MessageID = location
+				}
+			case errors.As(err, &statusErr) && statusErr.expired():
+				result.InvalidRecipients[subscription.Endpoint] = reasonExpired
+				errs = append(errs, err)
+			case providerClient.IsRetryable(err):
+				result.Failed++
+				errs = append(errs, err)
+				retryEndpoints = append(retryEndpoints, subscription.Endpoint)
+				retryErrs = append(retryErrs, err)
+			default:
+				result.Failed++
+				errs = append(errs, err)
+			}
+		}(subscription)
+	}
+	wg.Wait()
+
+	result.ResultCode = strconv.Itoa(http.StatusCreated)
+	result.Message = fmt.Sprintf("%d accepted, %d rejected", result.Succeeded, len(errs))
+
+	if result.Succeeded == 0 {
+		return result, tracerClient.RecordError(span, errors.Join(errs...))
+	}
+
+	for _, err := range errs {
+		s.deps.LogRestMessage(ctx, "", constants.METHOD_POST, webpushMsg, err, "Subscription rejected")
+	}
+
+	if len(retryEndpoints) > 0 {
+		return result, tracerClient.RecordError(span, providerClient.NewPartialFailure(retryEndpoints, retryErrs))
+	}
+
+	return result, nil
+}
+
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *statusError) expired() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
+
+func (s *WebPushSender) send(ctx context.Context, subscription model.WebPushSubscription, payload []byte, timeToLive time.Duration, urgency string, topic string) (string, error) {
+	if err := validateEndpoint(subscription.Endpoint); err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	body, err := encrypt(payload, subscription.Keys.P256dh, subscription.Keys.Auth)
+	if err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	authorization, err := s.vapid.Authorization(subscription.Endpoint)
+	if err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", &providerClient.PermanentError{Err: err}
+	}
+
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+	httpReq.Header.Set("Content-Encoding", "aes128gcm")
+	httpReq.Header.Set("TTL", strconv.Itoa(int(timeToLive.Seconds())))
+	httpReq.Header.Set("Urgency", urgency)
+	httpReq.Header.Set("Authorization", authorization)
+	if topic != "" {
+		httpReq.Header.Set("Topic", topic)
+	}
+
+	httpRes, err := s.client.Do(httpReq)
+	if err != nil {
+		s.deps.LogRestMessage(ctx, subscription.Endpoint, constants.METHOD_POST, nil, err, "Get http result")
+		return "", providerClient.RequestError(err)
+	}
+	defer httpRes.Body.Close()
+
+	httpResBody, _ := io.ReadAll(io.LimitReader(httpRes.Body, 4096))
+
+	if httpRes.StatusCode >= http.StatusOK && httpRes.StatusCode < http.StatusMultipleChoices {
+		return httpRes.Header.Get("Location"), nil
+	}
+
+	statusErr := &statusError{StatusCode: httpRes.StatusCode, Body: string(httpResBody)}
+	switch {
+	case httpRes.StatusCode == http.StatusTooManyRequests || httpRes.StatusCode == http.StatusServiceUnavailable:
+		return "", providerClient.RateLimited(httpRes, statusErr)
+	case httpRes.StatusCode >= http.StatusInternalServerError:
+		return "", &providerClient.RetryableError{Err: statusErr}
+	}
+
+	return "", &providerClient.PermanentError{Err: statusErr}
+}
+
+// validateEndpoint accepts only https push service urls, subscriptions come
+// from browsers and are never plain http.
+func validateEndpoint(endpoint string) error {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid webpush endpoint: %w", err)
+	}
+
+	if target.Scheme != "https" {
+		return fmt.Errorf("webpush endpoint scheme %q is not allowed", target.Scheme)
+	}
+
+	if target.Host == "" {
+		return errors.New("webpush endpoint has no host")
+	}
+
+	return providerClient.CheckPublicHost(target.Hostname())
+}
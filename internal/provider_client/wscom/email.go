@@ -1,4 +1,4 @@
-package provider_client
+package wscom
 
 import (
 	"context"
@@ -9,9 +9,54 @@ import (
 	"strings"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const (
+	replyCfgNoReply = "noreply"
 )
 
+func init() {
+	providerClient.RegisterEmailSender(constants.PROVIDER_WSCOM, NewEmailSender)
+}
+
+type EmailSender struct {
+	deps *providerClient.Deps
+}
+
+func NewEmailSender(deps *providerClient.Deps) (providerClient.EmailSender, error) {
+	return &EmailSender{
+		deps: deps,
+	}, nil
+}
+
+func (s *EmailSender) Name() string {
+	return constants.PROVIDER_WSCOM
+}
+
+func (s *EmailSender) SendEmail(ctx context.Context, emailMsg *model.Email) (*providerClient.DeliveryResult, error) {
+	msg, kode, err := s.send(ctx, replyCfgNoReply, emailMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &providerClient.DeliveryResult{
+		Provider:   constants.PROVIDER_WSCOM,
+		ResultCode: kode,
+		Message:    msg,
+	}
+
+	if !strings.EqualFold(msg, constants.Success) {
+		return result, fmt.Errorf("send email failed with message: %s", msg)
+	}
+
+	return result, nil
+}
+
 type SendEmailEnvelopeReq struct {
 	XMLName  xml.Name         `xml:"x:Envelope"`
 	XmlnsX   string           `xml:"xmlns:x,attr"`
@@ -40,20 +85,20 @@ type SendEmailReq struct {
 	Avl        string `xml:"bpj:avl"`
 }
 
-func (pc *ProviderClient) SendEmail(ctx context.Context, replyCfg string, emailMsg *model.Email) (string, string, error) {
-	_, span := pc.tracer.Start(ctx, "ProviderClient.SendEmail")
+func (s *EmailSender) send(ctx context.Context, replyCfg string, emailMsg *model.Email) (string, string, error) {
+	ctx, span := s.deps.Tracer.Start(ctx, "ProviderClient.SendEmail")
 	defer span.End()
 
-	client := pc.NewHttpClient()
+	client := s.deps.NewHttpClient()
 
-	xmlReq := getEmailRequestXml(replyCfg, pc.cfg.ProviderClient.EmailProvider.From, emailMsg)
+	xmlReq := getEmailRequestXml(replyCfg, s.deps.Cfg.ProviderClient.EmailProvider.From, emailMsg)
 
 	httpReqBody := strings.NewReader(xmlReq)
 
-	httpReq, err := http.NewRequest(constants.METHOD_POST, pc.cfg.ProviderClient.EmailProvider.Url, httpReqBody)
+	httpReq, err := http.NewRequestWithContext(ctx, constants.METHOD_POST, s.deps.Cfg.ProviderClient.EmailProvider.Url, httpReqBody)
 	if err != nil {
-		pc.logRestMessage(ctx, pc.cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Create new request")
-		return "", "", err
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Create new request")
+		return "", "", tracerClient.RecordError(span, err)
 	}
 
 	httpReq.Header.Add("Content-Type", "text/xml; charset=utf-8")
@@ -61,20 +106,23 @@ func (pc *ProviderClient) SendEmail(ctx context.Context, replyCfg string, emailM
 
 	httpRes, err := client.Do(httpReq)
 	if err != nil {
-		pc.logRestMessage(ctx, pc.cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Get http result")
-		return "", "", err
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Get http result")
+		return "", "", tracerClient.RecordError(span, err)
 	}
 	defer httpRes.Body.Close()
 
 	httpResBody, err := io.ReadAll(httpRes.Body)
 	if err != nil {
-		pc.logRestMessage(ctx, pc.cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Get result body")
-		return "", "", err
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, emailMsg, err, "Get result body")
+		return "", "", tracerClient.RecordError(span, err)
 	}
 
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpRes.StatusCode))
+
 	if httpRes.StatusCode != http.StatusOK {
-		pc.logRestMessage(ctx, pc.cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, string(httpResBody), fmt.Errorf("status code %v", httpRes.StatusCode), "Check HTTP result code")
-		return "", "", err
+		err = providerClient.RateLimited(httpRes, fmt.Errorf("status code %v", httpRes.StatusCode))
+		s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, string(httpResBody), err, "Check HTTP result code")
+		return "", "", tracerClient.RecordError(span, err)
 	}
 
 	resMsg := strings.Split(string(httpResBody), "<ax21:msg>")
@@ -85,7 +133,7 @@ func (pc *ProviderClient) SendEmail(ctx context.Context, replyCfg string, emailM
 	resKode = strings.Split(resKode[1], "</ax21:kode>")
 	kode := resKode[0]
 
-	pc.logRestMessage(ctx, pc.cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, string(httpResBody), nil, "Success to send email request")
+	s.deps.LogRestMessage(ctx, s.deps.Cfg.ProviderClient.EmailProvider.Url, constants.METHOD_POST, string(httpResBody), nil, "Success to send email request")
 
 	return msg, kode, nil
 }
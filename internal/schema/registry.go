@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultVersion applies to envelopes sent before schema_version existed.
+const DefaultVersion = 1
+
+//go:embed schemas
+var files embed.FS
+
+// Registry holds the envelope schema and every payload schema version found
+// under schemas/<category>/v<N>.json. Producers pick the version per message,
+// so old and new versions are accepted side by side during a migration.
+type Registry struct {
+	envelope *Schema
+	payloads map[string]map[int]*Schema
+}
+
+func NewRegistry() (*Registry, error) {
+	r := &Registry{payloads: make(map[string]map[int]*Schema)}
+
+	envelope, err := load("schemas/envelope.json")
+	if err != nil {
+		return nil, err
+	}
+	r.envelope = envelope
+
+	paths, err := fs.Glob(files, "schemas/*/v*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		category := path.Base(path.Dir(p))
+		version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path.Base(p), "v"), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: bad version in file name", p)
+		}
+
+		schema, err := load(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.payloads[category] == nil {
+			r.payloads[category] = make(map[int]*Schema)
+		}
+		r.payloads[category][version] = schema
+	}
+
+	return r, nil
+}
+
+func load(p string) (*Schema, error) {
+	b, err := files.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	if err := json.Unmarshal(b, schema); err != nil {
+		return nil, fmt.Errorf("schema %s: %w", p, err)
+	}
+
+	return schema, nil
+}
+
+func (r *Registry) ValidateEnvelope(b []byte) []FieldError {
+	return r.envelope.Validate(b)
+}
+
+// ValidatePayload checks the payload of category against the given schema
+// version, 0 meaning DefaultVersion. Paths are relative to the payload.
+func (r *Registry) ValidatePayload(category string, version int, b []byte) []FieldError {
+	if version == 0 {
+		version = DefaultVersion
+	}
+
+	versions, ok := r.payloads[category]
+	if !ok {
+		return []FieldError{{Path: "/category_name", Keyword: "schema", Message: fmt.Sprintf("no schema for category %q", category)}}
+	}
+
+	schema, ok := versions[version]
+	if !ok {
+		return []FieldError{{
+			Path:    "/schema_version",
+			Keyword: "schema",
+			Message: fmt.Sprintf("version %d is not supported for %s, supported versions are %s", version, category, joinInts(r.Versions(category))),
+		}}
+	}
+
+	return schema.Validate(b)
+}
+
+// Versions lists the schema versions known for category in ascending order.
+func (r *Registry) Versions(category string) []int {
+	versions := make([]int, 0, len(r.payloads[category]))
+	for version := range r.payloads[category] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	return versions
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,328 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema used by the payload definitions: type,
+// properties, required, additionalProperties, items, enum, anyOf, string,
+// number and array bounds, pattern and the email and uri formats.
+type Schema struct {
+	Type                 typeList           `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Format               string             `json:"format"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+
+	pattern *regexp.Regexp
+	// never is the false schema, nothing validates against it.
+	never bool
+}
+
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*t = list
+
+	return nil
+}
+
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+
+	type plain Schema
+	if err := json.Unmarshal(b, (*plain)(s)); err != nil {
+		return err
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+
+	return nil
+}
+
+// FieldError is one failed rule, Path is the JSON pointer of the value.
+type FieldError struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// Validate checks the JSON document b against s. A document that is not JSON
+// is reported as a single error at the root.
+func (s *Schema) Validate(b []byte) []FieldError {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []FieldError{{Keyword: "syntax", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var errs []FieldError
+	s.validate("", value, &errs)
+
+	return errs
+}
+
+func (s *Schema) validate(path string, value interface{}, errs *[]FieldError) {
+	if s.never {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "false", Message: "is not allowed"})
+		return
+	}
+
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		*errs = append(*errs, FieldError{
+			Path:    path,
+			Keyword: "type",
+			Message: fmt.Sprintf("must be %s, got %s", strings.Join(s.Type, " or "), typeOf(value)),
+		})
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "enum", Message: fmt.Sprintf("must be one of %s", formatEnum(s.Enum))})
+	}
+
+	if len(s.AnyOf) > 0 {
+		// Each failed form is spelled out so the sender sees what either needs.
+		var forms []string
+		for _, sub := range s.AnyOf {
+			var subErrs []FieldError
+			sub.validate(path, value, &subErrs)
+			if len(subErrs) == 0 {
+				forms = nil
+				break
+			}
+
+			reasons := make([]string, len(subErrs))
+			for i, subErr := range subErrs {
+				reasons[i] = subErr.String()
+			}
+			forms = append(forms, strings.Join(reasons, ", "))
+		}
+		if forms != nil {
+			*errs = append(*errs, FieldError{Path: path, Keyword: "anyOf", Message: "must satisfy one of: " + strings.Join(forms, " | ")})
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(path, v, errs)
+	case json.Number:
+		s.validateNumber(path, v, errs)
+	case []interface{}:
+		s.validateArray(path, v, errs)
+	case map[string]interface{}:
+		s.validateObject(path, v, errs)
+	}
+}
+
+func (s *Schema) validateString(path string, v string, errs *[]FieldError) {
+	length := utf8.RuneCountInString(v)
+
+	if s.MinLength != nil && length < *s.MinLength {
+		message := fmt.Sprintf("must be at least %d characters", *s.MinLength)
+		if *s.MinLength == 1 {
+			message = "must not be empty"
+		}
+		*errs = append(*errs, FieldError{Path: path, Keyword: "minLength", Message: message})
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "maxLength", Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "pattern", Message: fmt.Sprintf("must match %s", s.Pattern)})
+	}
+
+	switch s.Format {
+	case "email":
+		if _, err := mail.ParseAddress(v); err != nil {
+			*errs = append(*errs, FieldError{Path: path, Keyword: "format", Message: "must be an email address"})
+		}
+	case "uri":
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+			*errs = append(*errs, FieldError{Path: path, Keyword: "format", Message: "must be an absolute URI"})
+		}
+	}
+}
+
+func (s *Schema) validateNumber(path string, v json.Number, errs *[]FieldError) {
+	n, err := v.Float64()
+	if err != nil {
+		return
+	}
+
+	if s.Minimum != nil && n < *s.Minimum {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "minimum", Message: fmt.Sprintf("must be at least %v", *s.Minimum)})
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "maximum", Message: fmt.Sprintf("must be at most %v", *s.Maximum)})
+	}
+}
+
+func (s *Schema) validateArray(path string, v []interface{}, errs *[]FieldError) {
+	if s.MinItems != nil && len(v) < *s.MinItems {
+		message := fmt.Sprintf("must have at least %d items", *s.MinItems)
+		if *s.MinItems == 1 {
+			message = "must not be empty"
+		}
+		*errs = append(*errs, FieldError{Path: path, Keyword: "minItems", Message: message})
+	}
+	if s.MaxItems != nil && len(v) > *s.MaxItems {
+		*errs = append(*errs, FieldError{Path: path, Keyword: "maxItems", Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+	}
+
+	if s.Items != nil {
+		for i, item := range v {
+			s.Items.validate(path+"/"+strconv.Itoa(i), item, errs)
+		}
+	}
+}
+
+func (s *Schema) validateObject(path string, v map[string]interface{}, errs *[]FieldError) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, FieldError{Path: path + "/" + escapePointer(name), Keyword: "required", Message: "is required"})
+		}
+	}
+
+	// Sorted so the reported errors are stable.
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childPath := path + "/" + escapePointer(name)
+
+		if property, ok := s.Properties[name]; ok {
+			property.validate(childPath, v[name], errs)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.never {
+				*errs = append(*errs, FieldError{Path: childPath, Keyword: "additionalProperties", Message: "is not a known field"})
+				continue
+			}
+			s.AdditionalProperties.validate(childPath, v[name], errs)
+		}
+	}
+}
+
+func (s *Schema) matchesType(value interface{}) bool {
+	actual := typeOf(value)
+
+	for _, t := range s.Type {
+		if t == actual {
+			return true
+		}
+		if t == "number" && actual == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) inEnum(value interface{}) bool {
+	for _, allowed := range s.Enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) && typeOf(normalize(allowed)) == typeOf(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize turns numbers decoded without UseNumber into json.Number.
+func normalize(v interface{}) interface{} {
+	if f, ok := v.(float64); ok {
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return v
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatEnum(values []interface{}) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		b, _ := json.Marshal(v)
+		parts = append(parts, string(b))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,258 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func mustSchema(t *testing.T, doc string) *Schema {
+	t.Helper()
+
+	s := &Schema{}
+	if err := json.Unmarshal([]byte(doc), s); err != nil {
+		t.Fatalf("schema %s: %v", doc, err)
+	}
+	return s
+}
+
+func TestValidateKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []FieldError
+	}{
+		{
+			name:   "syntax",
+			schema: `{}`,
+			value:  `{"a":`,
+			want:   []FieldError{{Keyword: "syntax", Message: "invalid JSON: unexpected EOF"}},
+		},
+		{
+			name:   "false schema",
+			schema: `false`,
+			value:  `1`,
+			want:   []FieldError{{Keyword: "false", Message: "is not allowed"}},
+		},
+		{
+			name:   "true schema",
+			schema: `true`,
+			value:  `{"a":[1,"b",null]}`,
+		},
+		{
+			name:   "type",
+			schema: `{"type":"string"}`,
+			value:  `12`,
+			want:   []FieldError{{Keyword: "type", Message: "must be string, got integer"}},
+		},
+		{
+			name:   "type list",
+			schema: `{"type":["string","null"]}`,
+			value:  `null`,
+		},
+		{
+			name:   "integer is a number",
+			schema: `{"type":"number"}`,
+			value:  `3`,
+		},
+		{
+			name:   "fraction is not an integer",
+			schema: `{"type":"integer"}`,
+			value:  `3.5`,
+			want:   []FieldError{{Keyword: "type", Message: "must be integer, got number"}},
+		},
+		{
+			name:   "enum",
+			schema: `{"enum":["high","regular"]}`,
+			value:  `"low"`,
+			want:   []FieldError{{Keyword: "enum", Message: `must be one of "high", "regular"`}},
+		},
+		{
+			name:   "enum compares types",
+			schema: `{"enum":[1]}`,
+			value:  `"1"`,
+			want:   []FieldError{{Keyword: "enum", Message: "must be one of 1"}},
+		},
+		{
+			name:   "enum number",
+			schema: `{"enum":[1,2]}`,
+			value:  `2`,
+		},
+		{
+			name:   "anyOf",
+			schema: `{"anyOf":[{"type":"string"},{"type":"array","minItems":1}]}`,
+			value:  `[]`,
+			want:   []FieldError{{Keyword: "anyOf", Message: "must satisfy one of: /: must be string, got array | /: must not be empty"}},
+		},
+		{
+			name:   "anyOf second form",
+			schema: `{"anyOf":[{"type":"string"},{"type":"array","minItems":1}]}`,
+			value:  `["a"]`,
+		},
+		{
+			name:   "minLength",
+			schema: `{"minLength":3}`,
+			value:  `"ab"`,
+			want:   []FieldError{{Keyword: "minLength", Message: "must be at least 3 characters"}},
+		},
+		{
+			name:   "minLength one",
+			schema: `{"minLength":1}`,
+			value:  `""`,
+			want:   []FieldError{{Keyword: "minLength", Message: "must not be empty"}},
+		},
+		{
+			name:   "maxLength counts runes",
+			schema: `{"maxLength":2}`,
+			value:  `"éé"`,
+		},
+		{
+			name:   "maxLength",
+			schema: `{"maxLength":2}`,
+			value:  `"abc"`,
+			want:   []FieldError{{Keyword: "maxLength", Message: "must be at most 2 characters"}},
+		},
+		{
+			name:   "pattern",
+			schema: `{"pattern":"^[a-z]+$"}`,
+			value:  `"ab1"`,
+			want:   []FieldError{{Keyword: "pattern", Message: "must match ^[a-z]+$"}},
+		},
+		{
+			name:   "format email",
+			schema: `{"format":"email"}`,
+			value:  `"not an address"`,
+			want:   []FieldError{{Keyword: "format", Message: "must be an email address"}},
+		},
+		{
+			name:   "format email valid",
+			schema: `{"format":"email"}`,
+			value:  `"user@example.com"`,
+		},
+		{
+			name:   "format uri",
+			schema: `{"format":"uri"}`,
+			value:  `"/relative/path"`,
+			want:   []FieldError{{Keyword: "format", Message: "must be an absolute URI"}},
+		},
+		{
+			name:   "format uri valid",
+			schema: `{"format":"uri"}`,
+			value:  `"https://example.com/a"`,
+		},
+		{
+			name:   "minimum",
+			schema: `{"minimum":1}`,
+			value:  `0.5`,
+			want:   []FieldError{{Keyword: "minimum", Message: "must be at least 1"}},
+		},
+		{
+			name:   "maximum",
+			schema: `{"maximum":10}`,
+			value:  `11`,
+			want:   []FieldError{{Keyword: "maximum", Message: "must be at most 10"}},
+		},
+		{
+			name:   "minItems",
+			schema: `{"minItems":2}`,
+			value:  `[1]`,
+			want:   []FieldError{{Keyword: "minItems", Message: "must have at least 2 items"}},
+		},
+		{
+			name:   "minItems one",
+			schema: `{"minItems":1}`,
+			value:  `[]`,
+			want:   []FieldError{{Keyword: "minItems", Message: "must not be empty"}},
+		},
+		{
+			name:   "maxItems",
+			schema: `{"maxItems":1}`,
+			value:  `[1,2]`,
+			want:   []FieldError{{Keyword: "maxItems", Message: "must have at most 1 items"}},
+		},
+		{
+			name:   "items",
+			schema: `{"items":{"type":"string"}}`,
+			value:  `["a",2]`,
+			want:   []FieldError{{Path: "/1", Keyword: "type", Message: "must be string, got integer"}},
+		},
+		{
+			name:   "required",
+			schema: `{"required":["a/b","c"]}`,
+			value:  `{"c":1}`,
+			want:   []FieldError{{Path: "/a~1b", Keyword: "required", Message: "is required"}},
+		},
+		{
+			name:   "properties",
+			schema: `{"properties":{"a":{"type":"object","properties":{"b":{"type":"boolean"}}}}}`,
+			value:  `{"a":{"b":"yes"}}`,
+			want:   []FieldError{{Path: "/a/b", Keyword: "type", Message: "must be boolean, got string"}},
+		},
+		{
+			name:   "additionalProperties false",
+			schema: `{"properties":{"a":{}},"additionalProperties":false}`,
+			value:  `{"a":1,"z":2,"b":3}`,
+			want: []FieldError{
+				{Path: "/b", Keyword: "additionalProperties", Message: "is not a known field"},
+				{Path: "/z", Keyword: "additionalProperties", Message: "is not a known field"},
+			},
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"additionalProperties":{"type":"string"}}`,
+			value:  `{"a":"x","b":1}`,
+			want:   []FieldError{{Path: "/b", Keyword: "type", Message: "must be string, got integer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustSchema(t, tt.schema).Validate([]byte(tt.value))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%s) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBadPattern(t *testing.T) {
+	s := &Schema{}
+	if err := json.Unmarshal([]byte(`{"pattern":"(["}`), s); err == nil {
+		t.Fatal("want an error for a pattern that does not compile")
+	}
+}
+
+func TestSmsPhoneNumber(t *testing.T) {
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		number string
+		valid  bool
+	}{
+		{number: "081234567890", valid: true},
+		{number: "+6281234567890", valid: true},
+		{number: "+62 812-3456-7890", valid: true},
+		{number: "0812.3456.7890", valid: true},
+		{number: "(0812) 3456 7890", valid: true},
+		{number: "+62 (812) 3456-7890", valid: true},
+		{number: "0812345", valid: false},
+		{number: "0812-3456-789x", valid: false},
+		{number: "62 812 3456 7890 ", valid: false},
+		{number: "++6281234567890", valid: false},
+		{number: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			payload, _ := json.Marshal(map[string]string{"recipient_phone_number": tt.number, "content": "hi"})
+			errs := registry.ValidatePayload("sms", 2, payload)
+			if valid := len(errs) == 0; valid != tt.valid {
+				t.Errorf("valid = %t, want %t: %v", valid, tt.valid, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package server
+
+// Provider implementations register themselves with the provider client
+// registry on import, PROVIDER_* config then selects them by name.
+import (
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/apns"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/fcm"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/onesignal"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/restsms"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/smsapps"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/smtp"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/wacloud"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/webhook"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/webpush"
+	_ "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client/wscom"
+)
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	messageProcessor "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/message_processor"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	serviceMetrics "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/service_metrics"
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
+	metricClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/metric"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	headerReplayedFrom     = "replayed_from"
+	replayProgressInterval = 5 * time.Second
+)
+
+// ReplayOptions selects the messages of a dispatch topic to send again.
+// Matching messages go through the processor unless Republish is set, then
+// they are written to TargetTopic for the running consumers instead.
+type ReplayOptions struct {
+	Topic       string
+	Partition   int
+	From        kafkaClient.Bound
+	To          kafkaClient.Bound
+	Filter      map[string]string
+	DryRun      bool
+	Republish   bool
+	TargetTopic string
+}
+
+// ReplaySummary counts the scanned messages. Every matched message ends up
+// in one of Sent, Rejected, Rescheduled or Failed, a republished message
+// counts as sent.
+type ReplaySummary struct {
+	Scanned     int
+	Matched     int
+	Sent        int
+	Rejected    int
+	Rescheduled int
+	Failed      int
+	Undecodable int
+}
+
+func (summary *ReplaySummary) count(outcome messageProcessor.Outcome) {
+	switch outcome {
+	case messageProcessor.OutcomeSent:
+		summary.Sent++
+	case messageProcessor.OutcomeRejected:
+		summary.Rejected++
+	case messageProcessor.OutcomeRescheduled:
+		summary.Rescheduled++
+	default:
+		summary.Failed++
+	}
+}
+
+// ParseReplayFilter reads "category=sms,type=Otp" into envelope fields.
+func ParseReplayFilter(s string) (map[string]string, error) {
+	filter := make(map[string]string)
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("filter %q must be key=value", entry)
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch key {
+		case "category", "type", "channel":
+		default:
+			return nil, fmt.Errorf("filter %q: key must be category, type or channel", entry)
+		}
+		filter[key] = strings.TrimSpace(value)
+	}
+
+	return filter, nil
+}
+
+// Replay reads the range of opts.Topic with standalone readers and sends the
+// matching messages again. It runs without the spool and the metric server
+// so it can run next to the service.
+func (s *Server) Replay(channel string, priority string, opts *ReplayOptions) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	if err := s.setupLogger(); err != nil {
+		return fmt.Errorf("logger setup failed: %w", err)
+	}
+
+	if err := s.setupTracer(ctx); err != nil {
+		return fmt.Errorf("tracer setup failed: %w", err)
+	}
+	defer func() {
+		if err := s.appTracer.TraceProvider.Shutdown(context.Background()); err != nil {
+			fmt.Println("Failed to shutdown tracer:", err)
+		}
+	}()
+
+	if err := s.setupReplayMetric(ctx); err != nil {
+		return fmt.Errorf("metric setup failed: %w", err)
+	}
+	defer func() {
+		if err := s.appMetric.MetricProvider.Shutdown(context.Background()); err != nil {
+			fmt.Println("Failed to shutdown metric provider:", err)
+		}
+	}()
+
+	if err := s.setupKafka(ctx); err != nil {
+		return fmt.Errorf("kafka setup failed: %w", err)
+	}
+
+	if err := s.setupSerde(); err != nil {
+		return fmt.Errorf("schema registry setup failed: %w", err)
+	}
+
+	consumerBrokers := strings.Split(s.cfg.Kafka.ConsumerBrokers, ",")
+
+	var replay func(context.Context, kafka.Message) (messageProcessor.Outcome, error)
+	switch {
+	case opts.DryRun:
+		replay = func(_ context.Context, msg kafka.Message) (messageProcessor.Outcome, error) {
+			fmt.Printf("Would replay partition %d offset %d from %s\n", msg.Partition, msg.Offset, msg.Time.Format(time.RFC3339))
+			return messageProcessor.OutcomeSent, nil
+		}
+	case opts.Republish:
+		producer := kafkaClient.NewProducer(consumerBrokers, opts.TargetTopic, s.consumerAuth)
+		defer func() {
+			if err := producer.Close(); err != nil {
+				fmt.Println("Failed to close replay producer:", err)
+			}
+		}()
+
+		replay = func(ctx context.Context, msg kafka.Message) (messageProcessor.Outcome, error) {
+			if err := producer.PublishMessage(ctx, replayedMessage(msg)); err != nil {
+				return messageProcessor.OutcomeFailed, err
+			}
+			return messageProcessor.OutcomeSent, nil
+		}
+	default:
+		producerTopics := strings.Split(s.cfg.KafkaTopic.Producer, ",")
+		producerBrokers := strings.Split(s.cfg.Kafka.ProducerBrokers, ",")
+		s.producerMap = s.createProducerMap(producerTopics, producerBrokers)
+		defer func() {
+			for _, producer := range s.producerMap {
+				if closeErr := producer.Close(); closeErr != nil {
+					fmt.Println("Failed to close Kafka producer:", closeErr)
+				}
+			}
+		}()
+
+		if err := s.setupUsecase(); err != nil {
+			return fmt.Errorf("usecase setup failed: %w", err)
+		}
+
+		processor, err := s.createMessageProcessor()
+		if err != nil {
+			return fmt.Errorf("message processor setup failed: %w", err)
+		}
+		defer processor.Close()
+
+		replay = func(ctx context.Context, msg kafka.Message) (messageProcessor.Outcome, error) {
+			return processor.ReplayMessage(ctx, msg), nil
+		}
+	}
+
+	rangeReader := kafkaClient.NewRangeReader(consumerBrokers, opts.Topic, s.consumerAuth)
+	rangeReader.Partition = opts.Partition
+
+	ranges, err := rangeReader.Plan(ctx, opts.From, opts.To)
+	if err != nil {
+		return fmt.Errorf("replay plan failed: %w", err)
+	}
+
+	total := int64(0)
+	for _, pr := range ranges {
+		fmt.Printf("Partition %d: offsets %d to %d\n", pr.Partition, pr.Start, pr.End-1)
+		total += pr.End - pr.Start
+	}
+	if opts.DryRun || opts.Republish {
+		fmt.Printf("Replaying up to %d messages of %s\n", total, opts.Topic)
+	} else {
+		fmt.Printf("Replaying up to %d messages of %s on channel %s, priority %s\n", total, opts.Topic, channel, priority)
+	}
+
+	summary := &ReplaySummary{}
+	lastProgress := time.Now()
+
+	for _, pr := range ranges {
+		err := rangeReader.Read(ctx, pr, func(msg kafka.Message) error {
+			summary.Scanned++
+
+			if time.Since(lastProgress) >= replayProgressInterval {
+				lastProgress = time.Now()
+				fmt.Printf("Progress: %d/%d scanned, partition %d at offset %d, %d matched, %d sent, %d rejected, %d rescheduled, %d failed\n",
+					summary.Scanned, total, msg.Partition, msg.Offset, summary.Matched, summary.Sent, summary.Rejected, summary.Rescheduled, summary.Failed)
+			}
+
+			matched, err := s.replayMatches(ctx, msg, opts.Filter)
+			if err != nil {
+				summary.Undecodable++
+				fmt.Printf("Skipping partition %d offset %d: %v\n", msg.Partition, msg.Offset, err)
+				return nil
+			}
+			if !matched {
+				return nil
+			}
+			summary.Matched++
+
+			outcome, err := replay(ctx, msg)
+			if err != nil {
+				fmt.Printf("Failed to replay partition %d offset %d: %v\n", msg.Partition, msg.Offset, err)
+			}
+			if !opts.DryRun {
+				summary.count(outcome)
+			}
+
+			return nil
+		})
+		if err != nil {
+			printReplaySummary(summary)
+			return fmt.Errorf("replay of partition %d failed: %w", pr.Partition, err)
+		}
+	}
+
+	printReplaySummary(summary)
+	return nil
+}
+
+func (s *Server) setupReplayMetric(ctx context.Context) error {
+	var err error
+
+	s.appMetric, err = metricClient.NewAppMetric(ctx, s.cfg.Metric, s.cfg.Project.ServiceName, s.cfg.Project.Version)
+	if err != nil {
+		return err
+	}
+
+	s.serviceMetrics = serviceMetrics.NewServiceMetrics(s.appMetric.Meter)
+	s.consumerStats = kafkaClient.NewConsumerStats(fmt.Sprintf("%s-replay-%s-%d", s.cfg.Project.ServiceName, s.cfg.Project.ServerIP, os.Getpid()))
+
+	return nil
+}
+
+// replayMatches decodes the envelope only when there is a filter to apply.
+func (s *Server) replayMatches(ctx context.Context, msg kafka.Message, filter map[string]string) (bool, error) {
+	if len(filter) == 0 {
+		return true, nil
+	}
+
+	contentType := ""
+	for _, header := range msg.Headers {
+		if header.Key == serde.ContentTypeHeader {
+			contentType = string(header.Value)
+		}
+	}
+
+	value, err := s.serde.Decode(ctx, contentType, msg.Value)
+	if err != nil {
+		return false, err
+	}
+
+	envelope := &model.ConsumedKafkaMsg{}
+	if err := json.Unmarshal(value, envelope); err != nil {
+		return false, err
+	}
+
+	fields := map[string]string{
+		"category": envelope.CategoryName,
+		"type":     envelope.TypeName,
+		"channel":  envelope.ChannelName,
+	}
+	for key, want := range filter {
+		if !strings.EqualFold(fields[key], want) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// replayedMessage copies msg for the dispatch topic, tagged with where it
+// came from and with its retry headers reset.
+func replayedMessage(msg kafka.Message) kafka.Message {
+	headers := append(messageProcessor.ResetRetryHeaders(msg.Headers), kafka.Header{
+		Key:   headerReplayedFrom,
+		Value: []byte(msg.Topic + "/" + strconv.Itoa(msg.Partition) + "/" + strconv.FormatInt(msg.Offset, 10)),
+	})
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+func printReplaySummary(summary *ReplaySummary) {
+	fmt.Printf("Replay summary: %d scanned, %d matched, %d sent, %d rejected, %d rescheduled, %d failed, %d undecodable\n",
+		summary.Scanned, summary.Matched, summary.Sent, summary.Rejected, summary.Rescheduled, summary.Failed, summary.Undecodable)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReplayFilter(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{in: "", want: map[string]string{}},
+		{in: "category=sms,type=Otp", want: map[string]string{"category": "sms", "type": "Otp"}},
+		{in: " Category = sms , CHANNEL=jmo ,", want: map[string]string{"category": "sms", "channel": "jmo"}},
+		{in: "type=a=b", want: map[string]string{"type": "a=b"}},
+		{in: "category=sms,category=email", want: map[string]string{"category": "email"}},
+		{in: "category", wantErr: true},
+		{in: "priority=high", wantErr: true},
+		{in: "category=sms,topic=x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseReplayFilter(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseReplayFilter = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReplayFilter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
 	messageProcessor "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/message_processor"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/schema"
 	serviceMetrics "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/service_metrics"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/usecase"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
 	loggerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
 	metricClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/metric"
 	metricServer "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/metric_server"
+	schemaRegistry "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/schema_registry"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/spool"
 	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
 )
 
@@ -29,6 +37,11 @@ type Server struct {
 	producerMap      map[string]*kafkaClient.Producer
 	serviceMetrics   *serviceMetrics.ServiceMetrics
 	metricServer     *metricServer.MetricServer
+	consumerStats    *kafkaClient.ConsumerStats
+	producerAuth     *kafkaClient.Auth
+	consumerAuth     *kafkaClient.Auth
+	spool            *spool.Spool
+	serde            *serde.Serde
 }
 
 func NewServer(cfg *config.Config) *Server {
@@ -57,6 +70,11 @@ func (s *Server) Run(channel string, priority string) error {
 	if err := s.setupMetric(ctx, cancel); err != nil {
 		return fmt.Errorf("metric setup failed: %w", err)
 	}
+	defer func() {
+		if err := s.appMetric.MetricProvider.Shutdown(context.Background()); err != nil {
+			fmt.Println("Failed to shutdown metric provider:", err)
+		}
+	}()
 	defer func() {
 		if err := s.metricServer.Shutdown(ctx); err != nil {
 			fmt.Println("Failed to shutdown metric server:", err)
@@ -78,13 +96,50 @@ func (s *Server) Run(channel string, priority string) error {
 		}
 	}()
 
-	s.setupUsecase()
+	if err := s.setupSpool(); err != nil {
+		return fmt.Errorf("spool setup failed: %w", err)
+	}
+	defer func() {
+		if s.spool == nil {
+			return
+		}
+		if err := s.spool.Close(); err != nil {
+			fmt.Println("Failed to close spool:", err)
+		}
+	}()
 
-	messageProcessor := s.createMessageProcessor()
-	consumerTopics := s.prepareConsumerTopics(channel, priority)
-	if err := s.startConsumers(ctx, consumerTopics, messageProcessor); err != nil {
-		fmt.Println("Consumer start failed:", err)
-		return err
+	if err := s.setupSerde(); err != nil {
+		return fmt.Errorf("schema registry setup failed: %w", err)
+	}
+
+	if err := s.setupUsecase(); err != nil {
+		return fmt.Errorf("usecase setup failed: %w", err)
+	}
+
+	if s.spool != nil {
+		flushInterval, err := time.ParseDuration(s.cfg.Spool.FlushInterval)
+		if err != nil {
+			return fmt.Errorf("spool flush interval: %w", err)
+		}
+		go s.usecase.RunSpoolFlusher(ctx, flushInterval)
+	}
+
+	messageProcessor, err := s.createMessageProcessor()
+	if err != nil {
+		return fmt.Errorf("message processor setup failed: %w", err)
+	}
+	defer messageProcessor.Close()
+	if priority == constants.PRIORITY_MIXED {
+		if err := s.startScheduledConsumers(ctx, channel, messageProcessor); err != nil {
+			fmt.Println("Consumer start failed:", err)
+			return err
+		}
+	} else {
+		consumerTopics := s.prepareConsumerTopics(channel, priority)
+		if err := s.startConsumers(ctx, consumerTopics, messageProcessor); err != nil {
+			fmt.Println("Consumer start failed:", err)
+			return err
+		}
 	}
 
 	<-ctx.Done()
@@ -92,12 +147,7 @@ func (s *Server) Run(channel string, priority string) error {
 }
 
 func (s *Server) setupLogger() error {
-	var err error
-
-	s.appLogger = loggerClient.NewAppLogger()
-	if err != nil {
-		return err
-	}
+	s.appLogger = loggerClient.NewAppLogger(s.cfg.Logger)
 
 	return nil
 }
@@ -124,6 +174,14 @@ func (s *Server) setupMetric(ctx context.Context, cancel context.CancelFunc) err
 	s.serviceMetrics = serviceMetrics.NewServiceMetrics(s.appMetric.Meter)
 	s.metricServer = metricServer.NewMetricServer(s.cfg.Metric.Path, s.cfg.Metric.Prefix)
 
+	s.consumerStats = kafkaClient.NewConsumerStats(fmt.Sprintf("%s-%s-%d", s.cfg.Project.ServiceName, s.cfg.Project.ServerIP, os.Getpid()))
+	if err := serviceMetrics.RegisterConsumerStats(s.appMetric.Meter, s.consumerStats); err != nil {
+		return err
+	}
+	s.metricServer.AddJSONHandler(s.cfg.Metric.AdminPath+"/kafka/consumer", func() interface{} {
+		return s.consumerStats.Snapshot()
+	})
+
 	go func() {
 		defer cancel()
 
@@ -136,6 +194,15 @@ func (s *Server) setupMetric(ctx context.Context, cancel context.CancelFunc) err
 }
 
 func (s *Server) setupKafka(ctx context.Context) error {
+	var err error
+
+	if s.producerAuth, err = kafkaClient.NewAuth(s.cfg.Kafka.ProducerSecurity); err != nil {
+		return fmt.Errorf("producer: %w", err)
+	}
+	if s.consumerAuth, err = kafkaClient.NewAuth(s.cfg.Kafka.ConsumerSecurity); err != nil {
+		return fmt.Errorf("consumer: %w", err)
+	}
+
 	producerBrokers := strings.Split(s.cfg.Kafka.ProducerBrokers, ",")
 	fmt.Println("Producers broker:", producerBrokers)
 
@@ -149,10 +216,75 @@ func (s *Server) setupKafka(ctx context.Context) error {
 	return nil
 }
 
-func (s *Server) setupUsecase() {
-	s.usecase = usecase.NewUsecase(s.appLogger, s.cfg, s.appTracer.Tracer, s.cfg.ProviderClient.EmailProvider.Webhook, s.producerTopicMap, s.producerMap, s.serviceMetrics)
+// setupSerde connects the schema registry used for Avro and Protobuf values.
+// It fails when tracking events need a registry that is not configured.
+func (s *Server) setupSerde() error {
+	registry, err := schemaRegistry.NewClient(s.cfg.SchemaRegistry)
+	if err != nil {
+		return err
+	}
+
+	if registry == nil && !serde.IsJSON(s.cfg.KafkaTopic.ProducerContentType) {
+		return fmt.Errorf("producer content type %s needs SCHEMA_REGISTRY_URL or SCHEMA_REGISTRY_FILE", s.cfg.KafkaTopic.ProducerContentType)
+	}
+
+	s.serde = serde.New(registry)
+	return nil
+}
+
+// setupSpool opens the spool of this channel and priority, leaving s.spool nil
+// when no directory is configured.
+func (s *Server) setupSpool() error {
+	if s.cfg.Spool == nil || s.cfg.Spool.Dir == "" {
+		return nil
+	}
+
+	maxBytes, err := strconv.ParseInt(s.cfg.Spool.MaxBytes, 10, 64)
+	if err != nil {
+		return fmt.Errorf("spool max bytes: %w", err)
+	}
+
+	segmentBytes, err := strconv.ParseInt(s.cfg.Spool.SegmentBytes, 10, 64)
+	if err != nil {
+		return fmt.Errorf("spool segment bytes: %w", err)
+	}
+
+	dir := filepath.Join(s.cfg.Spool.Dir, strings.ToLower(s.cfg.Project.Channel)+"_"+s.cfg.Project.Priority)
+	s.spool, err = spool.Open(dir, spool.Options{
+		MaxBytes:     maxBytes,
+		SegmentBytes: segmentBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	if stats := s.spool.Stats(); stats.Records > 0 {
+		fmt.Printf("Recovered %d spooled messages from %s\n", stats.Records, dir)
+	}
+
+	s.metricServer.AddJSONHandler(s.cfg.Metric.AdminPath+"/spool", func() interface{} {
+		return s.spool.Stats()
+	})
+
+	return serviceMetrics.RegisterSpoolStats(s.appMetric.Meter, s.spool)
 }
 
-func (s *Server) createMessageProcessor() *messageProcessor.MessageProcessor {
-	return messageProcessor.NewMessageProcessor(s.appLogger, s.cfg, s.usecase, s.appTracer.Tracer, s.producerTopicMap, s.serviceMetrics)
+func (s *Server) setupUsecase() error {
+	var err error
+
+	s.usecase, err = usecase.NewUsecase(s.appLogger, s.cfg, s.appTracer.Tracer, s.cfg.ProviderClient.EmailProvider.Webhook, s.producerTopicMap, s.producerMap, s.serviceMetrics, s.spool, s.serde)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) createMessageProcessor() (*messageProcessor.MessageProcessor, error) {
+	schemas, err := schema.NewRegistry()
+	if err != nil {
+		return nil, err
+	}
+
+	return messageProcessor.NewMessageProcessor(s.appLogger, s.cfg, s.usecase, s.appTracer.Tracer, s.producerTopicMap, s.serviceMetrics, s.consumerStats, s.consumerAuth, schemas, s.serde), nil
 }
@@ -2,18 +2,21 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	messageProcessor "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/message_processor"
+	serviceMetrics "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/service_metrics"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
 	"github.com/segmentio/kafka-go"
 )
 
 func (s *Server) initKafkaTopics(ctx context.Context, producerBroker string, topics []string) error {
-	conn, err := kafkaClient.NewKafkaConn(ctx, s.cfg.Kafka)
+	conn, err := kafkaClient.NewKafkaConn(ctx, s.cfg.Kafka, s.producerAuth)
 	if err != nil {
 		return err
 	}
@@ -62,26 +65,140 @@ func (s *Server) startConsumers(ctx context.Context, consumerTopics []string, me
 	consumerBrokers := strings.Split(s.cfg.Kafka.ConsumerBrokers, ",")
 	fmt.Println("Consumers broker:", consumerBrokers)
 
-	consumer := kafkaClient.NewConsumer(consumerBrokers)
+	consumer := kafkaClient.NewConsumer(consumerBrokers, s.consumerStats, s.consumerAuth)
 	poolSize, err := strconv.Atoi(s.cfg.Kafka.PoolSize)
 	if err != nil {
 		return err
 	}
 
+	statsInterval, err := time.ParseDuration(s.cfg.Kafka.StatsInterval)
+	if err != nil {
+		return err
+	}
+
+	delayTopics := s.prepareDelayTopics(consumerTopics, messageProcessor)
+
+	go s.consumerStats.Run(ctx, consumerBrokers, s.consumerAuth, s.cfg.Kafka.GroupID, append(consumerTopics, delayTopics...), statsInterval)
+
 	for _, topic := range consumerTopics {
 		go consumer.StartWorkers(ctx, s.cfg.Kafka.GroupID, topic, poolSize, messageProcessor.ProcessMessage)
 	}
+	for _, topic := range delayTopics {
+		go consumer.StartWorkers(ctx, s.cfg.Kafka.GroupID, topic, poolSize, messageProcessor.ForwardDelayed)
+	}
+
+	return nil
+}
+
+// startScheduledConsumers consumes the high and regular topics of channel with
+// one worker pool. Readers only fetch, the scheduler hands their messages to
+// workers by weight with high priority winning ties.
+func (s *Server) startScheduledConsumers(ctx context.Context, channel string, messageProcessor *messageProcessor.MessageProcessor) error {
+	consumerBrokers := strings.Split(s.cfg.Kafka.ConsumerBrokers, ",")
+	fmt.Println("Consumers broker:", consumerBrokers)
+
+	consumer := kafkaClient.NewConsumer(consumerBrokers, s.consumerStats, s.consumerAuth)
+	poolSize, err := strconv.Atoi(s.cfg.Kafka.PoolSize)
+	if err != nil {
+		return err
+	}
+
+	statsInterval, err := time.ParseDuration(s.cfg.Kafka.StatsInterval)
+	if err != nil {
+		return err
+	}
+
+	highWeight, err := strconv.Atoi(s.cfg.Scheduler.HighWeight)
+	if err != nil {
+		return fmt.Errorf("scheduler high weight: %w", err)
+	}
+
+	regularWeight, err := strconv.Atoi(s.cfg.Scheduler.RegularWeight)
+	if err != nil {
+		return fmt.Errorf("scheduler regular weight: %w", err)
+	}
+
+	if highWeight < 1 || regularWeight < 1 {
+		return errors.New("scheduler weights must be at least 1")
+	}
+
+	highTopics := s.prepareConsumerTopics(channel, "high")
+	regularTopics := s.prepareConsumerTopics(channel, "normal")
+
+	workers := poolSize * len(highTopics)
+	if s.cfg.Scheduler.Workers != "" {
+		if workers, err = strconv.Atoi(s.cfg.Scheduler.Workers); err != nil {
+			return fmt.Errorf("scheduler workers: %w", err)
+		}
+	}
+
+	scheduler := kafkaClient.NewScheduler([]kafkaClient.SchedulerClass{
+		{Name: "high", Weight: highWeight},
+		{Name: "normal", Weight: regularWeight},
+	})
+
+	if err := serviceMetrics.RegisterSchedulerStats(s.appMetric.Meter, scheduler); err != nil {
+		return err
+	}
+	s.metricServer.AddJSONHandler(s.cfg.Metric.AdminPath+"/kafka/scheduler", func() interface{} {
+		return scheduler.Snapshot()
+	})
+
+	consumerTopics := append(highTopics, regularTopics...)
+	delayTopics := s.prepareDelayTopics(consumerTopics, messageProcessor)
+
+	go s.consumerStats.Run(ctx, consumerBrokers, s.consumerAuth, s.cfg.Kafka.GroupID, append(consumerTopics, delayTopics...), statsInterval)
+
+	go scheduler.Run(ctx, workers, messageProcessor.HandleScheduledMessage)
+
+	for _, topic := range highTopics {
+		go consumer.StartWorkers(ctx, s.cfg.Kafka.GroupID, topic, poolSize, messageProcessor.ScheduleMessages(scheduler, "high"))
+	}
+	for _, topic := range regularTopics {
+		go consumer.StartWorkers(ctx, s.cfg.Kafka.GroupID, topic, poolSize, messageProcessor.ScheduleMessages(scheduler, "normal"))
+	}
+	for _, topic := range delayTopics {
+		go consumer.StartWorkers(ctx, s.cfg.Kafka.GroupID, topic, poolSize, messageProcessor.ForwardDelayed)
+	}
 
 	return nil
 }
 
+// prepareDelayTopics returns the topics rescheduled messages of the dispatch
+// topics wait on, each is consumed apart so waiting holds no dispatch worker.
+func (s *Server) prepareDelayTopics(consumerTopics []string, messageProcessor *messageProcessor.MessageProcessor) []string {
+	delayTopics := make([]string, len(consumerTopics))
+	for i, topic := range consumerTopics {
+		delayTopics[i] = messageProcessor.DelayTopic(topic)
+	}
+	return delayTopics
+}
+
 func (s *Server) createProducerMap(producerTopics []string, producerBrokers []string) map[string]*kafkaClient.Producer {
 	producerMap := make(map[string]*kafkaClient.Producer)
 
 	for _, topic := range producerTopics {
-		producer := kafkaClient.NewProducer(producerBrokers, topic)
+		producer := kafkaClient.NewProducer(producerBrokers, topic, s.producerAuth)
 
-		if strings.Contains(topic, constants.NOTIF_TYPE_EMAIL) {
+		if strings.Contains(topic, constants.NOTIF_TYPE_REJECTED) {
+			producerMap[constants.NOTIF_TYPE_REJECTED] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WHATSAPP) {
+			producerMap[constants.NOTIF_TYPE_WHATSAPP] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBHOOK) {
+			producerMap[constants.NOTIF_TYPE_WEBHOOK] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBPUSH_INVALID) {
+			producerMap[constants.NOTIF_TYPE_WEBPUSH_INVALID] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBPUSH) {
+			producerMap[constants.NOTIF_TYPE_WEBPUSH] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_PUSH_TOKEN_INVALID) {
+			producerMap[constants.NOTIF_TYPE_PUSH_TOKEN_INVALID] = producer
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_EMAIL) {
 			producerMap[constants.NOTIF_TYPE_EMAIL] = producer
 			continue
 		} else if strings.Contains(topic, constants.NOTIF_TYPE_SMS_POOL) {
@@ -106,7 +223,25 @@ func (s *Server) createTopicMap(topics []string) map[string]string {
 	topicMap := make(map[string]string)
 
 	for _, topic := range topics {
-		if strings.Contains(topic, constants.NOTIF_TYPE_EMAIL) {
+		if strings.Contains(topic, constants.NOTIF_TYPE_REJECTED) {
+			topicMap[constants.NOTIF_TYPE_REJECTED] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WHATSAPP) {
+			topicMap[constants.NOTIF_TYPE_WHATSAPP] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBHOOK) {
+			topicMap[constants.NOTIF_TYPE_WEBHOOK] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBPUSH_INVALID) {
+			topicMap[constants.NOTIF_TYPE_WEBPUSH_INVALID] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_WEBPUSH) {
+			topicMap[constants.NOTIF_TYPE_WEBPUSH] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_PUSH_TOKEN_INVALID) {
+			topicMap[constants.NOTIF_TYPE_PUSH_TOKEN_INVALID] = topic
+			continue
+		} else if strings.Contains(topic, constants.NOTIF_TYPE_EMAIL) {
 			topicMap[constants.NOTIF_TYPE_EMAIL] = topic
 			continue
 		} else if strings.Contains(topic, constants.NOTIF_TYPE_SMS_POOL) {
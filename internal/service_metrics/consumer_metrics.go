@@ -0,0 +1,80 @@
+package service_metrics
+
+import (
+	"context"
+	"strconv"
+
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func RegisterConsumerStats(meter metric.Meter, stats *kafkaClient.ConsumerStats) error {
+	partitionLag, _ := meter.Int64ObservableGauge(
+		"kafka_consumer_partition_lag",
+		metric.WithDescription("The number of uncommitted messages per topic partition"),
+	)
+
+	topicLag, _ := meter.Int64ObservableGauge(
+		"kafka_consumer_topic_lag",
+		metric.WithDescription("The number of uncommitted messages per topic"),
+	)
+
+	assignedPartitions, _ := meter.Int64ObservableGauge(
+		"kafka_consumer_assigned_partitions",
+		metric.WithDescription("The number of partitions assigned to this consumer per topic"),
+	)
+
+	fetches, _ := meter.Int64ObservableCounter(
+		"kafka_consumer_fetches",
+		metric.WithDescription("The total number of kafka fetch requests"),
+	)
+
+	messages, _ := meter.Int64ObservableCounter(
+		"kafka_consumer_messages",
+		metric.WithDescription("The total number of kafka messages fetched"),
+	)
+
+	commits, _ := meter.Int64ObservableCounter(
+		"kafka_consumer_commits",
+		metric.WithDescription("The total number of success kafka commit"),
+	)
+
+	commitErrors, _ := meter.Int64ObservableCounter(
+		"kafka_consumer_commit_errors",
+		metric.WithDescription("The total number of error kafka commit"),
+	)
+
+	rebalances, _ := meter.Int64ObservableCounter(
+		"kafka_consumer_rebalances",
+		metric.WithDescription("The total number of consumer group rebalances"),
+	)
+
+	_, err := meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		snapshot := stats.Snapshot()
+
+		for _, ts := range snapshot.Topics {
+			attrs := metric.WithAttributes(attribute.String("topic", ts.Topic))
+
+			o.ObserveInt64(topicLag, ts.Lag, attrs)
+			o.ObserveInt64(assignedPartitions, int64(ts.AssignedPartitions), attrs)
+			o.ObserveInt64(fetches, ts.Fetches, attrs)
+			o.ObserveInt64(messages, ts.Messages, attrs)
+			o.ObserveInt64(commits, ts.Commits, attrs)
+			o.ObserveInt64(commitErrors, ts.CommitErrors, attrs)
+			o.ObserveInt64(rebalances, ts.Rebalances, attrs)
+		}
+
+		for _, ps := range snapshot.Partitions {
+			o.ObserveInt64(partitionLag, ps.Lag, metric.WithAttributes(
+				attribute.String("topic", ps.Topic),
+				attribute.String("partition", strconv.Itoa(ps.Partition)),
+			))
+		}
+
+		return nil
+	}, partitionLag, topicLag, assignedPartitions, fetches, messages, commits, commitErrors, rebalances)
+
+	return err
+}
@@ -0,0 +1,41 @@
+package service_metrics
+
+import (
+	"context"
+
+	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func RegisterSchedulerStats(meter metric.Meter, scheduler *kafkaClient.Scheduler) error {
+	queueDepth, _ := meter.Int64ObservableGauge(
+		"kafka_scheduler_queue_depth",
+		metric.WithDescription("The number of fetched messages waiting for a worker per priority"),
+	)
+
+	inFlight, _ := meter.Int64ObservableGauge(
+		"kafka_scheduler_in_flight",
+		metric.WithDescription("The number of messages being processed per priority"),
+	)
+
+	handled, _ := meter.Int64ObservableCounter(
+		"kafka_scheduler_handled",
+		metric.WithDescription("The total number of messages processed per priority"),
+	)
+
+	_, err := meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, qs := range scheduler.Snapshot() {
+			attrs := metric.WithAttributes(attribute.String("priority", qs.Class))
+
+			o.ObserveInt64(queueDepth, qs.Depth, attrs)
+			o.ObserveInt64(inFlight, qs.InFlight, attrs)
+			o.ObserveInt64(handled, qs.Handled, attrs)
+		}
+
+		return nil
+	}, queueDepth, inFlight, handled)
+
+	return err
+}
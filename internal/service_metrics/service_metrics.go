@@ -5,10 +5,15 @@ import (
 )
 
 type ServiceMetrics struct {
-	SuccessKafkaConsume metric.Int64Counter
-	ErrorKafkaConsume   metric.Int64Counter
-	SuccessKafkaPublish metric.Int64Counter
-	ErrorKafkaPublish   metric.Int64Counter
+	SuccessKafkaConsume  metric.Int64Counter
+	ErrorKafkaConsume    metric.Int64Counter
+	SuccessKafkaPublish  metric.Int64Counter
+	ErrorKafkaPublish    metric.Int64Counter
+	InvalidRecipient     metric.Int64Counter
+	RejectedMessage      metric.Int64Counter
+	SmsRecipient         metric.Int64Counter
+	SmsSegmentsPredicted metric.Int64Counter
+	SmsSegmentsActual    metric.Int64Counter
 }
 
 func NewServiceMetrics(meter metric.Meter) *ServiceMetrics {
@@ -32,10 +37,40 @@ func NewServiceMetrics(meter metric.Meter) *ServiceMetrics {
 		metric.WithDescription("The total number of error kafka pulish"),
 	)
 
+	invalidRecipient, _ := meter.Int64Counter(
+		"invalid_recipient",
+		metric.WithDescription("The total number of recipients reported invalid or replaced by a provider"),
+	)
+
+	rejectedMessage, _ := meter.Int64Counter(
+		"rejected_message",
+		metric.WithDescription("The total number of consumed messages rejected by schema validation"),
+	)
+
+	smsRecipient, _ := meter.Int64Counter(
+		"sms_recipient",
+		metric.WithDescription("The total number of sms recipients by mobile operator"),
+	)
+
+	smsSegmentsPredicted, _ := meter.Int64Counter(
+		"sms_segments_predicted",
+		metric.WithDescription("The total number of sms segments predicted before sending"),
+	)
+
+	smsSegmentsActual, _ := meter.Int64Counter(
+		"sms_segments_actual",
+		metric.WithDescription("The total number of sms segments reported by the gateway"),
+	)
+
 	return &ServiceMetrics{
-		SuccessKafkaConsume: successKafkaConsume,
-		ErrorKafkaConsume:   errorKafkaConsume,
-		SuccessKafkaPublish: successKafkaPublish,
-		ErrorKafkaPublish:   errorKafkaPublish,
+		SuccessKafkaConsume:  successKafkaConsume,
+		ErrorKafkaConsume:    errorKafkaConsume,
+		SuccessKafkaPublish:  successKafkaPublish,
+		ErrorKafkaPublish:    errorKafkaPublish,
+		InvalidRecipient:     invalidRecipient,
+		RejectedMessage:      rejectedMessage,
+		SmsRecipient:         smsRecipient,
+		SmsSegmentsPredicted: smsSegmentsPredicted,
+		SmsSegmentsActual:    smsSegmentsActual,
 	}
 }
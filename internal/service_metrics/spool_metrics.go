@@ -0,0 +1,44 @@
+package service_metrics
+
+import (
+	"context"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/spool"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+func RegisterSpoolStats(meter metric.Meter, s *spool.Spool) error {
+	spoolBytes, _ := meter.Int64ObservableGauge(
+		"spool_bytes",
+		metric.WithDescription("The size on disk of tracking events waiting in the spool"),
+	)
+
+	spoolRecords, _ := meter.Int64ObservableGauge(
+		"spool_records",
+		metric.WithDescription("The number of tracking events waiting in the spool"),
+	)
+
+	spoolOldestAge, _ := meter.Float64ObservableGauge(
+		"spool_oldest_age_seconds",
+		metric.WithDescription("The age of the oldest tracking event waiting in the spool"),
+	)
+
+	_, err := meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		stats := s.Stats()
+
+		age := 0.0
+		if !stats.Oldest.IsZero() {
+			age = time.Since(stats.Oldest).Seconds()
+		}
+
+		o.ObserveInt64(spoolBytes, stats.Bytes)
+		o.ObserveInt64(spoolRecords, stats.Records)
+		o.ObserveFloat64(spoolOldestAge, age)
+
+		return nil
+	}, spoolBytes, spoolRecords, spoolOldestAge)
+
+	return err
+}
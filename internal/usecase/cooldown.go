@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"net/url"
+
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+)
+
+// sendWithCooldown skips send while key is cooling down and starts a cooldown
+// when the provider asked to back off, so the other workers wait as well.
+func (u *Usecase) sendWithCooldown(key string, send func() (*providerClient.DeliveryResult, error)) (*providerClient.DeliveryResult, error) {
+	if err := u.cooldown.Check(key); err != nil {
+		return nil, err
+	}
+
+	result, err := send()
+	u.cooldown.Observe(key, err)
+
+	return result, err
+}
+
+// webhookCooldownKey scopes webhook cooldowns to the destination host, one
+// slow subscriber must not hold back the others.
+func webhookCooldownKey(provider string, rawUrl string) string {
+	target, err := url.Parse(rawUrl)
+	if err != nil {
+		return provider
+	}
+	return provider + "/" + target.Host
+}
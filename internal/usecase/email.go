@@ -4,31 +4,34 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/utils"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (u *Usecase) HandleEmail(ctx context.Context, parentMsg *model.PublishedKafkaMsg, emailMsg *model.Email) error {
-	ctx, span := u.tracer.Start(ctx, "Usecase.HandleEmail")
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandleEmail", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
 	defer span.End()
 
 	emailMsg.Status = "created"
 	emailBytes, err := json.Marshal(emailMsg)
 	if err != nil {
-		return err
+		return tracerClient.RecordError(span, err)
 	}
 
 	parentMsg.Data = emailBytes
 
 	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_EMAIL, emailMsg); err != nil {
-		return fmt.Errorf("publish message to kafka failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
 	}
 
 	if err := u.sendEmailMessageToProvider(ctx, emailMsg); err != nil {
-		return fmt.Errorf("send message to provider failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", err))
 	}
 
 	return nil
@@ -38,32 +41,61 @@ func (u *Usecase) sendEmailMessageToProvider(ctx context.Context, emailMsg *mode
 	ctx, span := u.tracer.Start(ctx, "Usecase.sendEmailMessageToProvider")
 	defer span.End()
 
-	utils.InjectWebhook(emailMsg, u.emailWebhookUrl)
+	senders := u.sendersFor(ctx)
+	if senders.Email == nil {
+		return tracerClient.RecordError(span, ErrNoEmailProvider)
+	}
 
-	if emailMsg.Attachment != nil {
-		attachments := []string{}
+	span.SetAttributes(tracerClient.ProviderKey.String(senders.Email.Name()))
 
-		for _, url := range emailMsg.Attachment {
-			base64Data, err := utils.DownloadFileAsBase64(url)
-			if err != nil {
-				u.logKafkaMessage(ctx, emailMsg, err, "Error to download attachment")
-				continue
-			}
+	utils.InjectWebhook(emailMsg, u.emailWebhookUrl)
 
-			attachments = append(attachments, base64Data)
+	if emailMsg.Attachment != nil {
+		attachments, attachNames, errs := downloadAttachments(emailMsg.Attachment, emailMsg.AttachName, utils.DownloadFileAsBase64)
+		for _, err := range errs {
+			u.logKafkaMessage(ctx, emailMsg, err, "Error to download attachment")
 		}
 
 		emailMsg.Attachment = attachments
+		emailMsg.AttachName = attachNames
 	}
 
-	msg, _, err := u.sc.SendEmail(ctx, "noreply", emailMsg)
+	result, err := u.sendWithCooldown(senders.Email.Name(), func() (*providerClient.DeliveryResult, error) {
+		return senders.Email.SendEmail(ctx, emailMsg)
+	})
+	if result != nil {
+		setDeliveryResultSpanAttributes(span, result)
+	}
 	if err != nil {
-		return err
+		return tracerClient.RecordError(span, err)
 	}
 
-	if !strings.EqualFold(msg, "Sukses") {
-		return fmt.Errorf("send email failed with message: %s", msg)
+	return nil
+}
+
+// downloadAttachments fetches the attachment urls as base64. A failed download
+// drops its name as well, so the names stay paired with the content by index.
+func downloadAttachments(urls []string, names []string, download func(url string) (string, error)) ([]string, []string, []error) {
+	attachments := []string{}
+	var attachNames []string
+	var errs []error
+
+	for i, url := range urls {
+		base64Data, err := download(url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("attachment %d: %w", i+1, err))
+			continue
+		}
+
+		attachments = append(attachments, base64Data)
+		if len(names) > 0 {
+			name := ""
+			if i < len(names) {
+				name = names[i]
+			}
+			attachNames = append(attachNames, name)
+		}
 	}
 
-	return nil
+	return attachments, attachNames, errs
 }
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDownloadAttachments(t *testing.T) {
+	download := func(url string) (string, error) {
+		if url == "https://files/broken" {
+			return "", errors.New("status code 404")
+		}
+		return "b64:" + url, nil
+	}
+
+	tests := []struct {
+		name            string
+		urls            []string
+		names           []string
+		wantAttachments []string
+		wantNames       []string
+		wantErrs        int
+	}{
+		{
+			name:            "failing middle attachment",
+			urls:            []string{"https://files/a", "https://files/broken", "https://files/c"},
+			names:           []string{"a.pdf", "b.pdf", "c.pdf"},
+			wantAttachments: []string{"b64:https://files/a", "b64:https://files/c"},
+			wantNames:       []string{"a.pdf", "c.pdf"},
+			wantErrs:        1,
+		},
+		{
+			name:            "fewer names than attachments",
+			urls:            []string{"https://files/broken", "https://files/b", "https://files/c"},
+			names:           []string{"a.pdf", "b.pdf"},
+			wantAttachments: []string{"b64:https://files/b", "b64:https://files/c"},
+			wantNames:       []string{"b.pdf", ""},
+			wantErrs:        1,
+		},
+		{
+			name:            "no names",
+			urls:            []string{"https://files/a", "https://files/broken"},
+			wantAttachments: []string{"b64:https://files/a"},
+			wantErrs:        1,
+		},
+		{
+			name:            "all failing",
+			urls:            []string{"https://files/broken"},
+			names:           []string{"a.pdf"},
+			wantAttachments: []string{},
+			wantErrs:        1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachments, names, errs := downloadAttachments(tt.urls, tt.names, download)
+
+			if !reflect.DeepEqual(attachments, tt.wantAttachments) {
+				t.Errorf("attachments = %v, want %v", attachments, tt.wantAttachments)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("names = %q, want %q", names, tt.wantNames)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("errs = %v, want %d", errs, tt.wantErrs)
+			}
+		})
+	}
+}
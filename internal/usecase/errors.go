@@ -0,0 +1,12 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrNoEmailProvider    = errors.New("no email provider configured for this channel")
+	ErrNoSmsProvider      = errors.New("no sms provider configured for this channel")
+	ErrNoPushProvider     = errors.New("no push provider configured for this channel")
+	ErrNoWhatsAppProvider = errors.New("no whatsapp provider configured for this channel")
+	ErrNoWebhookProvider  = errors.New("no webhook provider configured for this channel")
+	ErrNoWebPushProvider  = errors.New("no webpush provider configured for this channel")
+)
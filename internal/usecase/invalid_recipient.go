@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const reasonCanonical = "Canonical"
+
+// publishInvalidRecipients emits one event per recipient the provider
+// reported as gone or replaced, and counts them per channel. Failures are
+// only logged, the notification itself has already been handled.
+func (u *Usecase) publishInvalidRecipients(ctx context.Context, parentMsg *model.PublishedKafkaMsg, messageType string, result *providerClient.DeliveryResult) {
+	detectedAt := time.Now().Format(constants.TIME_LAYOUT_FORMAT)
+
+	invalids := make([]*model.InvalidRecipient, 0, len(result.InvalidRecipients)+len(result.CanonicalRecipients))
+	for recipient, reason := range result.InvalidRecipients {
+		invalids = append(invalids, &model.InvalidRecipient{
+			Recipient: recipient,
+			Reason:    reason,
+		})
+	}
+	for recipient, canonical := range result.CanonicalRecipients {
+		invalids = append(invalids, &model.InvalidRecipient{
+			Recipient:   recipient,
+			Reason:      reasonCanonical,
+			CanonicalId: canonical,
+		})
+	}
+
+	_, hasProducer := u.producerMap[messageType]
+
+	for _, invalid := range invalids {
+		invalid.ChannelName = parentMsg.ChannelName
+		invalid.CategoryName = parentMsg.CategoryName
+		invalid.Provider = result.Provider
+		invalid.DetectedAt = detectedAt
+
+		u.serviceMetrics.InvalidRecipient.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("channel", parentMsg.ChannelName),
+			attribute.String("type", messageType),
+			attribute.String("provider", result.Provider),
+			attribute.String("reason", invalid.Reason),
+		))
+
+		if !hasProducer {
+			continue
+		}
+
+		invalidBytes, err := json.Marshal(invalid)
+		if err != nil {
+			u.logKafkaMessage(ctx, invalid, err, "Error to marshal invalid recipient")
+			continue
+		}
+
+		invalidMsg := *parentMsg
+		invalidMsg.Data = invalidBytes
+
+		_ = u.publishMessageToKafka(ctx, &invalidMsg, messageType, invalid)
+	}
+}
@@ -6,58 +6,69 @@ import (
 	"fmt"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (u *Usecase) HandlePush(ctx context.Context, parentMsg *model.PublishedKafkaMsg, pushMsg *model.Push) error {
-	ctx, span := u.tracer.Start(ctx, "Usecase.HandlePush")
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandlePush", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
 	defer span.End()
 
 	pushMsg.Status = "created"
 	pushBytes, err := json.Marshal(pushMsg)
 	if err != nil {
-		return err
+		return tracerClient.RecordError(span, err)
 	}
 
 	parentMsg.Data = pushBytes
 
 	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_PUSH, pushMsg); err != nil {
-		return fmt.Errorf("publish message to kafka failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
 	}
 
-	if _, err := u.sendPushMessageToProvider(ctx, pushMsg); err != nil {
-		return fmt.Errorf("send message to provider failed: %w", err)
+	result, err := u.sendPushMessageToProvider(ctx, parentMsg.ChannelName, pushMsg)
+	if result != nil {
+		u.publishInvalidRecipients(ctx, parentMsg, constants.NOTIF_TYPE_PUSH_TOKEN_INVALID, result)
+	}
+	if err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", err))
 	}
 
 	return nil
 }
 
-func (u *Usecase) sendPushMessageToProvider(ctx context.Context, pushMsg *model.Push) (string, error) {
+func (u *Usecase) sendPushMessageToProvider(ctx context.Context, channel string, pushMsg *model.Push) (*providerClient.DeliveryResult, error) {
 	ctx, span := u.tracer.Start(ctx, "Usecase.sendPushMessageToProvider")
 	defer span.End()
 
-	var msgId string
-	var err error
+	senders := u.sendersFor(ctx)
+	sender := senders.Push
+	if pushMsg.IsIos && senders.PushIos != nil {
+		sender = senders.PushIos
+	}
 
-	switch u.cfg.Project.Priority {
-	case "high":
-		u.logRestMessage(ctx, u.cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, pushMsg, nil, "Sending push notification to Onesignal")
+	if sender == nil {
+		return nil, tracerClient.RecordError(span, ErrNoPushProvider)
+	}
 
-		_, msgId, err = u.sc.OneSignalPush(ctx, u.cfg.ProviderClient.OneSignalPushProvider.JmoAppId, pushMsg)
-		if err != nil {
-			u.logRestMessage(ctx, u.cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, pushMsg, nil, "Error to send push notification")
-			return "", err
-		}
+	provider := sender.Name()
+	span.SetAttributes(tracerClient.ProviderKey.String(provider))
 
-	case "normal":
-		u.logRestMessage(ctx, u.cfg.ProviderClient.FcmPushProvider.Url, constants.METHOD_POST, pushMsg, nil, "Sending push notification to FCM")
+	u.logRestMessage(ctx, provider, constants.METHOD_POST, pushMsg, nil, "Sending push notification to "+provider)
 
-		_, msgId, err = u.sc.FcmPush(ctx, pushMsg)
-		if err != nil {
-			u.logRestMessage(ctx, u.cfg.ProviderClient.OneSignalPushProvider.Url, constants.METHOD_POST, pushMsg, nil, "Error to send push notification")
-			return "", err
-		}
+	result, err := u.sendWithCooldown(provider, func() (*providerClient.DeliveryResult, error) {
+		return sender.SendPush(ctx, channel, pushMsg)
+	})
+	if result != nil {
+		setDeliveryResultSpanAttributes(span, result)
+	}
+	if err != nil {
+		u.logRestMessage(ctx, provider, constants.METHOD_POST, pushMsg, err, "Error to send push notification")
+		return result, tracerClient.RecordError(span, err)
 	}
 
-	return msgId, nil
+	return result, nil
 }
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+)
+
+// PublishRejected tells the producing team why their message was dropped.
+// It fails when no rejection topic is configured.
+func (u *Usecase) PublishRejected(ctx context.Context, rejected *model.RejectedKafkaMsg) error {
+	if _, ok := u.producerMap[constants.NOTIF_TYPE_REJECTED]; !ok {
+		return fmt.Errorf("no producer for %s", constants.NOTIF_TYPE_REJECTED)
+	}
+
+	ctx, span := tracerClient.StartKafkaProducerTracerSpan(u.tracer, ctx, u.producerTopicMap[constants.NOTIF_TYPE_REJECTED], "Usecase.PublishRejected")
+	defer span.End()
+
+	md, _ := contextMd.GetMetadataFromContext(ctx)
+
+	rejectedBytes, err := json.Marshal(rejected)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	if err := u.publishOrSpool(ctx, md.TraceID, constants.NOTIF_TYPE_REJECTED, rejectedBytes, rejected); err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+	return nil
+}
@@ -4,68 +4,108 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
-	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/utils"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/phone"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (u *Usecase) HandleSMS(ctx context.Context, parentMsg *model.PublishedKafkaMsg, smsMsg *model.Sms) error {
-	ctx, span := u.tracer.Start(ctx, "Usecase.HandleSMS")
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandleSMS", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
 	defer span.End()
 
 	smsMsg.Status = "created"
 	smsBytes, err := json.Marshal(smsMsg)
 	if err != nil {
-		return err
+		return tracerClient.RecordError(span, err)
 	}
 
 	parentMsg.Data = smsBytes
 
 	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_SMS, smsMsg); err != nil {
-		return fmt.Errorf("publish message to kafka failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
 	}
 
-	kodeStruct, err := u.sendSMSMessageToProvider(ctx, smsMsg)
+	result, err := u.sendSMSMessageToProvider(ctx, parentMsg, smsMsg)
 	if err != nil {
-		return fmt.Errorf("send message to provider failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", err))
 	}
 
 	smsMsg.Status = "on process"
-	smsMsg.MessageId = kodeStruct.MsgID
+	smsMsg.MessageId = result.MessageID
 
 	smsBytes, err = json.Marshal(smsMsg)
 	if err != nil {
-		return err
+		return tracerClient.RecordError(span, err)
 	}
 
 	parentMsg.Data = smsBytes
 
 	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_SMS_POOL, smsMsg); err != nil {
-		return fmt.Errorf("publish message to kafka failed: %w", err)
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
 	}
 
 	return nil
 }
 
-func (u *Usecase) sendSMSMessageToProvider(ctx context.Context, smsMsg *model.Sms) (*model.SendSmsResCode, error) {
+func (u *Usecase) sendSMSMessageToProvider(ctx context.Context, parentMsg *model.PublishedKafkaMsg, smsMsg *model.Sms) (*providerClient.DeliveryResult, error) {
 	ctx, span := u.tracer.Start(ctx, "Usecase.sendSMSMessageToProvider")
 	defer span.End()
 
-	msg, kode, err := u.sc.SendSms(ctx, smsMsg)
+	if err := u.normalizeRecipient(ctx, smsMsg); err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	if err := u.applyLengthPolicy(ctx, parentMsg, smsMsg); err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	senders := u.sendersFor(ctx)
+	if senders.Sms == nil {
+		return nil, tracerClient.RecordError(span, ErrNoSmsProvider)
+	}
+
+	span.SetAttributes(tracerClient.ProviderKey.String(senders.Sms.Name()))
+
+	result, err := u.sendWithCooldown(senders.Sms.Name(), func() (*providerClient.DeliveryResult, error) {
+		return senders.Sms.SendSms(ctx, smsMsg)
+	})
 	if err != nil {
-		return nil, err
+		return nil, tracerClient.RecordError(span, err)
 	}
 
-	if !strings.EqualFold(msg, "SUCCESS") {
-		return nil, fmt.Errorf("send sms failed with message: %s", msg)
+	setDeliveryResultSpanAttributes(span, result)
+	u.recordActualSegments(ctx, parentMsg, result)
+
+	return result, nil
+}
+
+// normalizeRecipient rewrites the recipient in the configured phone format.
+// Numbers that are not Indonesian mobile numbers fail permanently, no gateway
+// would deliver them.
+func (u *Usecase) normalizeRecipient(ctx context.Context, smsMsg *model.Sms) error {
+	number, err := phone.Parse(smsMsg.RecipientPhoneNumber)
+
+	operator := phone.OperatorUnknown
+	if err == nil {
+		operator = number.Operator
 	}
+	trace.SpanFromContext(ctx).SetAttributes(tracerClient.OperatorKey.String(operator))
+	u.serviceMetrics.SmsRecipient.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("operator", operator),
+		attribute.Bool("valid", err == nil),
+	))
 
-	kodeStruct := model.SendSmsResCode{}
-	if err := utils.StringToStruct(kode, &kodeStruct); err != nil {
-		return nil, err
+	if err != nil {
+		return &providerClient.PermanentError{Err: err}
 	}
 
-	return &kodeStruct, nil
+	smsMsg.RecipientPhoneNumber = number.Format(u.phoneFormat)
+	return nil
 }
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	smsEncoding "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/sms_encoding"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	smsActionAllow         = "allow"
+	smsActionReject        = "reject"
+	smsActionTruncate      = "truncate"
+	smsActionTransliterate = "transliterate"
+)
+
+// smsLengthPolicy resolves the action for messages over the segment limit.
+// The most specific key wins: channel.type, then channel, then type, then
+// the bare default.
+type smsLengthPolicy struct {
+	maxSegments   int
+	defaultAction string
+	byKey         map[string]string
+}
+
+func newSmsLengthPolicy(maxSegments string, selection string) (*smsLengthPolicy, error) {
+	limit, err := strconv.Atoi(maxSegments)
+	if err != nil || limit < 1 {
+		return nil, fmt.Errorf("sms max segments %q must be a positive number", maxSegments)
+	}
+
+	p := &smsLengthPolicy{
+		maxSegments:   limit,
+		defaultAction: smsActionAllow,
+		byKey:         make(map[string]string),
+	}
+
+	for _, entry := range strings.Split(selection, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, action, found := strings.Cut(entry, "=")
+		if !found {
+			key, action = "", entry
+		}
+		action = strings.ToLower(strings.TrimSpace(action))
+
+		switch action {
+		case smsActionAllow, smsActionReject, smsActionTruncate, smsActionTransliterate:
+		default:
+			return nil, fmt.Errorf("sms length policy %q: unknown action %q", entry, action)
+		}
+
+		if !found {
+			p.defaultAction = action
+			continue
+		}
+		p.byKey[strings.ToLower(strings.TrimSpace(key))] = action
+	}
+
+	return p, nil
+}
+
+func (p *smsLengthPolicy) action(channel string, typeName string) string {
+	channel = strings.ToLower(channel)
+	typeName = strings.ToLower(typeName)
+
+	for _, key := range []string{channel + "." + typeName, channel, typeName} {
+		if action, ok := p.byKey[key]; ok {
+			return action
+		}
+	}
+
+	return p.defaultAction
+}
+
+// applyLengthPolicy counts the segments of the message and applies the
+// policy when there are too many. Rejected messages fail permanently.
+func (u *Usecase) applyLengthPolicy(ctx context.Context, parentMsg *model.PublishedKafkaMsg, smsMsg *model.Sms) error {
+	info := smsEncoding.Analyze(smsMsg.Content)
+
+	action := smsActionAllow
+	if info.Segments > u.smsLengthPolicy.maxSegments {
+		action = u.smsLengthPolicy.action(parentMsg.ChannelName, parentMsg.TypeName)
+	}
+
+	switch action {
+	case smsActionTransliterate:
+		smsMsg.Content = smsEncoding.Transliterate(smsMsg.Content)
+		// Text that is still too long as GSM-7 is cut like truncate does.
+		smsMsg.Content = smsEncoding.Truncate(smsMsg.Content, u.smsLengthPolicy.maxSegments)
+	case smsActionTruncate:
+		smsMsg.Content = smsEncoding.Truncate(smsMsg.Content, u.smsLengthPolicy.maxSegments)
+	}
+
+	neededSegments := info.Segments
+	if action != smsActionAllow && action != smsActionReject {
+		info = smsEncoding.Analyze(smsMsg.Content)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracerClient.SmsEncodingKey.String(info.Encoding),
+		tracerClient.SmsSegmentsKey.Int(info.Segments),
+		tracerClient.SmsLengthActionKey.String(action),
+	)
+	u.serviceMetrics.SmsSegmentsPredicted.Add(ctx, int64(info.Segments), metric.WithAttributes(
+		attribute.String("channel", parentMsg.ChannelName),
+		attribute.String("type", parentMsg.TypeName),
+		attribute.String("encoding", info.Encoding),
+		attribute.String("action", action),
+	))
+
+	if action == smsActionReject {
+		return &providerClient.PermanentError{Err: fmt.Errorf("sms needs %d %s segments, the limit is %d", neededSegments, info.Encoding, u.smsLengthPolicy.maxSegments)}
+	}
+
+	return nil
+}
+
+// recordActualSegments counts the segments the gateway reports it sent.
+func (u *Usecase) recordActualSegments(ctx context.Context, parentMsg *model.PublishedKafkaMsg, result *providerClient.DeliveryResult) {
+	if result.Segments <= 0 {
+		return
+	}
+
+	u.serviceMetrics.SmsSegmentsActual.Add(ctx, int64(result.Segments), metric.WithAttributes(
+		attribute.String("channel", parentMsg.ChannelName),
+		attribute.String("type", parentMsg.TypeName),
+		attribute.String("provider", result.Provider),
+	))
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// spooledMessage is a tracking event that could not be published, kept with
+// its message type so the flusher picks the producer of the current config.
+type spooledMessage struct {
+	Type    string         `json:"type"`
+	Value   []byte         `json:"value"`
+	Headers []kafka.Header `json:"headers"`
+	Time    time.Time      `json:"time"`
+}
+
+// spoolMessage keeps kafkaMsg on disk for the flusher. It returns an error
+// when there is no spool or it refused the message.
+func (u *Usecase) spoolMessage(messageType string, kafkaMsg kafka.Message) error {
+	if u.spool == nil {
+		return fmt.Errorf("spool is disabled")
+	}
+
+	b, err := json.Marshal(&spooledMessage{
+		Type:    messageType,
+		Value:   kafkaMsg.Value,
+		Headers: kafkaMsg.Headers,
+		Time:    kafkaMsg.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.spool.Append(b)
+}
+
+// spoolPending reports whether earlier events are still spooled, new events
+// then queue behind them so tracking receives them in order.
+func (u *Usecase) spoolPending() bool {
+	return u.spool != nil && u.spool.Len() > 0
+}
+
+// RunSpoolFlusher publishes spooled events every interval until ctx is done.
+func (u *Usecase) RunSpoolFlusher(ctx context.Context, interval time.Duration) {
+	if u.spool == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		flushed, err := u.spool.Flush(func(data []byte) error {
+			return u.publishSpooled(ctx, data)
+		})
+		if flushed > 0 || err != nil {
+			u.logKafkaMessage(ctx, u.spool.Stats(), err, fmt.Sprintf("Flushed %d spooled messages", flushed))
+		}
+	}
+}
+
+func (u *Usecase) publishSpooled(ctx context.Context, data []byte) error {
+	msg := &spooledMessage{}
+	if err := json.Unmarshal(data, msg); err != nil {
+		// Unreadable records would block the spool forever.
+		u.logKafkaMessage(ctx, string(data), err, "Dropping unreadable spooled message")
+		return nil
+	}
+
+	producer, ok := u.producerMap[msg.Type]
+	if !ok {
+		u.logKafkaMessage(ctx, msg.Type, fmt.Errorf("no producer for %s", msg.Type), "Dropping spooled message")
+		return nil
+	}
+
+	if err := producer.PublishMessage(ctx, kafka.Message{
+		Value:   msg.Value,
+		Headers: msg.Headers,
+		Time:    msg.Time,
+	}); err != nil {
+		u.serviceMetrics.ErrorKafkaPublish.Add(context.Background(), 1, metric.WithAttributes())
+		return err
+	}
+
+	u.serviceMetrics.SuccessKafkaPublish.Add(context.Background(), 1, metric.WithAttributes())
+	return nil
+}
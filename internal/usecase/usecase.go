@@ -1,11 +1,20 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/config"
 	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	serviceMetrics "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/service_metrics"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
 	kafkaClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/kafka"
 	loggerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/phone"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/spool"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -14,22 +23,73 @@ type Usecase struct {
 	cfg              *config.Config
 	logger           *loggerClient.AppLogger
 	tracer           trace.Tracer
-	sc               providerClient.IProviderClient
+	senders          map[string]*providerClient.Senders
 	emailWebhookUrl  string
 	producerTopicMap map[string]string
 	producerMap      map[string]*kafkaClient.Producer
 	serviceMetrics   *serviceMetrics.ServiceMetrics
+	cooldown         *providerClient.Cooldown
+	spool            *spool.Spool
+	inlineData       bool
+	serde            *serde.Serde
+	contentType      string
+	phoneFormat      string
+	smsLengthPolicy  *smsLengthPolicy
 }
 
-func NewUsecase(logger *loggerClient.AppLogger, cfg *config.Config, tracer trace.Tracer, webhook string, producerTopicMap map[string]string, producerMap map[string]*kafkaClient.Producer, serviceMetrics *serviceMetrics.ServiceMetrics) *Usecase {
+func NewUsecase(logger *loggerClient.AppLogger, cfg *config.Config, tracer trace.Tracer, webhook string, producerTopicMap map[string]string, producerMap map[string]*kafkaClient.Producer, serviceMetrics *serviceMetrics.ServiceMetrics, eventSpool *spool.Spool, valueSerde *serde.Serde) (*Usecase, error) {
+	deps := providerClient.NewDeps(logger, cfg, tracer)
+
+	// A mixed priority process selects providers per message priority.
+	priorities := []string{cfg.Project.Priority}
+	if cfg.Project.Priority == constants.PRIORITY_MIXED {
+		priorities = []string{"high", "normal"}
+	}
+
+	senders := make(map[string]*providerClient.Senders, len(priorities))
+	for _, priority := range priorities {
+		prioritySenders, err := providerClient.NewSenders(deps, cfg.Project.Channel, priority)
+		if err != nil {
+			return nil, err
+		}
+		senders[priority] = prioritySenders
+	}
+
+	if !phone.ValidFormat(cfg.Sms.PhoneFormat) {
+		return nil, fmt.Errorf("sms phone format %q must be %s or %s", cfg.Sms.PhoneFormat, phone.FormatE164, phone.FormatLocal)
+	}
+
+	lengthPolicy, err := newSmsLengthPolicy(cfg.Sms.MaxSegments, cfg.Sms.LengthPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	inlineData, _ := strconv.ParseBool(cfg.KafkaTopic.ProducerInlineData)
+
 	return &Usecase{
 		logger:           logger,
 		cfg:              cfg,
-		sc:               providerClient.NewProviderClient(logger, cfg, tracer),
+		senders:          senders,
 		tracer:           tracer,
 		emailWebhookUrl:  webhook,
 		producerMap:      producerMap,
 		producerTopicMap: producerTopicMap,
 		serviceMetrics:   serviceMetrics,
+		cooldown:         providerClient.NewCooldown(),
+		spool:            eventSpool,
+		inlineData:       inlineData,
+		serde:            valueSerde,
+		contentType:      cfg.KafkaTopic.ProducerContentType,
+		phoneFormat:      cfg.Sms.PhoneFormat,
+		smsLengthPolicy:  lengthPolicy,
+	}, nil
+}
+
+func (u *Usecase) sendersFor(ctx context.Context) *providerClient.Senders {
+	if metadata, ok := contextMd.GetMetadataFromContext(ctx); ok {
+		if senders, ok := u.senders[metadata.Priority]; ok {
+			return senders
+		}
 	}
+	return u.senders[u.cfg.Project.Priority]
 }
@@ -3,42 +3,89 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
 	contextMd "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/context_metadata"
 	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+	schemaRegistry "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/schema_registry"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/serde"
 	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (u *Usecase) publishMessageToKafka(ctx context.Context, parentMsg *model.PublishedKafkaMsg, messageType string, childMsg interface{}) error {
-	ctx, span := u.tracer.Start(ctx, "Usecase.publishMessageToKafka")
+	ctx, span := tracerClient.StartKafkaProducerTracerSpan(u.tracer, ctx, u.producerTopicMap[messageType], "Usecase.publishMessageToKafka")
 	defer span.End()
 
+	span.SetAttributes(messageSpanAttributes(parentMsg)...)
+
 	md, _ := contextMd.GetMetadataFromContext(ctx)
 
-	msgBytes, err := json.Marshal(parentMsg)
+	var msg interface{} = parentMsg
+	if u.inlineData {
+		msg = parentMsg.Inline()
+	}
+
+	msgBytes, err := json.Marshal(msg)
 	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	if err := u.publishOrSpool(ctx, md.TraceID, messageType, msgBytes, childMsg); err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+	return nil
+}
+
+// publishOrSpool publishes msgBytes to the topic of messageType, falling back to
+// the spool so no tracking event is lost while the brokers are unreachable.
+// msgBytes is converted to the producer content type first.
+func (u *Usecase) publishOrSpool(ctx context.Context, traceID string, messageType string, msgBytes []byte, childMsg interface{}) error {
+	value, err := u.serde.Encode(ctx, u.contentType, schemaRegistry.Subject(u.producerTopicMap[messageType]), msgBytes)
+	if err != nil {
+		u.logKafkaMessage(ctx, childMsg, err, "Error to encode message")
 		return err
 	}
 
 	kafkaMsg := kafka.Message{
-		Value: msgBytes,
+		Value: value,
 		Time:  time.Now().UTC(),
 	}
 
 	tracerClient.InjectKafkaTracingHeadersToCarrier(ctx, &kafkaMsg.Headers)
 
-	injectTraceIDToKafkaHeaders(&kafkaMsg.Headers, md.TraceID)
+	injectTraceIDToKafkaHeaders(&kafkaMsg.Headers, traceID)
+
+	if !serde.IsJSON(u.contentType) {
+		kafkaMsg.Headers = append(kafkaMsg.Headers, kafka.Header{Key: serde.ContentTypeHeader, Value: []byte(u.contentType)})
+	}
+
+	if u.spoolPending() {
+		if err := u.spoolMessage(messageType, kafkaMsg); err != nil {
+			u.logKafkaMessage(ctx, childMsg, err, "Error to spool message")
+			return err
+		}
+		u.logKafkaMessage(ctx, childMsg, nil, "Message spooled behind earlier messages")
+		return nil
+	}
 
 	if err := u.producerMap[messageType].PublishMessage(ctx, kafkaMsg); err != nil {
 		u.serviceMetrics.ErrorKafkaPublish.Add(context.Background(), 1, metric.WithAttributes())
 		u.logKafkaMessage(ctx, childMsg, err, "Error to publish message")
-		return err
+
+		if spoolErr := u.spoolMessage(messageType, kafkaMsg); spoolErr != nil {
+			return errors.Join(err, spoolErr)
+		}
+		u.logKafkaMessage(ctx, childMsg, nil, "Message spooled after publish failure")
+		return nil
 	}
 
 	u.serviceMetrics.SuccessKafkaPublish.Add(context.Background(), 1, metric.WithAttributes())
@@ -46,6 +93,29 @@ func (u *Usecase) publishMessageToKafka(ctx context.Context, parentMsg *model.Pu
 	return nil
 }
 
+func messageSpanAttributes(msg *model.PublishedKafkaMsg) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		tracerClient.ChannelKey.String(msg.ChannelName),
+		tracerClient.CategoryKey.String(msg.CategoryName),
+		tracerClient.TypeKey.String(msg.TypeName),
+	}
+}
+
+func setDeliveryResultSpanAttributes(span trace.Span, result *providerClient.DeliveryResult) {
+	span.SetAttributes(
+		tracerClient.ProviderResultCodeKey.String(result.ResultCode),
+		tracerClient.ProviderMessageIDKey.String(result.MessageID),
+	)
+
+	if result.Succeeded+result.Failed+len(result.InvalidRecipients) > 0 {
+		span.SetAttributes(
+			tracerClient.RecipientsSucceededKey.Int(result.Succeeded),
+			tracerClient.RecipientsFailedKey.Int(result.Failed),
+			tracerClient.RecipientsInvalidKey.Int(len(result.InvalidRecipients)),
+		)
+	}
+}
+
 func (u *Usecase) logKafkaMessage(ctx context.Context, data interface{}, err error, activity string) {
 
 	logFields := u.getKafkaLogFields(ctx, data, err, activity)
@@ -82,7 +152,7 @@ func (u *Usecase) getKafkaLogFields(ctx context.Context, data interface{}, err e
 		EventName:         "",
 		TraceID:           fmt.Sprintf("TC%s", metadata.TraceID),
 		PrevTransactionID: "",
-		Body:              fmt.Sprint(data),
+		Body:              u.logger.Redact(data),
 		Result:            "",
 		Error:             constants.FALSE,
 		FlagStartOrStop:   constants.STOP,
@@ -132,7 +202,7 @@ func (u *Usecase) getRestLogFields(ctx context.Context, endpoint, method string,
 		EventName:         "",
 		TraceID:           fmt.Sprintf("TC%s", metadata.TraceID),
 		PrevTransactionID: "",
-		Body:              fmt.Sprint(data),
+		Body:              u.logger.Redact(data),
 		Result:            "",
 		Error:             constants.FALSE,
 		FlagStartOrStop:   constants.STOP,
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (u *Usecase) HandleWebhook(ctx context.Context, parentMsg *model.PublishedKafkaMsg, webhookMsg *model.Webhook) error {
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandleWebhook", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
+	defer span.End()
+
+	webhookMsg.Status = "created"
+	webhookBytes, err := json.Marshal(webhookMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	parentMsg.Data = webhookBytes
+
+	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_WEBHOOK, webhookMsg); err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
+	}
+
+	result, sendErr := u.sendWebhookMessageToProvider(ctx, parentMsg.ChannelName, webhookMsg)
+
+	// The receiver answers synchronously, so the outcome is tracked right away.
+	switch {
+	case sendErr == nil:
+		webhookMsg.Status = "sent"
+	case providerClient.IsRetryable(sendErr):
+		webhookMsg.Status = "retryable"
+	default:
+		webhookMsg.Status = "failed"
+	}
+	if result != nil {
+		webhookMsg.MessageId = result.MessageID
+	}
+
+	webhookBytes, err = json.Marshal(webhookMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	parentMsg.Data = webhookBytes
+
+	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_WEBHOOK, webhookMsg); err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
+	}
+
+	if sendErr != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", sendErr))
+	}
+
+	return nil
+}
+
+func (u *Usecase) sendWebhookMessageToProvider(ctx context.Context, channel string, webhookMsg *model.Webhook) (*providerClient.DeliveryResult, error) {
+	ctx, span := u.tracer.Start(ctx, "Usecase.sendWebhookMessageToProvider")
+	defer span.End()
+
+	senders := u.sendersFor(ctx)
+	if senders.Webhook == nil {
+		return nil, tracerClient.RecordError(span, ErrNoWebhookProvider)
+	}
+
+	span.SetAttributes(tracerClient.ProviderKey.String(senders.Webhook.Name()))
+
+	key := webhookCooldownKey(senders.Webhook.Name(), webhookMsg.Url)
+	result, err := u.sendWithCooldown(key, func() (*providerClient.DeliveryResult, error) {
+		return senders.Webhook.SendWebhook(ctx, channel, webhookMsg)
+	})
+	if result != nil {
+		setDeliveryResultSpanAttributes(span, result)
+	}
+	if err != nil {
+		return result, tracerClient.RecordError(span, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (u *Usecase) HandleWebPush(ctx context.Context, parentMsg *model.PublishedKafkaMsg, webpushMsg *model.WebPush) error {
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandleWebPush", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
+	defer span.End()
+
+	webpushMsg.Status = "created"
+	webpushBytes, err := json.Marshal(webpushMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	parentMsg.Data = webpushBytes
+
+	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_WEBPUSH, webpushMsg); err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
+	}
+
+	result, err := u.sendWebPushMessageToProvider(ctx, webpushMsg)
+	if result != nil {
+		u.publishInvalidRecipients(ctx, parentMsg, constants.NOTIF_TYPE_WEBPUSH_INVALID, result)
+	}
+	if err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", err))
+	}
+
+	return nil
+}
+
+func (u *Usecase) sendWebPushMessageToProvider(ctx context.Context, webpushMsg *model.WebPush) (*providerClient.DeliveryResult, error) {
+	ctx, span := u.tracer.Start(ctx, "Usecase.sendWebPushMessageToProvider")
+	defer span.End()
+
+	senders := u.sendersFor(ctx)
+	if senders.WebPush == nil {
+		return nil, tracerClient.RecordError(span, ErrNoWebPushProvider)
+	}
+
+	span.SetAttributes(tracerClient.ProviderKey.String(senders.WebPush.Name()))
+
+	result, err := u.sendWithCooldown(senders.WebPush.Name(), func() (*providerClient.DeliveryResult, error) {
+		return senders.WebPush.SendWebPush(ctx, webpushMsg)
+	})
+	if result != nil {
+		setDeliveryResultSpanAttributes(span, result)
+	}
+	if err != nil {
+		return result, tracerClient.RecordError(span, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/model"
+	providerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/internal/provider_client"
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+	tracerClient "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/tracer"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (u *Usecase) HandleWhatsApp(ctx context.Context, parentMsg *model.PublishedKafkaMsg, whatsappMsg *model.WhatsApp) error {
+	ctx, span := u.tracer.Start(ctx, "Usecase.HandleWhatsApp", trace.WithAttributes(messageSpanAttributes(parentMsg)...))
+	defer span.End()
+
+	whatsappMsg.Status = "created"
+	whatsappBytes, err := json.Marshal(whatsappMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	parentMsg.Data = whatsappBytes
+
+	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_WHATSAPP, whatsappMsg); err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
+	}
+
+	result, err := u.sendWhatsAppMessageToProvider(ctx, whatsappMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("send message to provider failed: %w", err))
+	}
+
+	// Delivery and read receipts arrive later through the provider webhook and
+	// are correlated by message id, so publish it with the on process status.
+	whatsappMsg.Status = "on process"
+	whatsappMsg.MessageId = result.MessageID
+
+	whatsappBytes, err = json.Marshal(whatsappMsg)
+	if err != nil {
+		return tracerClient.RecordError(span, err)
+	}
+
+	parentMsg.Data = whatsappBytes
+
+	if err := u.publishMessageToKafka(ctx, parentMsg, constants.NOTIF_TYPE_WHATSAPP, whatsappMsg); err != nil {
+		return tracerClient.RecordError(span, fmt.Errorf("publish message to kafka failed: %w", err))
+	}
+
+	return nil
+}
+
+func (u *Usecase) sendWhatsAppMessageToProvider(ctx context.Context, whatsappMsg *model.WhatsApp) (*providerClient.DeliveryResult, error) {
+	ctx, span := u.tracer.Start(ctx, "Usecase.sendWhatsAppMessageToProvider")
+	defer span.End()
+
+	senders := u.sendersFor(ctx)
+	if senders.WhatsApp == nil {
+		return nil, tracerClient.RecordError(span, ErrNoWhatsAppProvider)
+	}
+
+	span.SetAttributes(tracerClient.ProviderKey.String(senders.WhatsApp.Name()))
+
+	result, err := u.sendWithCooldown(senders.WhatsApp.Name(), func() (*providerClient.DeliveryResult, error) {
+		return senders.WhatsApp.SendWhatsApp(ctx, whatsappMsg)
+	})
+	if err != nil {
+		return nil, tracerClient.RecordError(span, err)
+	}
+
+	setDeliveryResultSpanAttributes(span, result)
+
+	return result, nil
+}
@@ -3,6 +3,7 @@ package constants
 const (
 	TIME_LAYOUT_FORMAT = "2006-01-02 15:04:05"
 
+	LEVEL_DEBUG = "DEBUG"
 	LEVEL_INFO  = "INFO"
 	LEVEL_WARN  = "WARN"
 	LEVEL_ERROR = "ERROR"
@@ -16,6 +17,7 @@ const (
 
 	PROTOCOL_HTTP = "HTTP"
 	PROTOCOL_TCP  = "TCP"
+	PROTOCOL_SMTP = "SMTP"
 
 	SYNC  = "SYNC"
 	ASYNC = "ASYNC"
@@ -30,6 +32,7 @@ const (
 
 	PRIORITY_HIGH   = "high"
 	PRIORITY_NORMAL = "reg"
+	PRIORITY_MIXED  = "mixed"
 
 	StatusAborted          = "Message processing aborted"
 	ErrorProcessingMessage = "Error while processing message"
@@ -42,6 +45,24 @@ const (
 	NOTIF_TYPE_INAPP    = "inapp"
 	NOTIF_TYPE_PUSH     = "push"
 	NOTIF_TYPE_SMS_POOL = "sms_pool"
+	NOTIF_TYPE_WHATSAPP = "whatsapp"
+	NOTIF_TYPE_WEBHOOK  = "webhook"
+	NOTIF_TYPE_WEBPUSH  = "webpush"
+
+	NOTIF_TYPE_WEBPUSH_INVALID    = "webpush_invalid"
+	NOTIF_TYPE_PUSH_TOKEN_INVALID = "push_token_invalid"
+	NOTIF_TYPE_REJECTED           = "rejected"
+
+	PROVIDER_WSCOM     = "wscom"
+	PROVIDER_SMSAPPS   = "smsapps"
+	PROVIDER_FCM       = "fcm"
+	PROVIDER_ONESIGNAL = "onesignal"
+	PROVIDER_RESTSMS   = "restsms"
+	PROVIDER_SMTP      = "smtp"
+	PROVIDER_WACLOUD   = "wacloud"
+	PROVIDER_HTTP      = "http"
+	PROVIDER_APNS      = "apns"
+	PROVIDER_VAPID     = "vapid"
 
 	CHANNEL_JMO     = "jmo"
 	CHANNEL_SMILE   = "smile"
@@ -49,3 +70,11 @@ const (
 	CHANNEL_SIDIA   = "sidia"
 	CHANNEL_PERISAI = "perisai"
 )
+
+var CHANNELS = []string{
+	CHANNEL_JMO,
+	CHANNEL_SMILE,
+	CHANNEL_SIPP,
+	CHANNEL_SIDIA,
+	CHANNEL_PERISAI,
+}
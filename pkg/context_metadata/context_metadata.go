@@ -16,14 +16,23 @@ type Metadata struct {
 	TransactionID string
 	Topic         string
 	StartTime     int64
+	Attempt       int
+	// Priority is set when one process consumes several priorities, empty
+	// otherwise.
+	Priority string
 }
 
-func SetMetadataToNewContext(ctx context.Context, traceID string, topic string) context.Context {
+func SetMetadataToNewContext(ctx context.Context, traceID string, topic string, attempt int) context.Context {
+	if attempt < 1 {
+		attempt = 1
+	}
+
 	metadata := Metadata{
 		TraceID:       traceID,
 		TransactionID: uuid.New().String(),
 		Topic:         topic,
 		StartTime:     time.Now().UnixMilli(),
+		Attempt:       attempt,
 	}
 
 	return context.WithValue(ctx, MetadataKey, metadata)
@@ -33,3 +42,11 @@ func GetMetadataFromContext(ctx context.Context) (Metadata, bool) {
 	metadata, ok := ctx.Value(MetadataKey).(Metadata)
 	return metadata, ok
 }
+
+// WithPriority returns a copy of ctx whose metadata carries priority.
+func WithPriority(ctx context.Context, priority string) context.Context {
+	metadata, _ := GetMetadataFromContext(ctx)
+	metadata.Priority = priority
+
+	return context.WithValue(ctx, MetadataKey, metadata)
+}
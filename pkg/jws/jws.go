@@ -0,0 +1,71 @@
+package jws
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// SignES256 returns a compact JWS of claims signed with key, header is merged
+// over {"alg":"ES256","typ":"JWT"}.
+func SignES256(key *ecdsa.PrivateKey, header map[string]interface{}, claims map[string]interface{}) (string, error) {
+	h := map[string]interface{}{
+		"alg": "ES256",
+		"typ": "JWT",
+	}
+	for k, v := range header {
+		h[k] = v
+	}
+
+	headerBytes, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	claimBytes, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(claimBytes)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS wants the fixed size r || s form rather than ASN.1.
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ParseP256PrivateKey reads a PEM encoded PKCS#8 P-256 key such as an APNs
+// .p8 file.
+func ParseP256PrivateKey(pemBytes []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("key is not PEM encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse key: %w", err)
+	}
+
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok || key.Curve.Params().BitSize != 256 {
+		return nil, errors.New("key must be a P-256 ECDSA key")
+	}
+
+	return key, nil
+}
@@ -12,11 +12,15 @@ type Worker func(ctx context.Context, r *kafka.Reader, workerID int)
 
 type Consumer struct {
 	brokers []string
+	stats   *ConsumerStats
+	auth    *Auth
 }
 
-func NewConsumer(brokers []string) *Consumer {
+func NewConsumer(brokers []string, stats *ConsumerStats, auth *Auth) *Consumer {
 	return &Consumer{
 		brokers: brokers,
+		stats:   stats,
+		auth:    auth,
 	}
 }
 
@@ -29,9 +33,11 @@ func (c *Consumer) StartWorkers(ctx context.Context, groupID string, consumerTop
 		go func(workerID int) {
 			defer wg.Done()
 
-			reader := NewReader(c.brokers, groupID, consumerTopic)
+			reader := NewReader(c.brokers, groupID, consumerTopic, c.stats.ClientID(), c.auth)
+			c.stats.AddReader(consumerTopic, reader)
 
 			defer func() {
+				c.stats.RemoveReader(reader)
 				if err := reader.Close(); err != nil {
 					fmt.Println("Failed to close reader:", err)
 				}
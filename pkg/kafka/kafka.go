@@ -13,14 +13,19 @@ type Config struct {
 	GroupID         string `mapstructure:"GROUP_ID"`
 	PoolSize        string `mapstructure:"POOL_SIZE"`
 	Partition       string `mapstructure:"PARTITION"`
+	StatsInterval   string `mapstructure:"STATS_INTERVAL"`
+	// Producer and consumer brokers are separate clusters, each with its own
+	// TLS and SASL setup.
+	ProducerSecurity *Security `mapstructure:"PRODUCER"`
+	ConsumerSecurity *Security `mapstructure:"CONSUMER"`
 }
 
 const (
 	ReplicationFactor = 1
 )
 
-func NewKafkaConn(ctx context.Context, kafkaCfg *Config) (*kafka.Conn, error) {
+func NewKafkaConn(ctx context.Context, kafkaCfg *Config, auth *Auth) (*kafka.Conn, error) {
 	producerBrokers := strings.Split(kafkaCfg.ProducerBrokers, ",")
 
-	return kafka.DialContext(ctx, "tcp", producerBrokers[0])
+	return auth.Dialer("").DialContext(ctx, "tcp", producerBrokers[0])
 }
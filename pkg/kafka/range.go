@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// rangeIdleTimeout ends a partition that has nothing left to read before its
+// last offset, which happens when the tail holds transaction markers.
+const rangeIdleTimeout = 10 * time.Second
+
+// Bound is one end of a range, either an offset or a time. The zero Bound
+// is open: the first offset for From, the last offset at start for To.
+type Bound struct {
+	Offset int64
+	Time   time.Time
+	set    bool
+}
+
+// ParseBound accepts an offset or an RFC 3339 time, empty meaning open.
+func ParseBound(s string) (Bound, error) {
+	if s == "" {
+		return Bound{}, nil
+	}
+
+	if offset, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if offset < 0 {
+			return Bound{}, fmt.Errorf("offset %d is negative", offset)
+		}
+		return Bound{Offset: offset, set: true}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return Bound{}, fmt.Errorf("%q is neither an offset nor an RFC 3339 time", s)
+	}
+
+	return Bound{Offset: -1, Time: t, set: true}, nil
+}
+
+func (b Bound) isTime() bool {
+	return b.set && b.Offset < 0
+}
+
+// PartitionRange is the span of a partition a range read covers. End is
+// exclusive.
+type PartitionRange struct {
+	Partition int
+	Start     int64
+	End       int64
+}
+
+// RangeReader reads a slice of a topic with standalone readers, one
+// partition after the other, without a consumer group or commits.
+type RangeReader struct {
+	brokers []string
+	topic   string
+	auth    *Auth
+	// Partition limits the read to one partition when not negative.
+	Partition int
+}
+
+func NewRangeReader(brokers []string, topic string, auth *Auth) *RangeReader {
+	return &RangeReader{
+		brokers:   brokers,
+		topic:     topic,
+		auth:      auth,
+		Partition: -1,
+	}
+}
+
+// Plan resolves from and to into offsets per partition. Partitions with
+// nothing in range are left out.
+func (rr *RangeReader) Plan(ctx context.Context, from Bound, to Bound) ([]PartitionRange, error) {
+	dialer := rr.auth.Dialer("")
+
+	partitions, err := dialer.LookupPartitions(ctx, "tcp", rr.brokers[0], rr.topic)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("topic %s has no partitions", rr.topic)
+	}
+
+	var ranges []PartitionRange
+	for _, partition := range partitions {
+		if rr.Partition >= 0 && partition.ID != rr.Partition {
+			continue
+		}
+
+		pr, err := rr.planPartition(ctx, dialer, partition.ID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("partition %d: %w", partition.ID, err)
+		}
+		if pr.Start < pr.End {
+			ranges = append(ranges, pr)
+		}
+	}
+
+	return ranges, nil
+}
+
+func (rr *RangeReader) planPartition(ctx context.Context, dialer *kafka.Dialer, partition int, from Bound, to Bound) (PartitionRange, error) {
+	conn, err := dialer.DialLeader(ctx, "tcp", rr.brokers[0], rr.topic, partition)
+	if err != nil {
+		return PartitionRange{}, err
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return PartitionRange{}, err
+	}
+
+	return resolveRange(partition, first, last, from, to, conn.ReadOffset)
+}
+
+// resolveRange turns from and to into offsets within [first, last) of a
+// partition. offsetAt returns the offset of the first message at or after a
+// time, negative when there is none.
+func resolveRange(partition int, first int64, last int64, from Bound, to Bound, offsetAt func(time.Time) (int64, error)) (PartitionRange, error) {
+	var err error
+	pr := PartitionRange{Partition: partition, Start: first, End: last}
+
+	switch {
+	case from.isTime():
+		if pr.Start, err = offsetAt(from.Time); err != nil {
+			return PartitionRange{}, err
+		}
+	case from.set:
+		pr.Start = from.Offset
+	}
+
+	switch {
+	case to.isTime():
+		// The offset of the first message after to ends the range.
+		end, err := offsetAt(to.Time.Add(time.Millisecond))
+		if err != nil {
+			return PartitionRange{}, err
+		}
+		if end >= 0 {
+			pr.End = end
+		}
+	case to.set:
+		pr.End = to.Offset + 1
+	}
+
+	// No message at or after a from time leaves nothing to read.
+	if pr.Start < 0 {
+		pr.Start = last
+	}
+	if pr.Start < first {
+		pr.Start = first
+	}
+	if pr.End > last {
+		pr.End = last
+	}
+
+	return pr, nil
+}
+
+// Read calls fn for every message of pr in offset order. It stops at the
+// first error fn returns.
+func (rr *RangeReader) Read(ctx context.Context, pr PartitionRange, fn func(kafka.Message) error) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   rr.brokers,
+		Topic:     rr.topic,
+		Partition: pr.Partition,
+		MinBytes:  1,
+		MaxBytes:  maxBytes,
+		MaxWait:   readerMaxWait,
+		Dialer:    rr.auth.Dialer(""),
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(pr.Start); err != nil {
+		return err
+	}
+
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, rangeIdleTimeout)
+		msg, err := reader.ReadMessage(readCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return nil
+			}
+			return err
+		}
+
+		if msg.Offset >= pr.End {
+			return nil
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+		if msg.Offset+1 >= pr.End {
+			return nil
+		}
+	}
+}
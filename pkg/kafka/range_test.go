@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseBound(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+
+	tests := []struct {
+		in      string
+		want    Bound
+		wantErr bool
+	}{
+		{in: "", want: Bound{}},
+		{in: "0", want: Bound{Offset: 0, set: true}},
+		{in: "42", want: Bound{Offset: 42, set: true}},
+		{in: "2024-05-01T10:00:00+07:00", want: Bound{Offset: -1, Time: at, set: true}},
+		{in: "2024-05-01T03:00:00Z", want: Bound{Offset: -1, Time: at, set: true}},
+		{in: "-1", wantErr: true},
+		{in: "2024-05-01", wantErr: true},
+		{in: "2024-05-01 10:00:00", wantErr: true},
+		{in: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseBound(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBound = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Offset != tt.want.Offset || got.set != tt.want.set || !got.Time.Equal(tt.want.Time) {
+				t.Errorf("ParseBound = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRange(t *testing.T) {
+	// Offsets 10 to 19 are retained, one message per second from base.
+	const first, last = 10, 20
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	timeOf := func(offset int64) time.Time {
+		return base.Add(time.Duration(offset-first) * time.Second)
+	}
+	offsetAt := func(t time.Time) (int64, error) {
+		for offset := int64(first); offset < last; offset++ {
+			if !timeOf(offset).Before(t) {
+				return offset, nil
+			}
+		}
+		return -1, nil
+	}
+
+	offset := func(o int64) Bound { return Bound{Offset: o, set: true} }
+	at := func(t time.Time) Bound { return Bound{Offset: -1, Time: t, set: true} }
+
+	tests := []struct {
+		name      string
+		from, to  Bound
+		wantStart int64
+		wantEnd   int64
+	}{
+		{name: "open", wantStart: 10, wantEnd: 20},
+		{name: "offsets, to is inclusive", from: offset(12), to: offset(15), wantStart: 12, wantEnd: 16},
+		{name: "single offset", from: offset(12), to: offset(12), wantStart: 12, wantEnd: 13},
+		{name: "from before retention", from: offset(3), wantStart: 10, wantEnd: 20},
+		{name: "to past the end", to: offset(25), wantStart: 10, wantEnd: 20},
+		{name: "to at the last offset", to: offset(19), wantStart: 10, wantEnd: 20},
+		{name: "times on messages", from: at(timeOf(13)), to: at(timeOf(15)), wantStart: 13, wantEnd: 16},
+		{name: "to just before the next message", to: at(timeOf(15).Add(999 * time.Millisecond)), wantStart: 10, wantEnd: 16},
+		{name: "to between messages", from: at(timeOf(12).Add(-time.Millisecond)), to: at(timeOf(15).Add(500 * time.Millisecond)), wantStart: 12, wantEnd: 16},
+		{name: "from after every message", from: at(timeOf(30)), wantStart: 20, wantEnd: 20},
+		{name: "to after every message", to: at(timeOf(30)), wantStart: 10, wantEnd: 20},
+		{name: "to before every message", to: at(base.Add(-time.Hour)), wantStart: 10, wantEnd: 10},
+		{name: "from after to", from: offset(16), to: offset(12), wantStart: 16, wantEnd: 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := resolveRange(3, first, last, tt.from, tt.to, offsetAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pr.Partition != 3 || pr.Start != tt.wantStart || pr.End != tt.wantEnd {
+				t.Errorf("range = %+v, want %d to %d", pr, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestResolveRangeOffsetError(t *testing.T) {
+	lookupErr := errors.New("broker unavailable")
+	offsetAt := func(time.Time) (int64, error) { return 0, lookupErr }
+
+	from := Bound{Offset: -1, Time: time.Now(), set: true}
+	if _, err := resolveRange(0, 0, 10, from, Bound{}, offsetAt); !errors.Is(err, lookupErr) {
+		t.Errorf("err = %v, want the lookup error", err)
+	}
+	if _, err := resolveRange(0, 0, 10, Bound{}, from, offsetAt); !errors.Is(err, lookupErr) {
+		t.Errorf("err = %v, want the lookup error", err)
+	}
+}
@@ -20,7 +20,7 @@ const (
 	readerReadLagInterval        = -1
 )
 
-func NewReader(brokers []string, groupID string, topic string) *kafka.Reader {
+func NewReader(brokers []string, groupID string, topic string, clientID string, auth *Auth) *kafka.Reader {
 	return kafka.NewReader(kafka.ReaderConfig{
 		Brokers:                brokers,
 		GroupID:                groupID,
@@ -35,8 +35,6 @@ func NewReader(brokers []string, groupID string, topic string) *kafka.Reader {
 		PartitionWatchInterval: readerPartitionWatchInterval,
 		MaxAttempts:            readerMaxAttempts,
 		MaxWait:                readerMaxWait,
-		Dialer: &kafka.Dialer{
-			Timeout: dialTimeout,
-		},
+		Dialer:                 auth.Dialer(clientID),
 	})
 }
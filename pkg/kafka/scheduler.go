@@ -0,0 +1,231 @@
+package kafka
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Handler processes one fetched message of class and commits it through r.
+type Handler func(ctx context.Context, class string, r *kafka.Reader, msg kafka.Message)
+
+// SchedulerClass is a queue of the scheduler. Classes listed first win ties,
+// so the most urgent class goes first.
+type SchedulerClass struct {
+	Name   string
+	Weight int
+}
+
+type QueueStats struct {
+	Class    string `json:"class"`
+	Weight   int    `json:"weight"`
+	Depth    int64  `json:"depth"`
+	Handled  int64  `json:"handled"`
+	InFlight int64  `json:"in_flight"`
+}
+
+type scheduledMessage struct {
+	reader *kafka.Reader
+	msg    kafka.Message
+	done   chan struct{}
+}
+
+type schedulerQueue struct {
+	class    SchedulerClass
+	ch       chan *scheduledMessage
+	depth    atomic.Int64
+	handled  atomic.Int64
+	inFlight atomic.Int64
+	// current is the smooth weighted round robin credit of the queue.
+	current int
+}
+
+// Scheduler shares a pool of workers between queues with smooth weighted
+// round robin. Only queues with waiting messages take part in a round, so an
+// idle class lends its share to the others and any class with a weight of at
+// least one is served while it has messages.
+type Scheduler struct {
+	mu     sync.Mutex
+	queues []*schedulerQueue
+	index  map[string]*schedulerQueue
+	notify chan struct{}
+}
+
+func NewScheduler(classes []SchedulerClass) *Scheduler {
+	s := &Scheduler{
+		index:  make(map[string]*schedulerQueue, len(classes)),
+		notify: make(chan struct{}, 1),
+	}
+
+	for _, class := range classes {
+		if class.Weight < 1 {
+			class.Weight = 1
+		}
+
+		queue := &schedulerQueue{
+			class: class,
+			ch:    make(chan *scheduledMessage),
+		}
+		s.queues = append(s.queues, queue)
+		s.index[class.Name] = queue
+	}
+
+	return s
+}
+
+// Submit queues msg for class and blocks until a worker has handled it, so
+// each reader keeps a single message in flight and commits stay in order.
+// It returns false when ctx is done before a worker took the message.
+func (s *Scheduler) Submit(ctx context.Context, class string, r *kafka.Reader, msg kafka.Message) bool {
+	queue := s.index[class]
+
+	item := &scheduledMessage{
+		reader: r,
+		msg:    msg,
+		done:   make(chan struct{}),
+	}
+
+	queue.depth.Add(1)
+	s.wake()
+
+	select {
+	case <-ctx.Done():
+		queue.depth.Add(-1)
+		return false
+	case queue.ch <- item:
+	}
+
+	<-item.done
+	return true
+}
+
+// Run starts workers that handle submitted messages and blocks until ctx is
+// done and they returned.
+func (s *Scheduler) Run(ctx context.Context, workers int, handle Handler) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				queue, item, ok := s.next(ctx)
+				if !ok {
+					return
+				}
+
+				queue.inFlight.Add(1)
+				handle(ctx, queue.class.Name, item.reader, item.msg)
+				queue.inFlight.Add(-1)
+				queue.handled.Add(1)
+				close(item.done)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *Scheduler) next(ctx context.Context) (*schedulerQueue, *scheduledMessage, bool) {
+	for {
+		queue, item, waiting := s.take()
+		if item != nil {
+			// Hand the wake up on while messages are left so idle workers do
+			// not sleep next to a full queue.
+			if waiting {
+				s.wake()
+			}
+			return queue, item, true
+		}
+		if waiting {
+			// A submitter counted its message but has not reached the
+			// channel yet.
+			runtime.Gosched()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, false
+		case <-s.notify:
+		}
+	}
+}
+
+// take receives from the waiting queue due next. waiting reports whether
+// messages are still queued after it.
+func (s *Scheduler) take() (*schedulerQueue, *scheduledMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *schedulerQueue
+	var contending []*schedulerQueue
+	total := 0
+
+	for _, queue := range s.queues {
+		if queue.depth.Load() == 0 {
+			continue
+		}
+
+		contending = append(contending, queue)
+		total += queue.class.Weight
+		if best == nil || queue.current+queue.class.Weight > best.current+best.class.Weight {
+			best = queue
+		}
+	}
+
+	if best == nil {
+		return nil, nil, false
+	}
+
+	select {
+	case item := <-best.ch:
+		best.depth.Add(-1)
+
+		for _, queue := range contending {
+			queue.current += queue.class.Weight
+		}
+		best.current -= total
+
+		return best, item, s.pending()
+	default:
+		return nil, nil, true
+	}
+}
+
+func (s *Scheduler) pending() bool {
+	for _, queue := range s.queues {
+		if queue.depth.Load() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) Snapshot() []QueueStats {
+	stats := make([]QueueStats, 0, len(s.queues))
+
+	for _, queue := range s.queues {
+		stats = append(stats, QueueStats{
+			Class:    queue.class.Name,
+			Weight:   queue.class.Weight,
+			Depth:    queue.depth.Load(),
+			Handled:  queue.handled.Load(),
+			InFlight: queue.inFlight.Load(),
+		})
+	}
+
+	return stats
+}
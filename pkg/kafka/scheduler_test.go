@@ -0,0 +1,197 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// runSaturated keeps submitters messages waiting in every class and returns
+// the classes of the first n handled messages, taken by a single worker. The
+// handler waits for the other submitters to queue again so every class
+// contends for each pick.
+func runSaturated(t *testing.T, classes []SchedulerClass, submitters int, n int) []string {
+	t.Helper()
+
+	s := NewScheduler(classes)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, class := range classes {
+		for i := 0; i < submitters; i++ {
+			wg.Add(1)
+			go func(class string) {
+				defer wg.Done()
+				for s.Submit(ctx, class, nil, kafka.Message{}) {
+				}
+			}(class.Name)
+		}
+	}
+
+	// Every class must be queued before the worker starts, the order is only
+	// fixed while all of them contend.
+	if !waitQueued(s, int64(submitters)) {
+		t.Fatalf("submitters did not queue: %+v", s.Snapshot())
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	s.Run(ctx, 1, func(_ context.Context, class string, _ *kafka.Reader, _ kafka.Message) {
+		mu.Lock()
+		handled = append(handled, class)
+		last := len(handled) == n
+		mu.Unlock()
+
+		if last {
+			cancel()
+			return
+		}
+		// The submitter of this message waits for it, the others must be
+		// back in their queue before the next pick.
+		if !waitQueued(s, int64(submitters-1)) {
+			t.Errorf("submitters did not queue again: %+v", s.Snapshot())
+			cancel()
+		}
+	})
+	wg.Wait()
+
+	if len(handled) < n {
+		t.FailNow()
+	}
+	return handled[:n]
+}
+
+// waitQueued waits until every class has at least depth messages queued,
+// false when they never do.
+func waitQueued(s *Scheduler, depth int64) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for !allQueued(s, depth) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+	return true
+}
+
+func allQueued(s *Scheduler, depth int64) bool {
+	for _, stats := range s.Snapshot() {
+		if stats.Depth < depth {
+			return false
+		}
+	}
+	return true
+}
+
+func count(handled []string, class string) int {
+	n := 0
+	for _, c := range handled {
+		if c == class {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSchedulerWeightRatio(t *testing.T) {
+	handled := runSaturated(t, []SchedulerClass{{Name: "high", Weight: 4}, {Name: "regular", Weight: 1}}, 4, 500)
+
+	if high, regular := count(handled, "high"), count(handled, "regular"); high != 400 || regular != 100 {
+		t.Errorf("handled %d high and %d regular, want 400 and 100", high, regular)
+	}
+
+	// Smooth round robin spreads the regular messages instead of bunching
+	// them at the end of a round.
+	for i := 0; i+5 <= len(handled); i += 5 {
+		if regular := count(handled[i:i+5], "regular"); regular != 1 {
+			t.Fatalf("round at %d has %d regular messages, want 1: %v", i, regular, handled[i:i+5])
+		}
+	}
+}
+
+func TestSchedulerHighWinsTies(t *testing.T) {
+	handled := runSaturated(t, []SchedulerClass{{Name: "high", Weight: 1}, {Name: "regular", Weight: 1}}, 4, 10)
+
+	for i, class := range handled {
+		want := "high"
+		if i%2 == 1 {
+			want = "regular"
+		}
+		if class != want {
+			t.Fatalf("handled %v, want high first and then alternating", handled)
+		}
+	}
+}
+
+func TestSchedulerRegularNotStarved(t *testing.T) {
+	handled := runSaturated(t, []SchedulerClass{{Name: "high", Weight: 100}, {Name: "regular", Weight: 1}}, 4, 303)
+
+	for i := 0; i < len(handled); i += 101 {
+		if regular := count(handled[i:i+101], "regular"); regular != 1 {
+			t.Fatalf("round at %d has %d regular messages, want 1", i, regular)
+		}
+	}
+}
+
+func TestSchedulerIdleClassLendsShare(t *testing.T) {
+	s := NewScheduler([]SchedulerClass{{Name: "high", Weight: 4}, {Name: "regular", Weight: 1}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, 1, func(context.Context, string, *kafka.Reader, kafka.Message) {})
+	}()
+
+	// Only regular has messages, none of them waits for the high share.
+	for i := 0; i < 20; i++ {
+		if !s.Submit(ctx, "regular", nil, kafka.Message{}) {
+			t.Fatal("submit failed")
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+func TestSchedulerSubmitCancelled(t *testing.T) {
+	s := NewScheduler([]SchedulerClass{{Name: "high", Weight: 4}, {Name: "regular", Weight: 1}})
+
+	// No worker runs, so the message is never taken.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if s.Submit(ctx, "regular", nil, kafka.Message{}) {
+		t.Fatal("Submit returned true without a worker")
+	}
+
+	for _, stats := range s.Snapshot() {
+		if stats.Depth != 0 {
+			t.Errorf("%s depth = %d, want 0 after a cancelled submit", stats.Class, stats.Depth)
+		}
+	}
+}
+
+func TestSchedulerRunReturnsOnCancel(t *testing.T) {
+	s := NewScheduler([]SchedulerClass{{Name: "regular", Weight: 1}})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx, 3, func(context.Context, string, *kafka.Reader, kafka.Message) {})
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
@@ -0,0 +1,151 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismScramSHA256 = "SCRAM-SHA-256"
+	SASLMechanismScramSHA512 = "SCRAM-SHA-512"
+)
+
+// Security is the TLS and SASL setup of one cluster. Both are off when
+// TLSEnabled is not true and SASLMechanism is empty.
+type Security struct {
+	TLSEnabled            string `mapstructure:"TLS_ENABLED"`
+	TLSCAFile             string `mapstructure:"TLS_CA_FILE"`
+	TLSCertFile           string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile            string `mapstructure:"TLS_KEY_FILE"`
+	TLSServerName         string `mapstructure:"TLS_SERVER_NAME"`
+	TLSInsecureSkipVerify string `mapstructure:"TLS_INSECURE_SKIP_VERIFY"`
+	SASLMechanism         string `mapstructure:"SASL_MECHANISM"`
+	SASLUsername          string `mapstructure:"SASL_USERNAME"`
+	SASLPassword          string `mapstructure:"SASL_PASSWORD"`
+}
+
+// Auth holds the TLS config and SASL mechanism built from a Security. A nil
+// Auth dials plaintext without authentication.
+type Auth struct {
+	TLS  *tls.Config
+	SASL sasl.Mechanism
+}
+
+func NewAuth(sec *Security) (*Auth, error) {
+	auth := &Auth{}
+	if sec == nil {
+		return auth, nil
+	}
+
+	if enabled, _ := strconv.ParseBool(sec.TLSEnabled); enabled {
+		tlsCfg, err := newTLSConfig(sec)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: %w", err)
+		}
+		auth.TLS = tlsCfg
+	}
+
+	mechanism, err := newSASLMechanism(sec)
+	if err != nil {
+		return nil, fmt.Errorf("kafka sasl: %w", err)
+	}
+	auth.SASL = mechanism
+
+	return auth, nil
+}
+
+func newTLSConfig(sec *Security) (*tls.Config, error) {
+	insecureSkipVerify, _ := strconv.ParseBool(sec.TLSInsecureSkipVerify)
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         sec.TLSServerName,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if sec.TLSCAFile != "" {
+		b, err := os.ReadFile(sec.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", sec.TLSCAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if sec.TLSCertFile != "" || sec.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(sec.TLSCertFile, sec.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func newSASLMechanism(sec *Security) (sasl.Mechanism, error) {
+	mechanism := strings.ToUpper(strings.TrimSpace(sec.SASLMechanism))
+	if mechanism == "" {
+		return nil, nil
+	}
+
+	if sec.SASLUsername == "" {
+		return nil, errors.New("username is not configured")
+	}
+
+	switch mechanism {
+	case SASLMechanismPlain:
+		return plain.Mechanism{Username: sec.SASLUsername, Password: sec.SASLPassword}, nil
+	case SASLMechanismScramSHA256:
+		return scram.Mechanism(scram.SHA256, sec.SASLUsername, sec.SASLPassword)
+	case SASLMechanismScramSHA512:
+		return scram.Mechanism(scram.SHA512, sec.SASLUsername, sec.SASLPassword)
+	}
+
+	return nil, fmt.Errorf("unsupported mechanism %q", sec.SASLMechanism)
+}
+
+// Dialer is used by readers, writers and admin connections.
+func (a *Auth) Dialer(clientID string) *kafka.Dialer {
+	dialer := &kafka.Dialer{
+		ClientID: clientID,
+		Timeout:  dialTimeout,
+	}
+
+	if a != nil {
+		dialer.TLS = a.TLS
+		dialer.SASLMechanism = a.SASL
+	}
+
+	return dialer
+}
+
+// Transport is used by kafka.Client requests.
+func (a *Auth) Transport(clientID string) *kafka.Transport {
+	transport := &kafka.Transport{
+		ClientID:    clientID,
+		DialTimeout: dialTimeout,
+	}
+
+	if a != nil {
+		transport.TLS = a.TLS
+		transport.SASL = a.SASL
+	}
+
+	return transport
+}
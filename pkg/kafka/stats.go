@@ -0,0 +1,349 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	statsRequestTimeout = 10 * time.Second
+)
+
+type TopicStats struct {
+	Topic              string `json:"topic"`
+	Readers            int    `json:"readers"`
+	AssignedPartitions int    `json:"assigned_partitions"`
+	Lag                int64  `json:"lag"`
+	Fetches            int64  `json:"fetches"`
+	Messages           int64  `json:"messages"`
+	Bytes              int64  `json:"bytes"`
+	Commits            int64  `json:"commits"`
+	CommitErrors       int64  `json:"commit_errors"`
+	Rebalances         int64  `json:"rebalances"`
+	Timeouts           int64  `json:"timeouts"`
+	Errors             int64  `json:"errors"`
+}
+
+type PartitionStats struct {
+	Topic           string `json:"topic"`
+	Partition       int    `json:"partition"`
+	Assigned        bool   `json:"assigned"`
+	CommittedOffset int64  `json:"committed_offset"`
+	FirstOffset     int64  `json:"first_offset"`
+	HighWaterMark   int64  `json:"high_water_mark"`
+	Lag             int64  `json:"lag"`
+}
+
+type ConsumerStatsSnapshot struct {
+	ClientID   string           `json:"client_id"`
+	GroupID    string           `json:"group_id"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	LastError  string           `json:"last_error,omitempty"`
+	Topics     []TopicStats     `json:"topics"`
+	Partitions []PartitionStats `json:"partitions"`
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+type ConsumerStats struct {
+	mu         sync.RWMutex
+	clientID   string
+	groupID    string
+	brokers    []string
+	transport  *kafka.Transport
+	topicNames []string
+	readers    map[*kafka.Reader]string
+	topics     map[string]*TopicStats
+	partitions map[topicPartition]*PartitionStats
+	updatedAt  time.Time
+	lastError  string
+}
+
+func NewConsumerStats(clientID string) *ConsumerStats {
+	return &ConsumerStats{
+		clientID:   clientID,
+		readers:    make(map[*kafka.Reader]string),
+		topics:     make(map[string]*TopicStats),
+		partitions: make(map[topicPartition]*PartitionStats),
+	}
+}
+
+func (cs *ConsumerStats) ClientID() string {
+	return cs.clientID
+}
+
+func (cs *ConsumerStats) AddReader(topic string, r *kafka.Reader) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.readers[r] = topic
+	cs.topic(topic).Readers++
+}
+
+func (cs *ConsumerStats) RemoveReader(r *kafka.Reader) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	topic, ok := cs.readers[r]
+	if !ok {
+		return
+	}
+
+	// Stats counters are reset on every call, so drain the last delta before
+	// the reader goes away.
+	cs.accumulate(topic, r.Stats())
+	cs.topic(topic).Readers--
+	delete(cs.readers, r)
+}
+
+func (cs *ConsumerStats) RecordCommit(topic string, err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if err != nil {
+		cs.topic(topic).CommitErrors++
+		return
+	}
+	cs.topic(topic).Commits++
+}
+
+func (cs *ConsumerStats) Run(ctx context.Context, brokers []string, auth *Auth, groupID string, topics []string, interval time.Duration) {
+	cs.mu.Lock()
+	cs.brokers = brokers
+	// One transport for the lifetime of the stats so its connections are
+	// reused between refreshes.
+	cs.transport = auth.Transport(cs.clientID)
+	cs.groupID = groupID
+	cs.topicNames = topics
+	for _, topic := range topics {
+		cs.topic(topic)
+	}
+	cs.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cs.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cs *ConsumerStats) Refresh(ctx context.Context) {
+	cs.mu.Lock()
+	for r, topic := range cs.readers {
+		cs.accumulate(topic, r.Stats())
+	}
+	brokers, transport, groupID, topics := cs.brokers, cs.transport, cs.groupID, cs.topicNames
+	cs.mu.Unlock()
+
+	if len(brokers) == 0 || groupID == "" || len(topics) == 0 {
+		return
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, statsRequestTimeout)
+	defer cancel()
+
+	partitions, err := fetchPartitionStats(reqCtx, brokers, transport, groupID, topics, cs.clientID)
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.updatedAt = time.Now()
+	if err != nil {
+		cs.lastError = err.Error()
+		return
+	}
+	cs.lastError = ""
+
+	cs.partitions = partitions
+	for _, ts := range cs.topics {
+		ts.Lag = 0
+		ts.AssignedPartitions = 0
+	}
+	for _, ps := range partitions {
+		ts := cs.topic(ps.Topic)
+		ts.Lag += ps.Lag
+		if ps.Assigned {
+			ts.AssignedPartitions++
+		}
+	}
+}
+
+func (cs *ConsumerStats) Snapshot() *ConsumerStatsSnapshot {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	snapshot := &ConsumerStatsSnapshot{
+		ClientID:   cs.clientID,
+		GroupID:    cs.groupID,
+		UpdatedAt:  cs.updatedAt,
+		LastError:  cs.lastError,
+		Topics:     make([]TopicStats, 0, len(cs.topics)),
+		Partitions: make([]PartitionStats, 0, len(cs.partitions)),
+	}
+
+	for _, ts := range cs.topics {
+		snapshot.Topics = append(snapshot.Topics, *ts)
+	}
+	for _, ps := range cs.partitions {
+		snapshot.Partitions = append(snapshot.Partitions, *ps)
+	}
+
+	sort.Slice(snapshot.Topics, func(i, j int) bool {
+		return snapshot.Topics[i].Topic < snapshot.Topics[j].Topic
+	})
+	sort.Slice(snapshot.Partitions, func(i, j int) bool {
+		if snapshot.Partitions[i].Topic != snapshot.Partitions[j].Topic {
+			return snapshot.Partitions[i].Topic < snapshot.Partitions[j].Topic
+		}
+		return snapshot.Partitions[i].Partition < snapshot.Partitions[j].Partition
+	})
+
+	return snapshot
+}
+
+func (cs *ConsumerStats) topic(topic string) *TopicStats {
+	ts, ok := cs.topics[topic]
+	if !ok {
+		ts = &TopicStats{Topic: topic}
+		cs.topics[topic] = ts
+	}
+	return ts
+}
+
+func (cs *ConsumerStats) accumulate(topic string, rs kafka.ReaderStats) {
+	ts := cs.topic(topic)
+	ts.Fetches += rs.Fetches
+	ts.Messages += rs.Messages
+	ts.Bytes += rs.Bytes
+	ts.Rebalances += rs.Rebalances
+	ts.Timeouts += rs.Timeouts
+	ts.Errors += rs.Errors
+}
+
+// The reader only computes lag for non-group consumers, so the group lag is
+// derived from the committed offsets and the partition high water marks.
+func fetchPartitionStats(ctx context.Context, brokers []string, transport *kafka.Transport, groupID string, topics []string, clientID string) (map[topicPartition]*PartitionStats, error) {
+	client := &kafka.Client{
+		Addr:      kafka.TCP(brokers...),
+		Timeout:   statsRequestTimeout,
+		Transport: transport,
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	partitionIDs := make(map[string][]int)
+	offsetRequests := make(map[string][]kafka.OffsetRequest)
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			continue
+		}
+		for _, p := range topic.Partitions {
+			partitionIDs[topic.Name] = append(partitionIDs[topic.Name], p.ID)
+			offsetRequests[topic.Name] = append(offsetRequests[topic.Name], kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+		}
+	}
+
+	listOffsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: offsetRequests})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets: %w", err)
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: partitionIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %w", committed.Error)
+	}
+
+	groups, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{groupID}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe group: %w", err)
+	}
+
+	partitions := make(map[topicPartition]*PartitionStats)
+	for topic, offsets := range listOffsets.Topics {
+		for _, po := range offsets {
+			if po.Error != nil {
+				continue
+			}
+			partitions[topicPartition{topic, po.Partition}] = &PartitionStats{
+				Topic:           topic,
+				Partition:       po.Partition,
+				FirstOffset:     po.FirstOffset,
+				HighWaterMark:   po.LastOffset,
+				CommittedOffset: -1,
+			}
+		}
+	}
+
+	for topic, offsets := range committed.Topics {
+		for _, po := range offsets {
+			ps, ok := partitions[topicPartition{topic, po.Partition}]
+			if !ok || po.Error != nil {
+				continue
+			}
+			ps.CommittedOffset = po.CommittedOffset
+		}
+	}
+
+	for _, group := range groups.Groups {
+		if group.Error != nil {
+			continue
+		}
+		for _, member := range group.Members {
+			if member.ClientID != clientID {
+				continue
+			}
+			for _, assignment := range member.MemberAssignments.Topics {
+				for _, partition := range assignment.Partitions {
+					if ps, ok := partitions[topicPartition{assignment.Topic, partition}]; ok {
+						ps.Assigned = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, ps := range partitions {
+		ps.Lag = partitionLag(ps)
+	}
+
+	return partitions, nil
+}
+
+// partitionLag counts the messages left to read. A partition without a
+// committed offset has its whole retained range pending, from the first
+// offset still on the broker up to the high water mark.
+func partitionLag(ps *PartitionStats) int64 {
+	from := ps.CommittedOffset
+	if from < 0 {
+		from = ps.FirstOffset
+	}
+	if from < 0 {
+		from = 0
+	}
+
+	if lag := ps.HighWaterMark - from; lag > 0 {
+		return lag
+	}
+	return 0
+}
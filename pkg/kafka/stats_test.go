@@ -0,0 +1,30 @@
+package kafka
+
+import "testing"
+
+func TestPartitionLag(t *testing.T) {
+	tests := []struct {
+		name      string
+		committed int64
+		first     int64
+		hwm       int64
+		want      int64
+	}{
+		{name: "committed", committed: 90, first: 40, hwm: 100, want: 10},
+		{name: "caught up", committed: 100, first: 40, hwm: 100, want: 0},
+		{name: "committed past the high water mark", committed: 120, first: 40, hwm: 100, want: 0},
+		{name: "no commit after retention", committed: -1, first: 40, hwm: 100, want: 60},
+		{name: "no commit on a fresh topic", committed: -1, first: 0, hwm: 100, want: 100},
+		{name: "no commit on an empty topic", committed: -1, first: 100, hwm: 100, want: 0},
+		{name: "no first offset", committed: -1, first: -1, hwm: 100, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := &PartitionStats{CommittedOffset: tt.committed, FirstOffset: tt.first, HighWaterMark: tt.hwm}
+			if got := partitionLag(ps); got != tt.want {
+				t.Errorf("lag = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
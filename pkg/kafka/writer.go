@@ -18,13 +18,11 @@ const (
 	writerAsync        = false
 )
 
-func NewWriter(brokers []string, topic string) *kafka.Writer {
+func NewWriter(brokers []string, topic string, auth *Auth) *kafka.Writer {
 	return kafka.NewWriter(kafka.WriterConfig{
-		Brokers: brokers,
-		Topic:   topic,
-		Dialer: &kafka.Dialer{
-			Timeout: dialTimeout,
-		},
+		Brokers:          brokers,
+		Topic:            topic,
+		Dialer:           auth.Dialer(""),
 		Balancer:         &kafka.LeastBytes{},
 		MaxAttempts:      writerMaxAttempts,
 		BatchSize:        writerBatchSize,
@@ -1,73 +1,175 @@
 package logger
 
 import (
+	"encoding/json"
 	"log"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+)
+
+const (
+	EncodingConsole = "console"
+	EncodingJSON    = "json"
+
+	samplingTick = time.Second
 )
 
+var levelOrder = map[string]int{
+	constants.LEVEL_DEBUG: 0,
+	constants.LEVEL_INFO:  1,
+	constants.LEVEL_WARN:  2,
+	constants.LEVEL_ERROR: 3,
+	constants.LEVEL_FATAL: 4,
+}
+
 type Config struct {
-	Encoding string
+	Encoding        string `mapstructure:"ENCODING"`
+	Level           string `mapstructure:"LEVEL"`
+	SamplingInitial string `mapstructure:"SAMPLING_INITIAL"`
+	// Sampling is a comma separated list of LEVEL=N, keeping one of every N
+	// repeated lines with the same activity once SamplingInitial lines passed
+	// within a second.
+	Sampling   string `mapstructure:"SAMPLING"`
+	MaskRules  string `mapstructure:"MASK_RULES"`
+	RawPayload string `mapstructure:"RAW_PAYLOAD"`
 }
 
 type LogFields struct {
-	Timestamp         string
-	LogLevel          string
-	TransactionID     string
-	ServiceName       string
-	Endpoint          string
-	Protocol          string
-	MethodType        string
-	ExecutionType     string
-	ContentType       string
-	FunctionName      string
-	UserInfo          *UserInfo
-	ExecutionTime     string
-	ServerIP          string
-	ClientIP          string
-	EventName         string
-	TraceID           string
-	PrevTransactionID string
-	Body              string
-	Result            string
-	Error             string
-	FlagStartOrStop   string
-	Message           *Message
+	Timestamp         string    `json:"timestamp"`
+	LogLevel          string    `json:"log_level"`
+	TransactionID     string    `json:"transaction_id"`
+	ServiceName       string    `json:"service_name"`
+	Endpoint          string    `json:"endpoint"`
+	Protocol          string    `json:"protocol"`
+	MethodType        string    `json:"method_type"`
+	ExecutionType     string    `json:"execution_type"`
+	ContentType       string    `json:"content_type"`
+	FunctionName      string    `json:"function_name"`
+	UserInfo          *UserInfo `json:"user_info"`
+	ExecutionTime     string    `json:"execution_time"`
+	ServerIP          string    `json:"server_ip"`
+	ClientIP          string    `json:"client_ip"`
+	EventName         string    `json:"event_name"`
+	TraceID           string    `json:"trace_id"`
+	PrevTransactionID string    `json:"prev_transaction_id"`
+	Body              string    `json:"body"`
+	Result            string    `json:"result"`
+	Error             string    `json:"error"`
+	FlagStartOrStop   string    `json:"flag_start_or_stop"`
+	Message           *Message  `json:"message"`
 }
 
 type UserInfo struct {
-	Username string
-	Role     string
-	Others   string
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Others   string `json:"others"`
 }
 
 type Message struct {
-	Activity          string
-	ObjectPerformedOn string
-	ResultOfActivity  string
-	ErrorCode         string
-	ErrorMessage      string
-	ShortDescription  string
+	Activity          string `json:"activity"`
+	ObjectPerformedOn string `json:"object_performed_on"`
+	ResultOfActivity  string `json:"result_of_activity"`
+	ErrorCode         string `json:"error_code"`
+	ErrorMessage      string `json:"error_message"`
+	ShortDescription  string `json:"short_description"`
 }
 
 type AppLogger struct {
-	logger *log.Logger
+	logger   *log.Logger
+	encoding string
+	minLevel int
+	sampler  *sampler
+	redactor *Redactor
+}
+
+func NewAppLogger(cfg *Config) *AppLogger {
+	al := &AppLogger{
+		logger:   log.New(os.Stdout, "", 0),
+		encoding: EncodingConsole,
+		minLevel: levelOrder[constants.LEVEL_INFO],
+		redactor: NewRedactor("", false),
+	}
+
+	if cfg == nil {
+		return al
+	}
+
+	rawPayload, _ := strconv.ParseBool(cfg.RawPayload)
+	al.redactor = NewRedactor(cfg.MaskRules, rawPayload)
+
+	if strings.EqualFold(cfg.Encoding, EncodingJSON) {
+		al.encoding = EncodingJSON
+	}
+
+	if level, ok := levelOrder[strings.ToUpper(cfg.Level)]; ok {
+		al.minLevel = level
+	}
+
+	if thereafter := parseSampling(cfg.Sampling); len(thereafter) > 0 {
+		initial, _ := strconv.Atoi(cfg.SamplingInitial)
+		al.sampler = newSampler(initial, thereafter)
+	}
+
+	return al
 }
 
-func NewAppLogger() *AppLogger {
-	return &AppLogger{
-		logger: log.New(os.Stdout, "", 0),
+func (al *AppLogger) Enabled(level string) bool {
+	order, ok := levelOrder[strings.ToUpper(level)]
+	if !ok {
+		return true
 	}
+	return order >= al.minLevel
+}
+
+func (al *AppLogger) Redact(data interface{}) string {
+	return al.redactor.Redact(data)
 }
 
 func (al *AppLogger) StructuredPrint(lf *LogFields) {
+	if !al.Enabled(lf.LogLevel) {
+		return
+	}
+
+	if al.sampler != nil && lf.Message != nil && !al.sampler.allow(lf.LogLevel, lf.Message.Activity) {
+		return
+	}
+
+	if lf.Message != nil && !al.redactor.raw {
+		lf.Message.ErrorMessage = MaskText(lf.Message.ErrorMessage)
+	}
+
+	if al.encoding == EncodingJSON {
+		al.printJSON(lf)
+		return
+	}
+
+	al.printConsole(lf)
+}
+
+func (al *AppLogger) printConsole(lf *LogFields) {
 	wrapEmptyFields(lf)
 
-	logString := lf.Timestamp + " [" + lf.LogLevel + "] \t " + lf.TransactionID + " \t " + lf.ServiceName + " \t " + lf.Endpoint + " \t " + lf.Protocol + " \t " + lf.MethodType + " \t " + lf.ExecutionType + " \t " + lf.ContentType + " \t " + lf.FunctionName + " \t '" + lf.UserInfo.Username + "' as '" + lf.UserInfo.Role + "' . '" + lf.UserInfo.Others + "' \t " + lf.ExecutionTime + " ms \t " + lf.ServerIP + " \t " + lf.ClientIP + " \t " + lf.EventName + " \t " + lf.TraceID + " \t " + lf.PrevTransactionID + " \t " + lf.Body + " \t " + lf.Result + " \t " + lf.Error + " \t [" + lf.FlagStartOrStop + "] \t '" + lf.Message.Activity + "' on '" + lf.Message.ObjectPerformedOn + "' with result '" + lf.Message.ShortDescription + "' with error '" + lf.Message.ErrorMessage + "' : '" + lf.Message.ErrorCode + "' . '" + lf.Message.ShortDescription + "'"
+	logString := lf.Timestamp + " [" + lf.LogLevel + "] \t " + lf.TransactionID + " \t " + lf.ServiceName + " \t " + lf.Endpoint + " \t " + lf.Protocol + " \t " + lf.MethodType + " \t " + lf.ExecutionType + " \t " + lf.ContentType + " \t " + lf.FunctionName + " \t '" + lf.UserInfo.Username + "' as '" + lf.UserInfo.Role + "' . '" + lf.UserInfo.Others + "' \t " + lf.ExecutionTime + " ms \t " + lf.ServerIP + " \t " + lf.ClientIP + " \t " + lf.EventName + " \t " + lf.TraceID + " \t " + lf.PrevTransactionID + " \t " + lf.Body + " \t " + lf.Result + " \t " + lf.Error + " \t [" + lf.FlagStartOrStop + "] \t '" + lf.Message.Activity + "' on '" + lf.Message.ObjectPerformedOn + "' with result '" + lf.Message.ResultOfActivity + "' with error '" + lf.Message.ErrorMessage + "' : '" + lf.Message.ErrorCode + "' . '" + lf.Message.ShortDescription + "'"
 
 	al.logger.Println(logString)
 }
 
+func (al *AppLogger) printJSON(lf *LogFields) {
+	logBytes, err := json.Marshal(lf)
+	if err != nil {
+		al.logger.Println("Failed to marshal log fields:", err)
+		return
+	}
+
+	al.logger.Println(string(logBytes))
+}
+
 func wrapEmptyFields(fields interface{}) {
 	v := reflect.ValueOf(fields).Elem()
 
@@ -85,3 +187,65 @@ func wrapEmptyFields(fields interface{}) {
 		}
 	}
 }
+
+type sampler struct {
+	mu         sync.Mutex
+	initial    int
+	thereafter map[string]int
+	resetAt    time.Time
+	counts     map[string]int
+}
+
+func newSampler(initial int, thereafter map[string]int) *sampler {
+	return &sampler{
+		initial:    initial,
+		thereafter: thereafter,
+		counts:     make(map[string]int),
+	}
+}
+
+func parseSampling(raw string) map[string]int {
+	thereafter := make(map[string]int)
+
+	for _, rule := range strings.Split(raw, ",") {
+		level, value, found := strings.Cut(strings.TrimSpace(rule), "=")
+		if !found {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 1 {
+			continue
+		}
+
+		thereafter[strings.ToUpper(strings.TrimSpace(level))] = n
+	}
+
+	return thereafter
+}
+
+func (s *sampler) allow(level, activity string) bool {
+	n, ok := s.thereafter[strings.ToUpper(level)]
+	if !ok {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.After(s.resetAt) {
+		s.resetAt = now.Add(samplingTick)
+		s.counts = make(map[string]int)
+	}
+
+	key := level + "|" + activity
+	s.counts[key]++
+	count := s.counts[key]
+
+	if count <= s.initial {
+		return true
+	}
+
+	return (count-s.initial)%n == 0
+}
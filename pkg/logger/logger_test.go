@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/constants"
+)
+
+// newTestLogger builds a logger from cfg that writes into the returned buffer.
+func newTestLogger(cfg *Config) (*AppLogger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	al := NewAppLogger(cfg)
+	al.logger = log.New(&buf, "", 0)
+	return al, &buf
+}
+
+func lines(buf *bytes.Buffer) []string {
+	out := strings.TrimSpace(buf.String())
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+func TestStructuredPrintJSON(t *testing.T) {
+	al, buf := newTestLogger(&Config{Encoding: "JSON"})
+
+	al.StructuredPrint(&LogFields{
+		LogLevel:    constants.LEVEL_INFO,
+		ServiceName: "dispatch-service",
+		Message: &Message{
+			Activity:     "Send sms",
+			ErrorMessage: "invalid number 081234567890",
+		},
+	})
+
+	got := lines(buf)
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(got), buf.String())
+	}
+
+	var lf LogFields
+	if err := json.Unmarshal([]byte(got[0]), &lf); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if lf.LogLevel != constants.LEVEL_INFO || lf.ServiceName != "dispatch-service" {
+		t.Errorf("fields = %+v", lf)
+	}
+	if lf.Message == nil || lf.Message.Activity != "Send sms" {
+		t.Fatalf("message = %+v", lf.Message)
+	}
+	// Empty fields stay empty in JSON, only the console encoding fills them.
+	if lf.Endpoint != "" {
+		t.Errorf("endpoint = %q, want empty", lf.Endpoint)
+	}
+	if strings.Contains(lf.Message.ErrorMessage, "081234567890") {
+		t.Errorf("error message not masked: %q", lf.Message.ErrorMessage)
+	}
+}
+
+func TestStructuredPrintConsole(t *testing.T) {
+	al, buf := newTestLogger(&Config{})
+
+	al.StructuredPrint(&LogFields{
+		Timestamp: "2024-01-01T00:00:00Z",
+		LogLevel:  constants.LEVEL_WARN,
+		UserInfo:  &UserInfo{},
+		Message:   &Message{Activity: "Send sms"},
+	})
+
+	got := buf.String()
+	if !strings.HasPrefix(got, "2024-01-01T00:00:00Z [WARN]") {
+		t.Errorf("line = %q", got)
+	}
+	if !strings.Contains(got, "'Send sms' on '-'") {
+		t.Errorf("empty fields not filled: %q", got)
+	}
+}
+
+func TestStructuredPrintDropsLowerLevels(t *testing.T) {
+	al, buf := newTestLogger(&Config{Encoding: EncodingJSON, Level: "warn"})
+
+	for _, level := range []string{
+		constants.LEVEL_DEBUG,
+		constants.LEVEL_INFO,
+		constants.LEVEL_WARN,
+		constants.LEVEL_ERROR,
+	} {
+		al.StructuredPrint(&LogFields{LogLevel: level, Message: &Message{}})
+	}
+
+	got := lines(buf)
+	if len(got) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(got), buf.String())
+	}
+	for i, want := range []string{constants.LEVEL_WARN, constants.LEVEL_ERROR} {
+		var lf LogFields
+		if err := json.Unmarshal([]byte(got[i]), &lf); err != nil {
+			t.Fatal(err)
+		}
+		if lf.LogLevel != want {
+			t.Errorf("line %d level = %s, want %s", i, lf.LogLevel, want)
+		}
+	}
+}
+
+func TestStructuredPrintSampling(t *testing.T) {
+	al, buf := newTestLogger(&Config{
+		Encoding:        EncodingJSON,
+		SamplingInitial: "3",
+		Sampling:        "INFO=4",
+	})
+
+	for i := 0; i < 11; i++ {
+		al.StructuredPrint(&LogFields{LogLevel: constants.LEVEL_INFO, Message: &Message{Activity: "Send sms"}})
+	}
+	// The first 3 pass, then one in every 4 of the remaining 8.
+	if got := len(lines(buf)); got != 5 {
+		t.Errorf("sampled activity logged %d lines, want 5", got)
+	}
+
+	buf.Reset()
+	al.StructuredPrint(&LogFields{LogLevel: constants.LEVEL_INFO, Message: &Message{Activity: "Send email"}})
+	al.StructuredPrint(&LogFields{LogLevel: constants.LEVEL_WARN, Message: &Message{Activity: "Send sms"}})
+	if got := len(lines(buf)); got != 2 {
+		t.Errorf("other level or activity logged %d lines, want 2", got)
+	}
+}
+
+func TestParseSampling(t *testing.T) {
+	got := parseSampling(" info=10, WARN = 2,error=1,debug=x,bad")
+	if len(got) != 2 || got["INFO"] != 10 || got["WARN"] != 2 {
+		t.Errorf("parseSampling = %v", got)
+	}
+}
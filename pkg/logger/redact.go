@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	MaskMsisdn = "msisdn"
+	MaskEmail  = "email"
+	MaskToken  = "token"
+	MaskDigits = "digits"
+	MaskFull   = "full"
+	MaskNone   = "none"
+	// MaskRecipient picks the mask from the value, fields like "to" hold a
+	// phone number, an address or a device token depending on the provider.
+	MaskRecipient = "recipient"
+
+	maskChar = "*"
+)
+
+var defaultMaskRules = map[string]string{
+	"recipient_phone_number": MaskMsisdn,
+	"msisdn":                 MaskMsisdn,
+	"recipient_to":           MaskEmail,
+	"recipient_cc":           MaskEmail,
+	"recipient_bcc":          MaskEmail,
+	"player_ids":             MaskToken,
+	"to":                     MaskRecipient,
+	"registration_ids":       MaskToken,
+	"include_player_ids":     MaskToken,
+	"endpoint":               MaskToken,
+	"recipient":              MaskRecipient,
+	"p256dh":                 MaskToken,
+	"auth":                   MaskFull,
+	"content":                MaskDigits,
+	"content_html":           MaskDigits,
+	"txt":                    MaskDigits,
+	"parameters":             MaskDigits,
+	"body":                   MaskDigits,
+	"attachment":             MaskFull,
+	"password":               MaskFull,
+}
+
+// msisdnExpr matches Indonesian mobile numbers, which may be grouped with
+// spaces or dashes, e.g. +62 812-3456-7890.
+const msisdnExpr = `(?:\+?62|0)[ -]?8[0-9]{2}(?:[ -]?[0-9]){5,9}`
+
+var (
+	emailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	msisdnPattern      = regexp.MustCompile(msisdnExpr)
+	msisdnValuePattern = regexp.MustCompile(`^` + msisdnExpr + `$`)
+	digitsPattern      = regexp.MustCompile(`[0-9]{4,}`)
+)
+
+type Redactor struct {
+	rules map[string]string
+	raw   bool
+}
+
+// NewRedactor builds a redactor from the default rules overridden by a comma
+// separated list of field=strategy. When raw is true payloads are logged
+// unmasked, which is meant for local debugging only.
+func NewRedactor(rules string, raw bool) *Redactor {
+	r := &Redactor{
+		rules: make(map[string]string, len(defaultMaskRules)),
+		raw:   raw,
+	}
+
+	for field, strategy := range defaultMaskRules {
+		r.rules[field] = strategy
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		field, strategy, found := strings.Cut(strings.TrimSpace(rule), "=")
+		if !found {
+			continue
+		}
+		r.rules[strings.ToLower(strings.TrimSpace(field))] = strings.ToLower(strings.TrimSpace(strategy))
+	}
+
+	return r
+}
+
+func (r *Redactor) Redact(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+
+	if r.raw {
+		return fmt.Sprint(data)
+	}
+
+	var value interface{}
+
+	switch d := data.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(d), &value); err != nil {
+			return MaskText(d)
+		}
+	case []byte:
+		if err := json.Unmarshal(d, &value); err != nil {
+			return MaskText(string(d))
+		}
+	case error:
+		return MaskText(d.Error())
+	default:
+		dataBytes, err := json.Marshal(d)
+		if err != nil {
+			return MaskText(fmt.Sprint(d))
+		}
+		if err := json.Unmarshal(dataBytes, &value); err != nil {
+			return MaskText(string(dataBytes))
+		}
+	}
+
+	maskedBytes, err := json.Marshal(r.mask("", value))
+	if err != nil {
+		return ""
+	}
+
+	return string(maskedBytes)
+}
+
+func (r *Redactor) mask(field string, value interface{}) interface{} {
+	strategy, hasRule := r.rules[strings.ToLower(field)]
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = r.mask(key, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.mask(field, child)
+		}
+		return v
+	case string:
+		if embedded, ok := decodeEmbedded(v); ok {
+			return r.mask(field, embedded)
+		}
+		if hasRule {
+			return MaskValue(strategy, v)
+		}
+		return MaskText(v)
+	default:
+		if hasRule && strategy == MaskFull {
+			return strings.Repeat(maskChar, 3)
+		}
+		return v
+	}
+}
+
+// decodeEmbedded unwraps a JSON object or array carried inside a string,
+// either as JSON text or as the base64 form encoding/json gives []byte such
+// as the envelope data, so the field rules reach into it.
+func decodeEmbedded(value string) (interface{}, bool) {
+	raw := []byte(strings.TrimSpace(value))
+	if len(raw) == 0 {
+		return nil, false
+	}
+
+	if raw[0] != '{' && raw[0] != '[' {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, false
+		}
+		raw = []byte(strings.TrimSpace(string(decoded)))
+		if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+			return nil, false
+		}
+	}
+
+	var embedded interface{}
+	if err := json.Unmarshal(raw, &embedded); err != nil {
+		return nil, false
+	}
+	return embedded, true
+}
+
+func MaskValue(strategy string, value string) string {
+	switch strategy {
+	case MaskMsisdn:
+		return MaskPhoneNumber(value)
+	case MaskEmail:
+		return MaskEmailAddress(value)
+	case MaskToken:
+		return MaskSecret(value)
+	case MaskRecipient:
+		return MaskRecipientValue(value)
+	case MaskDigits:
+		return MaskText(digitsPattern.ReplaceAllStringFunc(value, maskAll))
+	case MaskFull:
+		return strings.Repeat(maskChar, 3)
+	case MaskNone:
+		return value
+	}
+	return MaskText(value)
+}
+
+// MaskText masks email addresses and phone numbers found in free text such
+// as provider responses and error messages.
+func MaskText(text string) string {
+	text = emailPattern.ReplaceAllStringFunc(text, MaskEmailAddress)
+	return msisdnPattern.ReplaceAllStringFunc(text, MaskPhoneNumber)
+}
+
+func MaskPhoneNumber(phone string) string {
+	return maskMiddle(phone, 4, 2)
+}
+
+func MaskEmailAddress(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return maskMiddle(email, 1, 0)
+	}
+	return maskMiddle(local, 1, 0) + "@" + domain
+}
+
+// MaskRecipientValue masks value as a phone number or an email address when
+// it is one, and as a token otherwise.
+func MaskRecipientValue(value string) string {
+	switch {
+	case msisdnValuePattern.MatchString(strings.TrimSpace(value)):
+		return MaskPhoneNumber(value)
+	case emailPattern.MatchString(value):
+		return MaskEmailAddress(value)
+	}
+	return MaskSecret(value)
+}
+
+func MaskSecret(secret string) string {
+	return maskMiddle(secret, 4, 4)
+}
+
+func maskMiddle(value string, keepStart, keepEnd int) string {
+	runes := []rune(value)
+	if len(runes) <= keepStart+keepEnd {
+		return strings.Repeat(maskChar, len(runes))
+	}
+	return string(runes[:keepStart]) + strings.Repeat(maskChar, len(runes)-keepStart-keepEnd) + string(runes[len(runes)-keepEnd:])
+}
+
+func maskAll(value string) string {
+	return strings.Repeat(maskChar, len(value))
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type testEnvelope struct {
+	CategoryName string `json:"category_name"`
+	Data         []byte `json:"data"`
+}
+
+func TestRedactEmbeddedData(t *testing.T) {
+	r := NewRedactor("", false)
+
+	payload := []byte(`{"recipient_phone_number":"081234567890","content":"OTP 123456","attachment":"JVBERi0xLjQ="}`)
+
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{name: "base64 data field", data: &testEnvelope{CategoryName: "sms", Data: payload}},
+		{name: "raw bytes", data: payload},
+		{name: "json string field", data: map[string]string{"data": string(payload)}},
+		{name: "nested list", data: map[string]interface{}{"messages": []interface{}{&testEnvelope{Data: payload}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Redact(tt.data)
+
+			for _, leaked := range []string{"081234567890", "123456", "JVBERi0xLjQ="} {
+				if strings.Contains(got, leaked) {
+					t.Fatalf("Redact leaked %q: %s", leaked, got)
+				}
+			}
+			if !strings.Contains(got, "0812******90") {
+				t.Fatalf("Redact did not mask the phone number in data: %s", got)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("Redact returned invalid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestRedactKeepsPlainStrings(t *testing.T) {
+	r := NewRedactor("", false)
+
+	// "dGVzdA==" is base64 for "test", which is not JSON and stays as is.
+	got := r.Redact(map[string]string{"token_id": "dGVzdA==", "status": "sent"})
+	if got != `{"status":"sent","token_id":"dGVzdA=="}` {
+		t.Fatalf("Redact = %s", got)
+	}
+}
+
+func TestRedactRules(t *testing.T) {
+	r := NewRedactor("content=none, password=full", false)
+
+	got := r.Redact(map[string]interface{}{
+		"content":       "OTP 123456",
+		"recipient_to":  []string{"budi@example.com"},
+		"password":      42,
+		"error_message": "failed for 081234567890",
+	})
+
+	want := `{"content":"OTP 123456","error_message":"failed for 0812******90","password":"***","recipient_to":["b***@example.com"]}`
+	if got != want {
+		t.Fatalf("Redact = %s, want %s", got, want)
+	}
+}
+
+func TestRedactRaw(t *testing.T) {
+	r := NewRedactor("", true)
+
+	if got := r.Redact("081234567890"); got != "081234567890" {
+		t.Fatalf("Redact = %s", got)
+	}
+}
+
+func TestRedactRecipientFields(t *testing.T) {
+	r := NewRedactor("", false)
+
+	tests := []struct {
+		name string
+		data map[string]string
+		want string
+	}{
+		{name: "whatsapp msisdn", data: map[string]string{"to": "6281234567890"}, want: `{"to":"6281*******90"}`},
+		{name: "grouped msisdn", data: map[string]string{"recipient": "+62 812-3456-7890"}, want: `{"recipient":"+62 ***********90"}`},
+		{name: "email", data: map[string]string{"to": "budi@example.com"}, want: `{"to":"b***@example.com"}`},
+		{name: "device token", data: map[string]string{"to": "fcm-token-abcdefgh"}, want: `{"to":"fcm-**********efgh"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.data); got != tt.want {
+				t.Errorf("Redact = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskTextPhoneNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "failed for 081234567890", want: "failed for 0812******90"},
+		{text: "failed for +6281234567890.", want: "failed for +628********90."},
+		{text: "failed for +62 812-3456-7890", want: "failed for +62 ***********90"},
+		{text: "failed for 0812 3456 7890", want: "failed for 0812********90"},
+		{text: "failed for 0812-3456-789, retrying", want: "failed for 0812*******89, retrying"},
+		{text: "order 20240501 took 1500 ms", want: "order 20240501 took 1500 ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := MaskText(tt.text); got != tt.want {
+				t.Errorf("MaskText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
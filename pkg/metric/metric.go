@@ -3,8 +3,14 @@ package probes
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
+	otlpEndpoint "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/otlp_endpoint"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
@@ -12,11 +18,24 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
+const (
+	ExporterOtlpGrpc = "otlp-grpc"
+	ExporterOtlpHttp = "otlp-http"
+	ExporterNone     = "none"
+
+	defaultExportInterval = 30 * time.Second
+)
+
 type Config struct {
 	Path      string `mapstructure:"PATH"`
 	Port      string `mapstructure:"PORT"`
 	Prefix    string `mapstructure:"PREFIX"`
 	MeterName string `mapstructure:"METER_NAME"`
+	AdminPath string `mapstructure:"ADMIN_PATH"`
+	Exporter  string `mapstructure:"EXPORTER"`
+	Endpoint  string `mapstructure:"ENDPOINT"`
+	Insecure  string `mapstructure:"INSECURE"`
+	Interval  string `mapstructure:"INTERVAL"`
 }
 
 type AppMetric struct {
@@ -41,9 +60,25 @@ func NewAppMetric(ctx context.Context, cfg *Config, serviceName, version string)
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	metricProvider := sdkMetric.NewMeterProvider(
+	options := []sdkMetric.Option{
 		sdkMetric.WithResource(resource),
-		sdkMetric.WithReader(exporter))
+		sdkMetric.WithReader(exporter),
+	}
+
+	// The OTLP exporter runs alongside Prometheus, a collector outage must not
+	// prevent the service from starting.
+	otlpExporter, err := newOtlpExporter(ctx, cfg)
+	if err != nil {
+		fmt.Println("Failed to initialize OTLP metric exporter:", err)
+	} else if otlpExporter != nil {
+		interval, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			interval = defaultExportInterval
+		}
+		options = append(options, sdkMetric.WithReader(sdkMetric.NewPeriodicReader(otlpExporter, sdkMetric.WithInterval(interval))))
+	}
+
+	metricProvider := sdkMetric.NewMeterProvider(options...)
 
 	ap := &AppMetric{
 		MetricProvider: metricProvider,
@@ -52,5 +87,39 @@ func NewAppMetric(ctx context.Context, cfg *Config, serviceName, version string)
 
 	otel.SetMeterProvider(metricProvider)
 
-	return ap, err
+	return ap, nil
+}
+
+func newOtlpExporter(ctx context.Context, cfg *Config) (sdkMetric.Exporter, error) {
+	exporter := strings.ToLower(cfg.Exporter)
+	if exporter == "" || exporter == ExporterNone {
+		return nil, nil
+	}
+
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is missing in the metric configuration")
+	}
+
+	endpoint, path, insecure := otlpEndpoint.Parse(cfg.Endpoint, cfg.Insecure)
+
+	switch exporter {
+	case ExporterOtlpGrpc:
+		options := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+		if insecure {
+			options = append(options, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, options...)
+
+	case ExporterOtlpHttp:
+		options := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+		if path != "" {
+			options = append(options, otlpmetrichttp.WithURLPath(path))
+		}
+		if insecure {
+			options = append(options, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, options...)
+	}
+
+	return nil, fmt.Errorf("unsupported metric exporter: %s", cfg.Exporter)
 }
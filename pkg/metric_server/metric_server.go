@@ -24,6 +24,12 @@ func NewMetricServer(path string, serviceName string) *MetricServer {
 	}
 }
 
+func (ms *MetricServer) AddJSONHandler(path string, fn func() interface{}) {
+	ms.server.GET(path, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, fn())
+	})
+}
+
 func (ms *MetricServer) Run(port string) error {
 	if err := ms.server.Start(port); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("failed to run server: %w", err)
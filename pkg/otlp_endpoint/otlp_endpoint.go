@@ -0,0 +1,25 @@
+package otlp_endpoint
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Parse reads the endpoint of an OTLP exporter, either host:port or a full
+// URL. A URL also gives the path, and its scheme decides whether the
+// connection is insecure, otherwise insecure is parsed as a bool.
+func Parse(endpoint string, insecure string) (string, string, bool) {
+	isInsecure, _ := strconv.ParseBool(insecure)
+
+	if !strings.Contains(endpoint, "://") {
+		return endpoint, "", isInsecure
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint, "", isInsecure
+	}
+
+	return u.Host, u.Path, u.Scheme == "http"
+}
@@ -0,0 +1,29 @@
+package otlp_endpoint
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		endpoint     string
+		insecure     string
+		wantHost     string
+		wantPath     string
+		wantInsecure bool
+	}{
+		{endpoint: "otel-collector:4317", insecure: "true", wantHost: "otel-collector:4317", wantInsecure: true},
+		{endpoint: "otel-collector:4317", insecure: "", wantHost: "otel-collector:4317"},
+		{endpoint: "http://otel-collector:4318/otlp/v1/traces", insecure: "false", wantHost: "otel-collector:4318", wantPath: "/otlp/v1/traces", wantInsecure: true},
+		{endpoint: "https://otel.example.com", insecure: "true", wantHost: "otel.example.com"},
+		{endpoint: "http://bad host", insecure: "true", wantHost: "http://bad host", wantInsecure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			host, path, insecure := Parse(tt.endpoint, tt.insecure)
+			if host != tt.wantHost || path != tt.wantPath || insecure != tt.wantInsecure {
+				t.Errorf("Parse(%q, %q) = %q, %q, %t, want %q, %q, %t",
+					tt.endpoint, tt.insecure, host, path, insecure, tt.wantHost, tt.wantPath, tt.wantInsecure)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	FormatE164  = "e164"
+	FormatLocal = "local"
+
+	OperatorUnknown = "unknown"
+
+	countryCode = "62"
+	// Indonesian mobile numbers have 9 to 12 digits after the country code.
+	minNationalLength = 9
+	maxNationalLength = 12
+)
+
+var ErrInvalidNumber = errors.New("invalid mobile number")
+
+// operators maps the first three national digits of a mobile number to its
+// operator.
+var operators = map[string]string{
+	"811": "telkomsel", "812": "telkomsel", "813": "telkomsel",
+	"821": "telkomsel", "822": "telkomsel", "823": "telkomsel",
+	"851": "telkomsel", "852": "telkomsel", "853": "telkomsel",
+	"814": "indosat", "815": "indosat", "816": "indosat",
+	"855": "indosat", "856": "indosat", "857": "indosat", "858": "indosat",
+	"817": "xl", "818": "xl", "819": "xl",
+	"859": "xl", "877": "xl", "878": "xl",
+	"831": "axis", "832": "axis", "833": "axis", "838": "axis",
+	"895": "tri", "896": "tri", "897": "tri", "898": "tri", "899": "tri",
+	"881": "smartfren", "882": "smartfren", "883": "smartfren",
+	"884": "smartfren", "885": "smartfren", "886": "smartfren",
+	"887": "smartfren", "888": "smartfren", "889": "smartfren",
+}
+
+// Number is a validated Indonesian mobile number.
+type Number struct {
+	// National holds the digits after the country code, e.g. 81212345678.
+	National string
+	Operator string
+}
+
+// Parse accepts +62, 62 and 0 prefixed numbers, ignoring spaces, dashes,
+// dots and parentheses.
+func Parse(raw string) (*Number, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+
+	var national string
+	switch {
+	case strings.HasPrefix(digits, "+"+countryCode):
+		national = digits[len(countryCode)+1:]
+	case strings.HasPrefix(digits, countryCode):
+		national = digits[len(countryCode):]
+	case strings.HasPrefix(digits, "0"):
+		national = digits[1:]
+	default:
+		return nil, fmt.Errorf("%w %q: must start with +62, 62 or 0", ErrInvalidNumber, raw)
+	}
+
+	for _, r := range national {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("%w %q: must only hold digits", ErrInvalidNumber, raw)
+		}
+	}
+
+	if len(national) < minNationalLength || len(national) > maxNationalLength {
+		return nil, fmt.Errorf("%w %q: has %d digits after the country code, want %d to %d", ErrInvalidNumber, raw, len(national), minNationalLength, maxNationalLength)
+	}
+
+	operator, ok := operators[national[:3]]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: %s is not a mobile prefix", ErrInvalidNumber, raw, national[:3])
+	}
+
+	return &Number{National: national, Operator: operator}, nil
+}
+
+// Format writes n as +628... for FormatE164 or 08... for FormatLocal.
+func (n *Number) Format(format string) string {
+	if format == FormatLocal {
+		return "0" + n.National
+	}
+	return "+" + countryCode + n.National
+}
+
+// ValidFormat reports whether format is one Format knows.
+func ValidFormat(format string) bool {
+	return format == FormatE164 || format == FormatLocal
+}
@@ -0,0 +1,114 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw      string
+		national string
+		operator string
+	}{
+		{raw: "081234567890", national: "81234567890", operator: "telkomsel"},
+		{raw: "6281234567890", national: "81234567890", operator: "telkomsel"},
+		{raw: "+6281234567890", national: "81234567890", operator: "telkomsel"},
+		{raw: "+62 812-3456-7890", national: "81234567890", operator: "telkomsel"},
+		{raw: "(0812) 3456.7890", national: "81234567890", operator: "telkomsel"},
+		{raw: " 0857 1234 5678 ", national: "85712345678", operator: "indosat"},
+		{raw: "0817123456", national: "817123456", operator: "xl"},
+		{raw: "0838123456789", national: "838123456789", operator: "axis"},
+		{raw: "0896-1234-5678", national: "89612345678", operator: "tri"},
+		{raw: "62 881 2345 6789", national: "88123456789", operator: "smartfren"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			number, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if number.National != tt.national || number.Operator != tt.operator {
+				t.Errorf("Parse = %+v, want %s of %s", number, tt.national, tt.operator)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "empty", raw: ""},
+		{name: "foreign country code", raw: "+1 202 555 0100"},
+		{name: "plus without country code", raw: "+081234567890"},
+		{name: "letter", raw: "0812345678a9"},
+		{name: "too short", raw: "081712345"},
+		{name: "too long", raw: "08171234567890"},
+		{name: "landline", raw: "021 5551 2345"},
+		{name: "not a mobile prefix", raw: "0800123456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, err := Parse(tt.raw)
+			if !errors.Is(err, ErrInvalidNumber) {
+				t.Errorf("Parse(%q) = %+v, %v, want ErrInvalidNumber", tt.raw, number, err)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	number := &Number{National: "81234567890", Operator: "telkomsel"}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: FormatLocal, want: "081234567890"},
+		{format: FormatE164, want: "+6281234567890"},
+		// Callers check ValidFormat first, anything else falls back to e164.
+		{format: "", want: "+6281234567890"},
+	}
+
+	for _, tt := range tests {
+		if got := number.Format(tt.format); got != tt.want {
+			t.Errorf("Format(%q) = %s, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, raw := range []string{"081234567890", "+62 857-1234-5678", "62817123456"} {
+		number, err := Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, format := range []string{FormatLocal, FormatE164} {
+			again, err := Parse(number.Format(format))
+			if err != nil || *again != *number {
+				t.Errorf("Parse(Format(%q)) = %+v, %v, want %+v", format, again, err, number)
+			}
+		}
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	tests := map[string]bool{
+		FormatLocal: true,
+		FormatE164:  true,
+		"":          false,
+		"E164":      false,
+		"national":  false,
+	}
+
+	for format, want := range tests {
+		if got := ValidFormat(format); got != want {
+			t.Errorf("ValidFormat(%q) = %t, want %t", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package schema_registry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedClient keeps schemas by id for good, they never change once
+// registered. The latest schema of a subject is refreshed after ttl.
+type CachedClient struct {
+	client Client
+	ttl    time.Duration
+
+	mu        sync.RWMutex
+	byID      map[int]*Schema
+	bySubject map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	schema    *Schema
+	fetchedAt time.Time
+}
+
+func NewCachedClient(client Client, ttl time.Duration) *CachedClient {
+	return &CachedClient{
+		client:    client,
+		ttl:       ttl,
+		byID:      make(map[int]*Schema),
+		bySubject: make(map[string]cachedSchema),
+	}
+}
+
+func (c *CachedClient) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema, err := c.client.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.byID[id] = schema
+	c.mu.Unlock()
+
+	return schema, nil
+}
+
+func (c *CachedClient) LatestSchema(ctx context.Context, subject string) (*Schema, error) {
+	c.mu.RLock()
+	cached, ok := c.bySubject[subject]
+	c.mu.RUnlock()
+	if ok && time.Since(cached.fetchedAt) < c.ttl {
+		return cached.schema, nil
+	}
+
+	schema, err := c.client.LatestSchema(ctx, subject)
+	if err != nil {
+		// A stale schema beats failing while the registry is unreachable.
+		if ok {
+			return cached.schema, nil
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.bySubject[subject] = cachedSchema{schema: schema, fetchedAt: time.Now()}
+	c.byID[schema.ID] = schema
+	c.mu.Unlock()
+
+	return schema, nil
+}
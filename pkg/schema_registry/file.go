@@ -0,0 +1,61 @@
+package schema_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileClient is a stand-in registry read from a JSON array of schemas in the
+// REST response format. The latest schema of a subject is the one with the
+// highest version.
+type FileClient struct {
+	byID      map[int]*Schema
+	bySubject map[string]*Schema
+}
+
+func NewFileClient(path string) (*FileClient, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*Schema
+	if err := json.Unmarshal(b, &schemas); err != nil {
+		return nil, fmt.Errorf("schema registry file %s: %w", path, err)
+	}
+
+	c := &FileClient{
+		byID:      make(map[int]*Schema, len(schemas)),
+		bySubject: make(map[string]*Schema),
+	}
+	for _, schema := range schemas {
+		c.byID[schema.ID] = schema
+
+		if schema.Subject == "" {
+			continue
+		}
+		if latest, ok := c.bySubject[schema.Subject]; !ok || schema.Version > latest.Version {
+			c.bySubject[schema.Subject] = schema
+		}
+	}
+
+	return c, nil
+}
+
+func (c *FileClient) SchemaByID(_ context.Context, id int) (*Schema, error) {
+	schema, ok := c.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("schema %d: %w", id, ErrNotFound)
+	}
+	return schema, nil
+}
+
+func (c *FileClient) LatestSchema(_ context.Context, subject string) (*Schema, error) {
+	schema, ok := c.bySubject[subject]
+	if !ok {
+		return nil, fmt.Errorf("subject %s: %w", subject, ErrNotFound)
+	}
+	return schema, nil
+}
@@ -0,0 +1,82 @@
+package schema_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const httpTimeout = 10 * time.Second
+
+// HTTPClient talks to a Confluent compatible schema registry.
+type HTTPClient struct {
+	baseUrl  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewHTTPClient(baseUrl, username, password string) *HTTPClient {
+	return &HTTPClient{
+		baseUrl:  strings.TrimSuffix(baseUrl, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (c *HTTPClient) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	schema := &Schema{}
+	if err := c.get(ctx, "/schemas/ids/"+strconv.Itoa(id), schema); err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	schema.ID = id
+
+	return schema, nil
+}
+
+func (c *HTTPClient) LatestSchema(ctx context.Context, subject string) (*Schema, error) {
+	schema := &Schema{}
+	if err := c.get(ctx, "/subjects/"+url.PathEscape(subject)+"/versions/latest", schema); err != nil {
+		return nil, fmt.Errorf("subject %s: %w", subject, err)
+	}
+
+	return schema, nil
+}
+
+func (c *HTTPClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+path+"?format=serialized", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %d: %s", resp.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,86 @@
+package schema_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+	TypeJSON     = "JSON"
+
+	defaultCacheTTL = 5 * time.Minute
+)
+
+var ErrNotFound = errors.New("schema not found")
+
+// Config selects the registry. File takes precedence over Url so tests and
+// local runs can work from a JSON file instead of a running registry.
+type Config struct {
+	Url      string `mapstructure:"URL"`
+	Username string `mapstructure:"USERNAME"`
+	Password string `mapstructure:"PASSWORD"`
+	File     string `mapstructure:"FILE"`
+	CacheTTL string `mapstructure:"CACHE_TTL"`
+}
+
+// Schema is a registered schema as returned by the Confluent REST API. An
+// empty SchemaType means AVRO. Protobuf schemas are fetched in the serialized
+// format, Schema then holds a base64 FileDescriptorProto.
+type Schema struct {
+	ID         int    `json:"id"`
+	Subject    string `json:"subject,omitempty"`
+	Version    int    `json:"version,omitempty"`
+	SchemaType string `json:"schemaType,omitempty"`
+	Schema     string `json:"schema"`
+}
+
+func (s *Schema) Type() string {
+	if s.SchemaType == "" {
+		return TypeAvro
+	}
+	return s.SchemaType
+}
+
+type Client interface {
+	SchemaByID(ctx context.Context, id int) (*Schema, error)
+	LatestSchema(ctx context.Context, subject string) (*Schema, error)
+}
+
+// NewClient returns the configured registry behind a cache, or nil when
+// neither File nor Url is set.
+func NewClient(cfg *Config) (Client, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	ttl := defaultCacheTTL
+	if cfg.CacheTTL != "" {
+		d, err := time.ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("schema registry cache ttl: %w", err)
+		}
+		ttl = d
+	}
+
+	switch {
+	case cfg.File != "":
+		client, err := NewFileClient(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return NewCachedClient(client, ttl), nil
+	case cfg.Url != "":
+		return NewCachedClient(NewHTTPClient(cfg.Url, cfg.Username, cfg.Password), ttl), nil
+	}
+
+	return nil, nil
+}
+
+// Subject follows the topic name strategy of the Confluent serializers.
+func Subject(topic string) string {
+	return topic + "-value"
+}
@@ -0,0 +1,480 @@
+package serde
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// avroSchema is a parsed Avro schema. Values map to the JSON types the
+// envelope uses: records and maps to objects, bytes and fixed to base64
+// strings, enums to their symbol, and unions to the bare branch value.
+type avroSchema struct {
+	kind     string
+	name     string
+	fields   []avroField
+	symbols  []string
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name       string
+	schema     *avroSchema
+	defaultVal json.RawMessage
+}
+
+func parseAvroSchema(s string) (*avroSchema, error) {
+	return parseAvro(json.RawMessage(s), "", make(map[string]*avroSchema))
+}
+
+func parseAvro(raw json.RawMessage, namespace string, named map[string]*avroSchema) (*avroSchema, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: empty avro schema", ErrInvalid)
+	}
+
+	switch raw[0] {
+	case '"':
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return nil, err
+		}
+		return avroNamed(name, namespace, named)
+	case '[':
+		var branches []json.RawMessage
+		if err := json.Unmarshal(raw, &branches); err != nil {
+			return nil, err
+		}
+
+		union := &avroSchema{kind: "union"}
+		for _, branch := range branches {
+			parsed, err := parseAvro(branch, namespace, named)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, parsed)
+		}
+		return union, nil
+	}
+
+	var def struct {
+		Type      json.RawMessage `json:"type"`
+		Name      string          `json:"name"`
+		Namespace string          `json:"namespace"`
+		Fields    []struct {
+			Name    string          `json:"name"`
+			Type    json.RawMessage `json:"type"`
+			Default json.RawMessage `json:"default"`
+		} `json:"fields"`
+		Symbols []string        `json:"symbols"`
+		Items   json.RawMessage `json:"items"`
+		Values  json.RawMessage `json:"values"`
+		Size    int             `json:"size"`
+	}
+	if err := json.Unmarshal(raw, &def); err != nil {
+		return nil, fmt.Errorf("%w: avro schema: %v", ErrInvalid, err)
+	}
+
+	var kind string
+	if err := json.Unmarshal(def.Type, &kind); err != nil {
+		// {"type": {...}} nests a full schema.
+		return parseAvro(def.Type, namespace, named)
+	}
+
+	s := &avroSchema{kind: kind}
+	if def.Namespace != "" {
+		namespace = def.Namespace
+	}
+
+	switch kind {
+	case "record", "error", "enum", "fixed":
+		s.kind = strings.Replace(kind, "error", "record", 1)
+		s.name = qualify(def.Name, namespace)
+		named[s.name] = s
+		if i := strings.LastIndex(s.name, "."); i >= 0 {
+			namespace = s.name[:i]
+		}
+	}
+
+	var err error
+	switch s.kind {
+	case "record":
+		for _, f := range def.Fields {
+			field := avroField{name: f.Name, defaultVal: f.Default}
+			if field.schema, err = parseAvro(f.Type, namespace, named); err != nil {
+				return nil, err
+			}
+			s.fields = append(s.fields, field)
+		}
+	case "enum":
+		s.symbols = def.Symbols
+	case "fixed":
+		s.size = def.Size
+	case "array":
+		s.items, err = parseAvro(def.Items, namespace, named)
+	case "map":
+		s.values, err = parseAvro(def.Values, namespace, named)
+	default:
+		return avroNamed(kind, namespace, named)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func avroNamed(name, namespace string, named map[string]*avroSchema) (*avroSchema, error) {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return &avroSchema{kind: name}, nil
+	}
+
+	if s, ok := named[qualify(name, namespace)]; ok {
+		return s, nil
+	}
+	if s, ok := named[name]; ok {
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown avro type %q", ErrInvalid, name)
+}
+
+func qualify(name, namespace string) string {
+	if namespace == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// maxEmptyItems bounds a block beyond its byte length, items of null or
+// empty records take no bytes at all.
+const maxEmptyItems = 1 << 16
+
+type avroReader struct {
+	b []byte
+}
+
+func (r *avroReader) long() (int64, error) {
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad avro varint", ErrInvalid)
+	}
+	r.b = r.b[n:]
+	return v, nil
+}
+
+func (r *avroReader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b) {
+		return nil, fmt.Errorf("%w: avro value is truncated", ErrInvalid)
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b, nil
+}
+
+func (r *avroReader) bytes() ([]byte, error) {
+	n, err := r.long()
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(n))
+}
+
+// blocks reads the block encoding of arrays and maps, calling fn per item.
+func (r *avroReader) blocks(fn func() error) error {
+	for {
+		count, err := r.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			count = -count
+			// The block size in bytes only helps skipping.
+			if _, err := r.long(); err != nil {
+				return err
+			}
+		}
+		if count > int64(len(r.b))+maxEmptyItems {
+			return fmt.Errorf("%w: avro block of %d items in %d bytes", ErrInvalid, count, len(r.b))
+		}
+		for i := int64(0); i < count; i++ {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func decodeAvro(r *avroReader, s *avroSchema) (interface{}, error) {
+	switch s.kind {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "int", "long":
+		return r.long()
+	case "float":
+		b, err := r.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case "double":
+		b, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case "bytes":
+		return r.bytes()
+	case "string":
+		b, err := r.bytes()
+		return string(b), err
+	case "fixed":
+		return r.next(s.size)
+	case "enum":
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.symbols) {
+			return nil, fmt.Errorf("%w: enum index %d out of range", ErrInvalid, i)
+		}
+		return s.symbols[i], nil
+	case "union":
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || int(i) >= len(s.branches) {
+			return nil, fmt.Errorf("%w: union index %d out of range", ErrInvalid, i)
+		}
+		return decodeAvro(r, s.branches[i])
+	case "array":
+		items := []interface{}{}
+		err := r.blocks(func() error {
+			item, err := decodeAvro(r, s.items)
+			items = append(items, item)
+			return err
+		})
+		return items, err
+	case "map":
+		values := map[string]interface{}{}
+		err := r.blocks(func() error {
+			key, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			values[string(key)], err = decodeAvro(r, s.values)
+			return err
+		})
+		return values, err
+	case "record":
+		record := make(map[string]interface{}, len(s.fields))
+		for _, field := range s.fields {
+			value, err := decodeAvro(r, field.schema)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.name, err)
+			}
+			record[field.name] = value
+		}
+		return record, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported avro type %q", ErrInvalid, s.kind)
+}
+
+// encodeAvro writes v, decoded from JSON with UseNumber, in the binary
+// encoding of s.
+func encodeAvro(w *bytes.Buffer, s *avroSchema, v interface{}) error {
+	switch s.kind {
+	case "null":
+		if v != nil {
+			return avroMismatch(s, v)
+		}
+		return nil
+	case "boolean":
+		b, ok := v.(bool)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		if b {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+		return nil
+	case "int", "long":
+		n, ok := v.(json.Number)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return avroMismatch(s, v)
+		}
+		writeLong(w, i)
+		return nil
+	case "float", "double":
+		n, ok := v.(json.Number)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return avroMismatch(s, v)
+		}
+		if s.kind == "float" {
+			return binary.Write(w, binary.LittleEndian, math.Float32bits(float32(f)))
+		}
+		return binary.Write(w, binary.LittleEndian, math.Float64bits(f))
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		writeLong(w, int64(len(str)))
+		w.WriteString(str)
+		return nil
+	case "bytes", "fixed":
+		str, ok := v.(string)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		b, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be base64", ErrInvalid, s.kind)
+		}
+		if s.kind == "fixed" {
+			if len(b) != s.size {
+				return fmt.Errorf("%w: fixed %s needs %d bytes", ErrInvalid, s.name, s.size)
+			}
+		} else {
+			writeLong(w, int64(len(b)))
+		}
+		w.Write(b)
+		return nil
+	case "enum":
+		str, ok := v.(string)
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		for i, symbol := range s.symbols {
+			if symbol == str {
+				writeLong(w, int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %q is not a symbol of %s", ErrInvalid, str, s.name)
+	case "union":
+		for i, branch := range s.branches {
+			if !avroAccepts(branch, v) {
+				continue
+			}
+			writeLong(w, int64(i))
+			return encodeAvro(w, branch, v)
+		}
+		return avroMismatch(s, v)
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		if len(items) > 0 {
+			writeLong(w, int64(len(items)))
+			for _, item := range items {
+				if err := encodeAvro(w, s.items, item); err != nil {
+					return err
+				}
+			}
+		}
+		writeLong(w, 0)
+		return nil
+	case "map":
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		if len(values) > 0 {
+			writeLong(w, int64(len(values)))
+			for key, value := range values {
+				writeLong(w, int64(len(key)))
+				w.WriteString(key)
+				if err := encodeAvro(w, s.values, value); err != nil {
+					return err
+				}
+			}
+		}
+		writeLong(w, 0)
+		return nil
+	case "record":
+		record, ok := v.(map[string]interface{})
+		if !ok {
+			return avroMismatch(s, v)
+		}
+		for _, field := range s.fields {
+			value, ok := record[field.name]
+			if !ok && field.defaultVal != nil {
+				decoder := json.NewDecoder(bytes.NewReader(field.defaultVal))
+				decoder.UseNumber()
+				if err := decoder.Decode(&value); err != nil {
+					return err
+				}
+			}
+			if err := encodeAvro(w, field.schema, value); err != nil {
+				return fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: unsupported avro type %q", ErrInvalid, s.kind)
+}
+
+// avroAccepts picks the union branch for a JSON value.
+func avroAccepts(s *avroSchema, v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return s.kind == "null"
+	case bool:
+		return s.kind == "boolean"
+	case json.Number:
+		if s.kind == "int" || s.kind == "long" {
+			_, err := v.Int64()
+			return err == nil
+		}
+		return s.kind == "float" || s.kind == "double"
+	case string:
+		return s.kind == "string" || s.kind == "bytes" || s.kind == "enum" || s.kind == "fixed"
+	case []interface{}:
+		return s.kind == "array"
+	case map[string]interface{}:
+		return s.kind == "record" || s.kind == "map"
+	}
+	return false
+}
+
+func avroMismatch(s *avroSchema, v interface{}) error {
+	return fmt.Errorf("%w: %T does not fit avro %s", ErrInvalid, v, s.kind)
+}
+
+func writeLong(w *bytes.Buffer, v int64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	w.Write(buf[:n])
+}
@@ -0,0 +1,52 @@
+package serde
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// parseProtoFile builds the file of a serialized protobuf schema. Imports
+// resolve against the well-known types linked into the binary only.
+func parseProtoFile(schema string) (protoreflect.FileDescriptor, error) {
+	b, err := base64.StdEncoding.DecodeString(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: protobuf schema is not a serialized descriptor", ErrInvalid)
+	}
+
+	fileProto := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(b, fileProto); err != nil {
+		return nil, fmt.Errorf("%w: protobuf schema: %v", ErrInvalid, err)
+	}
+
+	file, err := protodesc.NewFile(fileProto, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("%w: protobuf schema: %v", ErrInvalid, err)
+	}
+
+	return file, nil
+}
+
+func protoMessage(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := file.Messages()
+
+	var message protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index >= messages.Len() {
+			return nil, fmt.Errorf("%w: no protobuf message at index %v", ErrInvalid, indexes)
+		}
+		message = messages.Get(index)
+		messages = message.Messages()
+	}
+
+	if message == nil {
+		return nil, fmt.Errorf("%w: protobuf schema has no message", ErrInvalid)
+	}
+
+	return message, nil
+}
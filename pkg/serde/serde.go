@@ -0,0 +1,230 @@
+package serde
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	schemaRegistry "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/schema_registry"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	ContentTypeHeader = "content-type"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeAvro     = "application/vnd.confluent.avro"
+	ContentTypeProtobuf = "application/vnd.confluent.protobuf"
+)
+
+// ErrInvalid marks values that can never be decoded, as opposed to a
+// registry that is unreachable for now.
+var ErrInvalid = errors.New("invalid value")
+
+// Serde converts Avro and Protobuf values in the schema registry wire format
+// to and from the JSON the rest of the service works with.
+type Serde struct {
+	registry schemaRegistry.Client
+
+	mu     sync.RWMutex
+	avro   map[int]*avroSchema
+	protos map[int]protoreflect.FileDescriptor
+}
+
+// New returns a Serde over registry, which may be nil when only JSON is used.
+func New(registry schemaRegistry.Client) *Serde {
+	return &Serde{
+		registry: registry,
+		avro:     make(map[int]*avroSchema),
+		protos:   make(map[int]protoreflect.FileDescriptor),
+	}
+}
+
+// IsJSON reports whether contentType needs no conversion. Messages without
+// a content type are JSON.
+func IsJSON(contentType string) bool {
+	return contentType == "" || contentType == ContentTypeJSON || strings.HasPrefix(contentType, ContentTypeJSON+";")
+}
+
+// Permanent reports whether err will fail the same way on every attempt.
+func Permanent(err error) bool {
+	return errors.Is(err, ErrInvalid) || errors.Is(err, schemaRegistry.ErrNotFound)
+}
+
+// Decode returns value as JSON.
+func (s *Serde) Decode(ctx context.Context, contentType string, value []byte) ([]byte, error) {
+	if IsJSON(contentType) {
+		return value, nil
+	}
+
+	id, payload, err := splitWire(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch contentType {
+	case ContentTypeAvro:
+		schema, err := s.avroSchema(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		decoded, err := decodeAvro(&avroReader{b: payload}, schema)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(decoded)
+	case ContentTypeProtobuf:
+		indexes, payload, err := splitMessageIndexes(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := s.protoFile(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		descriptor, err := protoMessage(file, indexes)
+		if err != nil {
+			return nil, err
+		}
+
+		message := dynamicpb.NewMessage(descriptor)
+		if err := proto.Unmarshal(payload, message); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		return protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(message)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported content type %q", ErrInvalid, contentType)
+}
+
+// Encode converts the JSON value to contentType with the latest schema of
+// subject.
+func (s *Serde) Encode(ctx context.Context, contentType string, subject string, value []byte) ([]byte, error) {
+	if IsJSON(contentType) {
+		return value, nil
+	}
+
+	if s.registry == nil {
+		return nil, fmt.Errorf("%w: no schema registry configured", ErrInvalid)
+	}
+
+	schema, err := s.registry.LatestSchema(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	switch contentType {
+	case ContentTypeAvro:
+		parsed, err := s.avroSchema(ctx, schema.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := encodeAvro(buf, parsed, v); err != nil {
+			return nil, err
+		}
+		return appendWire(schema.ID, buf.Bytes()), nil
+	case ContentTypeProtobuf:
+		file, err := s.protoFile(ctx, schema.ID)
+		if err != nil {
+			return nil, err
+		}
+		descriptor, err := protoMessage(file, []int{0})
+		if err != nil {
+			return nil, err
+		}
+
+		message := dynamicpb.NewMessage(descriptor)
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(value, message); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		payload, err := proto.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		// The single zero index selects the first message of the file.
+		return appendWire(schema.ID, append([]byte{0}, payload...)), nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported content type %q", ErrInvalid, contentType)
+}
+
+func (s *Serde) schema(ctx context.Context, id int, schemaType string) (*schemaRegistry.Schema, error) {
+	if s.registry == nil {
+		return nil, fmt.Errorf("%w: no schema registry configured", ErrInvalid)
+	}
+
+	schema, err := s.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type() != schemaType {
+		return nil, fmt.Errorf("%w: schema %d is %s, not %s", ErrInvalid, id, schema.Type(), schemaType)
+	}
+
+	return schema, nil
+}
+
+func (s *Serde) avroSchema(ctx context.Context, id int) (*avroSchema, error) {
+	s.mu.RLock()
+	parsed, ok := s.avro[id]
+	s.mu.RUnlock()
+	if ok {
+		return parsed, nil
+	}
+
+	schema, err := s.schema(ctx, id, schemaRegistry.TypeAvro)
+	if err != nil {
+		return nil, err
+	}
+	if parsed, err = parseAvroSchema(schema.Schema); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.avro[id] = parsed
+	s.mu.Unlock()
+
+	return parsed, nil
+}
+
+func (s *Serde) protoFile(ctx context.Context, id int) (protoreflect.FileDescriptor, error) {
+	s.mu.RLock()
+	file, ok := s.protos[id]
+	s.mu.RUnlock()
+	if ok {
+		return file, nil
+	}
+
+	schema, err := s.schema(ctx, id, schemaRegistry.TypeProtobuf)
+	if err != nil {
+		return nil, err
+	}
+	if file, err = parseProtoFile(schema.Schema); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.protos[id] = file
+	s.mu.Unlock()
+
+	return file, nil
+}
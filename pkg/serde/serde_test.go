@@ -0,0 +1,292 @@
+package serde
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	schemaRegistry "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/schema_registry"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const envelopeAvsc = `{
+	"type": "record", "name": "Envelope", "namespace": "cns",
+	"fields": [
+		{"name": "category_name", "type": "string"},
+		{"name": "schema_version", "type": ["null", "int"], "default": null},
+		{"name": "priority", "type": {"type": "enum", "name": "Priority", "symbols": ["LOW", "HIGH"]}, "default": "LOW"},
+		{"name": "data", "type": ["bytes", {
+			"type": "record", "name": "Sms",
+			"fields": [
+				{"name": "recipient_phone_number", "type": "string"},
+				{"name": "tags", "type": {"type": "array", "items": "string"}},
+				{"name": "meta", "type": {"type": "map", "values": ["null", "long", "double"]}}
+			]
+		}]},
+		{"name": "previous", "type": ["null", "Sms"], "default": null}
+	]
+}`
+
+func TestAvroRoundTrip(t *testing.T) {
+	s := newTestSerde(t)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "bytes branch and defaults",
+			in:   `{"category_name":"sms","data":"eyJhIjoxfQ=="}`,
+			want: `{"category_name":"sms","data":"eyJhIjoxfQ==","previous":null,"priority":"LOW","schema_version":null}`,
+		},
+		{
+			name: "nested record branches",
+			in: `{"category_name":"sms","schema_version":2,"priority":"HIGH",
+				"data":{"recipient_phone_number":"0812","tags":["a","b"],"meta":{"n":3,"f":1.5,"z":null}},
+				"previous":{"recipient_phone_number":"0813","tags":[],"meta":{}}}`,
+			want: `{"category_name":"sms","schema_version":2,"priority":"HIGH",
+				"data":{"recipient_phone_number":"0812","tags":["a","b"],"meta":{"n":3,"f":1.5,"z":null}},
+				"previous":{"recipient_phone_number":"0813","tags":[],"meta":{}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := s.Encode(context.Background(), ContentTypeAvro, "avro-value", []byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id, _, err := splitWire(encoded); err != nil || id != 1 {
+				t.Fatalf("wire id = %d, %v", id, err)
+			}
+
+			decoded, err := s.Decode(context.Background(), ContentTypeAvro, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, decoded, tt.want)
+		})
+	}
+}
+
+func TestAvroEncodeErrors(t *testing.T) {
+	s := newTestSerde(t)
+
+	for _, in := range []string{
+		`{"data":"eyJhIjoxfQ=="}`,
+		`{"category_name":"sms","priority":"MEDIUM","data":"eyJhIjoxfQ=="}`,
+		`{"category_name":"sms","data":5}`,
+	} {
+		if _, err := s.Encode(context.Background(), ContentTypeAvro, "avro-value", []byte(in)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Encode(%s) err = %v, want ErrInvalid", in, err)
+		}
+	}
+}
+
+func TestAvroDecodeHugeBlock(t *testing.T) {
+	s := newTestSerde(t)
+
+	// category_name "", null schema_version, LOW, the Sms branch, empty
+	// phone number, then an array claiming 2^40 items.
+	payload := []byte{0, 0, 0, 2, 0}
+	payload = binary.AppendVarint(payload, 1<<40)
+
+	_, err := s.Decode(context.Background(), ContentTypeAvro, appendWire(1, payload))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	s := newTestSerde(t)
+
+	encoded, err := s.Encode(context.Background(), ContentTypeProtobuf, "proto-value",
+		[]byte(`{"category_name":"sms","data":"eyJhIjoxfQ==","schema_version":2,"unknown":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := s.Decode(context.Background(), ContentTypeProtobuf, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, decoded, `{"category_name":"sms","data":"eyJhIjoxfQ==","schema_version":2,"nested":null}`)
+}
+
+func TestProtobufMessageIndexes(t *testing.T) {
+	s := newTestSerde(t)
+
+	// Envelope.Nested{note = "hi"} is the message at path [0, 0].
+	payload := protowire(t, 1, "hi")
+
+	tests := []struct {
+		name    string
+		indexes []int64
+		want    string
+		wantErr bool
+	}{
+		{name: "nested message", indexes: []int64{2, 0, 0}, want: `{"note":"hi"}`},
+		{name: "second top level message", indexes: []int64{1, 1}, want: `{"reason":"hi"}`},
+		{name: "index out of range", indexes: []int64{1, 5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := appendWire(2, nil)
+			for _, index := range tt.indexes {
+				value = binary.AppendVarint(value, index)
+			}
+			value = append(value, payload...)
+
+			decoded, err := s.Decode(context.Background(), ContentTypeProtobuf, value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("err = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSONEqual(t, decoded, tt.want)
+		})
+	}
+}
+
+func TestDecodeUnknownSchema(t *testing.T) {
+	s := newTestSerde(t)
+
+	_, err := s.Decode(context.Background(), ContentTypeAvro, appendWire(99, nil))
+	if !errors.Is(err, schemaRegistry.ErrNotFound) || !Permanent(err) {
+		t.Fatalf("err = %v, want a permanent ErrNotFound", err)
+	}
+
+	// An Avro id used with the Protobuf content type is a mismatch.
+	_, err = s.Decode(context.Background(), ContentTypeProtobuf, appendWire(1, []byte{0}))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestJSONPassesThrough(t *testing.T) {
+	s := New(nil)
+
+	for _, contentType := range []string{"", ContentTypeJSON, ContentTypeJSON + "; charset=utf-8"} {
+		value, err := s.Decode(context.Background(), contentType, []byte(`{"a":1}`))
+		if err != nil || string(value) != `{"a":1}` {
+			t.Errorf("Decode(%q) = %s, %v", contentType, value, err)
+		}
+	}
+
+	if _, err := s.Encode(context.Background(), ContentTypeAvro, "avro-value", []byte(`{}`)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Encode without registry err = %v, want ErrInvalid", err)
+	}
+}
+
+// newTestSerde writes a registry file with the Avro envelope as id 1 and a
+// Protobuf file with nested messages as id 2.
+func newTestSerde(t *testing.T) *Serde {
+	t.Helper()
+
+	schemas := []*schemaRegistry.Schema{
+		{ID: 1, Subject: "avro-value", Version: 1, Schema: envelopeAvsc},
+		{ID: 2, Subject: "proto-value", Version: 3, SchemaType: schemaRegistry.TypeProtobuf, Schema: testProtoSchema(t)},
+	}
+	b, err := json.Marshal(schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "registry.json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := schemaRegistry.NewClient(&schemaRegistry.Config{File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(registry)
+}
+
+func testProtoSchema(t *testing.T) string {
+	t.Helper()
+
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     kind.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			JsonName: proto.String(name),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("envelope.proto"),
+		Package: proto.String("cns"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Envelope"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("category_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("data", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+					field("schema_version", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					field("nested", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".cns.Envelope.Nested"),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name:  proto.String("Nested"),
+					Field: []*descriptorpb.FieldDescriptorProto{field("note", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")},
+				}},
+			},
+			{
+				Name:  proto.String("Rejected"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("reason", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")},
+			},
+		},
+	}
+
+	b, err := proto.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// protowire encodes a single string field.
+func protowire(t *testing.T, number int, value string) []byte {
+	t.Helper()
+
+	b := []byte{byte(number<<3 | 2), byte(len(value))}
+	return append(b, value...)
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("got invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("want invalid JSON %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package serde
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Confluent wire format: a zero magic byte and the big endian schema id
+// ahead of the encoded value.
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+func splitWire(value []byte) (int, []byte, error) {
+	if len(value) < headerSize || value[0] != magicByte {
+		return 0, nil, fmt.Errorf("%w: value is not in the schema registry wire format", ErrInvalid)
+	}
+
+	return int(binary.BigEndian.Uint32(value[1:headerSize])), value[headerSize:], nil
+}
+
+func appendWire(id int, payload []byte) []byte {
+	value := make([]byte, headerSize, headerSize+len(payload))
+	value[0] = magicByte
+	binary.BigEndian.PutUint32(value[1:], uint32(id))
+
+	return append(value, payload...)
+}
+
+// splitMessageIndexes reads the path of the protobuf message within its file
+// that follows the schema id. A single zero stands for the first message.
+func splitMessageIndexes(b []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(b)
+	if n <= 0 || count < 0 {
+		return nil, nil, fmt.Errorf("%w: bad protobuf message indexes", ErrInvalid)
+	}
+	b = b[n:]
+
+	if count == 0 {
+		return []int{0}, b, nil
+	}
+	// Every index takes at least one byte, a larger count is garbage and
+	// must not size the allocation.
+	if count > int64(len(b)) {
+		return nil, nil, fmt.Errorf("%w: %d protobuf message indexes in %d bytes", ErrInvalid, count, len(b))
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(b)
+		if n <= 0 || index < 0 {
+			return nil, nil, fmt.Errorf("%w: bad protobuf message indexes", ErrInvalid)
+		}
+		indexes[i] = int(index)
+		b = b[n:]
+	}
+
+	return indexes, b, nil
+}
@@ -0,0 +1,72 @@
+package serde
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestSplitMessageIndexes(t *testing.T) {
+	varints := func(values ...int64) []byte {
+		var b []byte
+		for _, v := range values {
+			b = binary.AppendVarint(b, v)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		in      []byte
+		want    []int
+		rest    int
+		wantErr bool
+	}{
+		{name: "first message", in: append(varints(0), 1, 2), want: []int{0}, rest: 2},
+		{name: "nested path", in: append(varints(2, 1, 0), 7), want: []int{1, 0}, rest: 1},
+		{name: "empty", in: nil, wantErr: true},
+		{name: "negative count", in: varints(-1), wantErr: true},
+		{name: "huge count", in: append(varints(1<<33), make([]byte, 5)...), wantErr: true},
+		{name: "truncated indexes", in: varints(3, 1), wantErr: true},
+		{name: "negative index", in: varints(1, -2), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := splitMessageIndexes(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("err = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("indexes = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("indexes = %v, want %v", got, tt.want)
+				}
+			}
+			if len(rest) != tt.rest {
+				t.Fatalf("rest has %d bytes, want %d", len(rest), tt.rest)
+			}
+		})
+	}
+}
+
+func TestSplitWire(t *testing.T) {
+	id, payload, err := splitWire(appendWire(42, []byte{9}))
+	if err != nil || id != 42 || len(payload) != 1 || payload[0] != 9 {
+		t.Fatalf("splitWire = %d, %v, %v", id, payload, err)
+	}
+
+	for _, in := range [][]byte{nil, {0, 0, 0}, {1, 0, 0, 0, 1}} {
+		if _, _, err := splitWire(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("splitWire(%v) err = %v, want ErrInvalid", in, err)
+		}
+	}
+}
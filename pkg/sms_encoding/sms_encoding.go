@@ -0,0 +1,184 @@
+package sms_encoding
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	EncodingGSM7 = "gsm7"
+	EncodingUCS2 = "ucs2"
+
+	// Single messages carry 160 septets or 70 UTF-16 units, concatenated
+	// parts lose room to the user data header.
+	gsm7Single = 160
+	gsm7Part   = 153
+	ucs2Single = 70
+	ucs2Part   = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞ\x1bÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension holds the characters sent as an escape plus one septet.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// Info describes how a text is sent. Units are septets for GSM-7 and UTF-16
+// code units for UCS-2.
+type Info struct {
+	Encoding string
+	Units    int
+	Segments int
+}
+
+// Analyze picks GSM-7 when every character fits it, UCS-2 otherwise, and
+// counts the segments the text is split into.
+func Analyze(text string) Info {
+	if IsGSM7(text) {
+		return Info{Encoding: EncodingGSM7, Units: septets(text), Segments: len(split(text, EncodingGSM7))}
+	}
+	return Info{Encoding: EncodingUCS2, Units: len(utf16.Encode([]rune(text))), Segments: len(split(text, EncodingUCS2))}
+}
+
+func IsGSM7(text string) bool {
+	for _, r := range text {
+		if runeUnits(r, EncodingGSM7) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Truncate cuts text to what fits in maxSegments, never splitting an escape
+// sequence or a surrogate pair.
+func Truncate(text string, maxSegments int) string {
+	if maxSegments <= 0 {
+		return ""
+	}
+
+	info := Analyze(text)
+	if info.Segments <= maxSegments {
+		return text
+	}
+
+	if maxSegments == 1 {
+		limit := gsm7Single
+		if info.Encoding == EncodingUCS2 {
+			limit = ucs2Single
+		}
+
+		used := 0
+		for i, r := range text {
+			n := runeUnits(r, info.Encoding)
+			if used+n > limit {
+				return text[:i]
+			}
+			used += n
+		}
+		return text
+	}
+
+	// Keep whole parts, a character moved to the next part leaves a gap the
+	// unit budget alone would not see.
+	n := 0
+	for _, part := range split(text, info.Encoding)[:maxSegments] {
+		n += len(part)
+	}
+
+	return text[:n]
+}
+
+// Transliterate replaces characters outside GSM-7 with the closest GSM-7
+// spelling, and with ? when there is none.
+func Transliterate(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		if runeUnits(r, EncodingGSM7) > 0 {
+			b.WriteRune(r)
+			continue
+		}
+		if replacement, ok := transliterations[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		if r == '\uFE0F' || r == '\u200D' {
+			// Emoji presentation selectors and joiners carry no text.
+			continue
+		}
+		b.WriteByte('?')
+	}
+
+	return b.String()
+}
+
+var transliterations = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '`': "'", '´': "'",
+	'“': "\"", '”': "\"", '„': "\"", '«': "\"", '»': "\"",
+	'–': "-", '—': "-", '−': "-", '…': "...", '•': "*", '·': ".",
+	'\u00A0': " ", '\u2009': " ", '\u200B': "", '\t': " ",
+	'á': "a", 'â': "a", 'ã': "a", 'ā': "a", 'Á': "A", 'Â': "A", 'Ã': "A", 'À': "A",
+	'ç': "Ç", 'ê': "e", 'ë': "e", 'ē': "e", 'È': "E", 'Ê': "E", 'Ë': "E",
+	'í': "i", 'î': "i", 'ï': "i", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ì': "I",
+	'ó': "o", 'ô': "o", 'õ': "o", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ò': "O",
+	'ú': "u", 'û': "u", 'Ú': "U", 'Û': "U", 'Ù': "U",
+	'ý': "y", 'ÿ': "y", 'Ý': "Y",
+}
+
+// runeUnits returns how many units r takes in encoding, 0 when GSM-7 cannot
+// carry it.
+func runeUnits(r rune, encoding string) int {
+	if encoding == EncodingUCS2 {
+		if r >= 0x10000 {
+			return 2
+		}
+		return 1
+	}
+
+	if strings.ContainsRune(gsm7Extension, r) {
+		return 2
+	}
+	if r != '\x1b' && strings.ContainsRune(gsm7Basic, r) {
+		return 1
+	}
+	return 0
+}
+
+func septets(text string) int {
+	n := 0
+	for _, r := range text {
+		n += runeUnits(r, EncodingGSM7)
+	}
+	return n
+}
+
+// split breaks text into the parts a gateway would send.
+func split(text string, encoding string) []string {
+	single, part := gsm7Single, gsm7Part
+	if encoding == EncodingUCS2 {
+		single, part = ucs2Single, ucs2Part
+	}
+
+	total := 0
+	for _, r := range text {
+		total += runeUnits(r, encoding)
+	}
+	if total <= single {
+		return []string{text}
+	}
+
+	var parts []string
+	start, used := 0, 0
+	for i, r := range text {
+		n := runeUnits(r, encoding)
+		if used+n > part {
+			parts = append(parts, text[start:i])
+			start, used = i, 0
+		}
+		used += n
+	}
+
+	return append(parts, text[start:])
+}
@@ -0,0 +1,102 @@
+package sms_encoding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Info
+	}{
+		{name: "empty", text: "", want: Info{Encoding: EncodingGSM7, Units: 0, Segments: 1}},
+		{name: "gsm7 single full", text: strings.Repeat("a", 160), want: Info{Encoding: EncodingGSM7, Units: 160, Segments: 1}},
+		{name: "gsm7 single over", text: strings.Repeat("a", 161), want: Info{Encoding: EncodingGSM7, Units: 161, Segments: 2}},
+		{name: "gsm7 two parts full", text: strings.Repeat("a", 306), want: Info{Encoding: EncodingGSM7, Units: 306, Segments: 2}},
+		{name: "gsm7 two parts over", text: strings.Repeat("a", 307), want: Info{Encoding: EncodingGSM7, Units: 307, Segments: 3}},
+		{name: "escape counts double", text: strings.Repeat("a", 158) + "€", want: Info{Encoding: EncodingGSM7, Units: 160, Segments: 1}},
+		{name: "escape over single", text: strings.Repeat("a", 159) + "€", want: Info{Encoding: EncodingGSM7, Units: 161, Segments: 2}},
+		{name: "escape kept whole across parts", text: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152), want: Info{Encoding: EncodingGSM7, Units: 306, Segments: 3}},
+		{name: "basic accents", text: "Rp1.000 ÄÖÑÜ àèéùìò", want: Info{Encoding: EncodingGSM7, Units: 19, Segments: 1}},
+		{name: "bare escape is not gsm7", text: "a\x1b", want: Info{Encoding: EncodingUCS2, Units: 2, Segments: 1}},
+		{name: "ucs2 single full", text: strings.Repeat("ж", 70), want: Info{Encoding: EncodingUCS2, Units: 70, Segments: 1}},
+		{name: "ucs2 single over", text: strings.Repeat("ж", 71), want: Info{Encoding: EncodingUCS2, Units: 71, Segments: 2}},
+		{name: "ucs2 two parts full", text: strings.Repeat("ж", 134), want: Info{Encoding: EncodingUCS2, Units: 134, Segments: 2}},
+		{name: "ucs2 two parts over", text: strings.Repeat("ж", 135), want: Info{Encoding: EncodingUCS2, Units: 135, Segments: 3}},
+		{name: "surrogate pairs count double", text: strings.Repeat("😀", 35), want: Info{Encoding: EncodingUCS2, Units: 70, Segments: 1}},
+		{name: "surrogate pair over single", text: strings.Repeat("ж", 69) + "😀", want: Info{Encoding: EncodingUCS2, Units: 71, Segments: 2}},
+		{name: "surrogate pair kept whole across parts", text: strings.Repeat("ж", 66) + "😀" + strings.Repeat("ж", 66), want: Info{Encoding: EncodingUCS2, Units: 134, Segments: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.text); got != tt.want {
+				t.Errorf("Analyze = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		maxSegments int
+		want        string
+	}{
+		{name: "no segment", text: "halo", maxSegments: 0, want: ""},
+		{name: "fits", text: strings.Repeat("a", 160), maxSegments: 1, want: strings.Repeat("a", 160)},
+		{name: "gsm7 single", text: strings.Repeat("a", 200), maxSegments: 1, want: strings.Repeat("a", 160)},
+		{name: "gsm7 two parts", text: strings.Repeat("a", 400), maxSegments: 2, want: strings.Repeat("a", 306)},
+		{name: "escape not split", text: strings.Repeat("a", 159) + "€b", maxSegments: 1, want: strings.Repeat("a", 159)},
+		{name: "escape at a part boundary", text: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 200), maxSegments: 2, want: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 151)},
+		{name: "ucs2 single", text: strings.Repeat("ж", 100), maxSegments: 1, want: strings.Repeat("ж", 70)},
+		{name: "ucs2 two parts", text: strings.Repeat("ж", 200), maxSegments: 2, want: strings.Repeat("ж", 134)},
+		{name: "surrogate pair not split", text: strings.Repeat("ж", 69) + "😀", maxSegments: 1, want: strings.Repeat("ж", 69)},
+		{name: "surrogate pair at a part boundary", text: strings.Repeat("ж", 66) + "😀" + strings.Repeat("ж", 100), maxSegments: 2, want: strings.Repeat("ж", 66) + "😀" + strings.Repeat("ж", 65)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.text, tt.maxSegments)
+			if got != tt.want {
+				t.Errorf("Truncate = %q (%d bytes), want %q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+			if tt.maxSegments > 0 {
+				if info := Analyze(got); info.Segments > tt.maxSegments {
+					t.Errorf("truncated text takes %d segments, want at most %d", info.Segments, tt.maxSegments)
+				}
+			}
+		})
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Saldo JHT Rp1.000", want: "Saldo JHT Rp1.000"},
+		{text: "“Halo” – dunia…", want: "\"Halo\" - dunia..."},
+		{text: "it’s ok", want: "it's ok"},
+		{text: "ça va, Ação", want: "Ça va, AÇao"},
+		{text: "€ and {braces}", want: "€ and {braces}"},
+		{text: "oke 👍", want: "oke ?"},
+		{text: "❤️", want: "?"},
+		{text: "你好", want: "??"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Transliterate(tt.text)
+			if got != tt.want {
+				t.Errorf("Transliterate = %q, want %q", got, tt.want)
+			}
+			if !IsGSM7(got) {
+				t.Errorf("%q is not gsm7", got)
+			}
+		})
+	}
+}
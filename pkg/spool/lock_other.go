@@ -0,0 +1,19 @@
+//go:build !unix
+
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockDir only creates the lock file where flock is not available, two
+// processes sharing a directory are not detected.
+func lockDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open spool lock: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,595 @@
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentExt = ".seg"
+	cursorFile = "cursor"
+	lockFile   = "lock"
+
+	// length, checksum and unix milli time precede every payload.
+	frameHeaderSize = 16
+
+	// Records are tracking events, anything larger is a damaged header.
+	maxRecordSize = 16 << 20
+
+	// The cursor is saved at least this often while flushing, a crash
+	// replays at most this many records.
+	cursorSaveEvery = 100
+)
+
+var (
+	ErrFull   = errors.New("spool is full")
+	ErrClosed = errors.New("spool is closed")
+	ErrTooBig = errors.New("record is too big")
+	ErrLocked = errors.New("spool is used by another process")
+)
+
+// Options bounds the spool on disk. Appends fail with ErrFull above MaxBytes,
+// segments roll over at SegmentBytes.
+type Options struct {
+	MaxBytes     int64
+	SegmentBytes int64
+}
+
+type Stats struct {
+	Bytes   int64     `json:"bytes"`
+	Records int64     `json:"records"`
+	Oldest  time.Time `json:"oldest,omitempty"`
+}
+
+// Spool is a FIFO of records kept in append-only segment files. Appends are
+// synced before they return, and the read position is kept in a cursor file
+// replaced atomically, so a restart resumes after the last flushed record.
+// Delivery is at least once, records flushed after the last cursor save are
+// flushed again after a crash.
+type Spool struct {
+	dir  string
+	opts Options
+	lock *os.File
+
+	mu       sync.Mutex
+	flushMu  sync.Mutex
+	segments []int64
+	sizes    map[int64]int64
+	active   *os.File
+	reader   *os.File
+	readSeq  int64
+	headSeq  int64
+	headOff  int64
+	records  int64
+	oldest   time.Time
+	unsaved  int
+	closed   bool
+}
+
+// Open opens the spool in dir, creating it when missing. A record torn by a
+// crash at the end of the last segment is cut off. Open fails with ErrLocked
+// while another process has the spool open.
+func Open(dir string, opts Options) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+
+	lock, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spool{
+		dir:     dir,
+		opts:    opts,
+		lock:    lock,
+		sizes:   make(map[int64]int64),
+		readSeq: -1,
+	}
+
+	if err := s.load(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("read spool dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseInt(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, seq)
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i] < s.segments[j] })
+
+	s.headSeq, s.headOff = s.readCursor()
+
+	// Segments before the cursor were flushed but not yet removed.
+	for len(s.segments) > 0 && s.segments[0] < s.headSeq {
+		_ = os.Remove(s.segmentPath(s.segments[0]))
+		s.segments = s.segments[1:]
+	}
+	if len(s.segments) == 0 || s.segments[0] != s.headSeq {
+		s.headOff = 0
+		if len(s.segments) > 0 {
+			s.headSeq = s.segments[0]
+		}
+	}
+
+	for i, seq := range s.segments {
+		from := int64(0)
+		if seq == s.headSeq {
+			from = s.headOff
+		}
+
+		size, records, oldest, err := s.scan(seq, from, i == len(s.segments)-1)
+		if err != nil {
+			return err
+		}
+
+		s.sizes[seq] = size
+		s.records += records
+		if s.oldest.IsZero() {
+			s.oldest = oldest
+		}
+	}
+
+	if s.headOff > s.sizes[s.headSeq] {
+		s.headOff = s.sizes[s.headSeq]
+	}
+
+	return nil
+}
+
+// scan counts the valid records of segment seq from offset from and returns
+// the segment size. The last segment is truncated after its last valid
+// record, the earlier ones were sealed and are left as written.
+func (s *Spool) scan(seq int64, from int64, last bool) (int64, int64, time.Time, error) {
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_RDWR, 0o644)
+	if err != nil {
+		return 0, 0, time.Time{}, fmt.Errorf("open segment: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	var (
+		records int64
+		oldest  time.Time
+		offset  = from
+	)
+
+	for {
+		_, at, size, err := readFrame(f, offset)
+		if err != nil {
+			break
+		}
+		if oldest.IsZero() {
+			oldest = at
+		}
+		records++
+		offset += size
+	}
+
+	if last && offset < info.Size() {
+		if err := f.Truncate(offset); err != nil {
+			return 0, 0, time.Time{}, fmt.Errorf("truncate torn record: %w", err)
+		}
+		return offset, records, oldest, f.Sync()
+	}
+
+	return info.Size(), records, oldest, nil
+}
+
+// Append adds data at the end of the spool.
+func (s *Spool) Append(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if len(data) > maxRecordSize {
+		return ErrTooBig
+	}
+
+	frame := encodeFrame(data, time.Now())
+	if s.opts.MaxBytes > 0 && s.bytes()+int64(len(frame)) > s.opts.MaxBytes {
+		return ErrFull
+	}
+
+	if err := s.ensureActive(int64(len(frame))); err != nil {
+		return err
+	}
+
+	seq := s.segments[len(s.segments)-1]
+	if _, err := s.active.Write(frame); err != nil {
+		// Cut a partial write so the next frame starts on a boundary.
+		_ = s.active.Truncate(s.sizes[seq])
+		_, _ = s.active.Seek(s.sizes[seq], io.SeekStart)
+		return fmt.Errorf("write spool: %w", err)
+	}
+	if err := s.active.Sync(); err != nil {
+		return fmt.Errorf("sync spool: %w", err)
+	}
+
+	s.sizes[seq] += int64(len(frame))
+	s.records++
+	if s.oldest.IsZero() {
+		s.oldest = time.Now()
+	}
+
+	return nil
+}
+
+func (s *Spool) ensureActive(frameSize int64) error {
+	if s.active != nil {
+		seq := s.segments[len(s.segments)-1]
+		if s.opts.SegmentBytes <= 0 || s.sizes[seq] == 0 || s.sizes[seq]+frameSize <= s.opts.SegmentBytes {
+			return nil
+		}
+		if err := s.active.Close(); err != nil {
+			return fmt.Errorf("close segment: %w", err)
+		}
+		s.active = nil
+	}
+
+	// Reopen the last segment after a restart unless it is already full.
+	if len(s.segments) > 0 && s.active == nil {
+		seq := s.segments[len(s.segments)-1]
+		if s.opts.SegmentBytes <= 0 || s.sizes[seq]+frameSize <= s.opts.SegmentBytes {
+			f, err := os.OpenFile(s.segmentPath(seq), os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return fmt.Errorf("open segment: %w", err)
+			}
+			s.active = f
+			return nil
+		}
+	}
+
+	// An empty spool starts at the cursor segment so the saved cursor never
+	// points past new data.
+	seq := s.headSeq
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1] + 1
+	}
+
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("create segment: %w", err)
+	}
+	if err := syncDir(s.dir); err != nil {
+		f.Close()
+		return err
+	}
+
+	if len(s.segments) == 0 {
+		s.headSeq, s.headOff = seq, 0
+		if err := s.saveCursor(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	s.segments = append(s.segments, seq)
+	s.sizes[seq] = 0
+	s.active = f
+
+	return nil
+}
+
+// Flush hands records to fn oldest first and removes the ones it accepted.
+// It stops at the first error so order is kept, returning the number of
+// records flushed.
+func (s *Spool) Flush(fn func(data []byte) error) (int, error) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	flushed := 0
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.unsaved == 0 || s.closed {
+			return
+		}
+		if err := s.saveCursor(); err != nil {
+			fmt.Println("Failed to save spool cursor:", err)
+		}
+	}()
+
+	for {
+		data, next, ok, err := s.peek()
+		if err != nil || !ok {
+			return flushed, err
+		}
+
+		if err := fn(data); err != nil {
+			return flushed, err
+		}
+
+		if err := s.commit(next); err != nil {
+			return flushed, err
+		}
+		flushed++
+	}
+}
+
+// peek reads the record at the cursor, moving past segments that are done.
+func (s *Spool) peek() ([]byte, int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.closed {
+			return nil, 0, false, ErrClosed
+		}
+		if len(s.segments) == 0 {
+			return nil, 0, false, nil
+		}
+
+		if s.headOff < s.sizes[s.headSeq] {
+			reader, err := s.readerFor(s.headSeq)
+			if err != nil {
+				return nil, 0, false, err
+			}
+
+			data, _, size, err := readFrame(reader, s.headOff)
+			if err == nil {
+				return data, s.headOff + size, true, nil
+			}
+			if s.headSeq == s.segments[len(s.segments)-1] {
+				return nil, 0, false, fmt.Errorf("read spool: %w", err)
+			}
+			// A damaged sealed segment cannot be resynced, skip its rest.
+			fmt.Println("Skipping damaged spool segment:", s.segmentPath(s.headSeq), err)
+		}
+
+		if s.headSeq == s.segments[len(s.segments)-1] {
+			return nil, 0, false, nil
+		}
+
+		if err := s.dropHead(); err != nil {
+			return nil, 0, false, err
+		}
+	}
+}
+
+func (s *Spool) commit(next int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headOff = next
+	s.records--
+	s.unsaved++
+
+	if s.records == 0 {
+		s.oldest = time.Time{}
+	} else if reader, err := s.readerFor(s.headSeq); err == nil {
+		if _, at, _, err := readFrame(reader, s.headOff); err == nil {
+			s.oldest = at
+		}
+	}
+
+	if s.unsaved >= cursorSaveEvery {
+		return s.saveCursor()
+	}
+	return nil
+}
+
+// dropHead removes the fully flushed head segment, the cursor moves first so
+// a crash in between never points into a deleted file.
+func (s *Spool) dropHead() error {
+	seq := s.segments[0]
+
+	s.segments = s.segments[1:]
+	s.headSeq, s.headOff = s.segments[0], 0
+	if err := s.saveCursor(); err != nil {
+		return err
+	}
+
+	if s.reader != nil && s.readSeq == seq {
+		s.reader.Close()
+		s.reader, s.readSeq = nil, -1
+	}
+	delete(s.sizes, seq)
+
+	if err := os.Remove(s.segmentPath(seq)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove segment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Spool) readerFor(seq int64) (*os.File, error) {
+	if s.reader != nil && s.readSeq == seq {
+		return s.reader, nil
+	}
+	if s.reader != nil {
+		s.reader.Close()
+	}
+
+	f, err := os.Open(s.segmentPath(seq))
+	if err != nil {
+		return nil, fmt.Errorf("open segment: %w", err)
+	}
+	s.reader, s.readSeq = f, seq
+
+	return f, nil
+}
+
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Stats{
+		Bytes:   s.bytes(),
+		Records: s.records,
+		Oldest:  s.oldest,
+	}
+}
+
+// Len is the number of records waiting to be flushed.
+func (s *Spool) Len() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.records
+}
+
+func (s *Spool) bytes() int64 {
+	var total int64
+	for _, size := range s.sizes {
+		total += size
+	}
+	return total - s.headOff
+}
+
+func (s *Spool) Close() error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	err := s.saveCursor()
+	if s.reader != nil {
+		s.reader.Close()
+	}
+	if s.active != nil {
+		if closeErr := s.active.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := s.lock.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func (s *Spool) segmentPath(seq int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+func (s *Spool) readCursor() (int64, int64) {
+	b, err := os.ReadFile(filepath.Join(s.dir, cursorFile))
+	if err != nil {
+		return 0, 0
+	}
+
+	var seq, offset int64
+	if _, err := fmt.Sscanf(string(b), "%d %d", &seq, &offset); err != nil {
+		return 0, 0
+	}
+
+	return seq, offset
+}
+
+// saveCursor replaces the cursor file through a synced temp file and rename.
+func (s *Spool) saveCursor() error {
+	s.unsaved = 0
+
+	tmp := filepath.Join(s.dir, cursorFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("write cursor: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(f, "%d %d\n", s.headSeq, s.headOff); err != nil {
+		f.Close()
+		return fmt.Errorf("write cursor: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync cursor: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write cursor: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, cursorFile)); err != nil {
+		return fmt.Errorf("replace cursor: %w", err)
+	}
+
+	return syncDir(s.dir)
+}
+
+func encodeFrame(data []byte, at time.Time) []byte {
+	frame := make([]byte, frameHeaderSize+len(data))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint64(frame[8:16], uint64(at.UnixMilli()))
+	copy(frame[frameHeaderSize:], data)
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(frame[8:]))
+
+	return frame
+}
+
+// readFrame reads the frame at offset and returns its payload, time and size
+// on disk.
+func readFrame(r io.ReaderAt, offset int64) ([]byte, time.Time, int64, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return nil, time.Time{}, 0, ErrTooBig
+	}
+	frame := make([]byte, 8+int(length))
+	copy(frame, header[8:16])
+	if _, err := r.ReadAt(frame[8:], offset+frameHeaderSize); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+
+	if crc32.ChecksumIEEE(frame) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, time.Time{}, 0, errors.New("checksum mismatch")
+	}
+
+	at := time.UnixMilli(int64(binary.BigEndian.Uint64(frame[0:8])))
+
+	return frame[8:], at, frameHeaderSize + int64(length), nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open spool dir: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync spool dir: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenLocksDir(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir, Options{}); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Open err = %v, want ErrLocked", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open after Close: %v", err)
+	}
+	reopened.Close()
+}
+
+func TestTornTailIsCut(t *testing.T) {
+	dir := t.TempDir()
+
+	s := openSpool(t, dir, Options{})
+	appendRecords(t, s, 0, 3)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crash in the middle of a write leaves half a frame behind.
+	segments := segmentFiles(t, dir)
+	last := segments[len(segments)-1]
+	info, err := os.Stat(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	torn := encodeFrame([]byte(recordName(3)), time.Now())
+	appendFile(t, last, torn[:len(torn)-3])
+
+	s = openSpool(t, dir, Options{})
+	if s.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", s.Len())
+	}
+	if after, _ := os.Stat(last); after.Size() != info.Size() {
+		t.Fatalf("segment is %d bytes, want the torn frame cut back to %d", after.Size(), info.Size())
+	}
+
+	// New records follow the valid ones.
+	appendRecords(t, s, 3, 5)
+	assertFlushed(t, s, 0, 5)
+	s.Close()
+}
+
+func TestRestartMidFlush(t *testing.T) {
+	dir := t.TempDir()
+	total := cursorSaveEvery*2 + 50
+
+	s := openSpool(t, dir, Options{SegmentBytes: 4096})
+	appendRecords(t, s, 0, total)
+
+	// Snapshot the directory in the middle of a flush, as a crash would
+	// leave it, after the cursor was saved once.
+	crashed := t.TempDir()
+	stop := errors.New("stop")
+	flushed, err := s.Flush(func(data []byte) error {
+		if string(data) == recordName(cursorSaveEvery+20) {
+			copyDir(t, dir, crashed)
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || flushed != cursorSaveEvery+20 {
+		t.Fatalf("Flush = %d, %v", flushed, err)
+	}
+
+	// The spool left running resumes where it stopped.
+	assertFlushed(t, s, cursorSaveEvery+20, total)
+	s.Close()
+
+	// The crashed copy replays from the last saved cursor, at least once.
+	recovered := openSpool(t, crashed, Options{SegmentBytes: 4096})
+	if recovered.Len() != int64(total-cursorSaveEvery) {
+		t.Fatalf("recovered Len = %d, want %d", recovered.Len(), total-cursorSaveEvery)
+	}
+	assertFlushed(t, recovered, cursorSaveEvery, total)
+	recovered.Close()
+}
+
+func TestSegmentRollover(t *testing.T) {
+	dir := t.TempDir()
+	frameSize := int64(frameHeaderSize + len(recordName(0)))
+	opts := Options{SegmentBytes: frameSize * 4}
+
+	s := openSpool(t, dir, opts)
+	appendRecords(t, s, 0, 10)
+	if n := len(segmentFiles(t, dir)); n != 3 {
+		t.Fatalf("%d segments, want 3 of at most 4 records", n)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The last segment has room for two more after a restart.
+	s = openSpool(t, dir, opts)
+	appendRecords(t, s, 10, 13)
+	if n := len(segmentFiles(t, dir)); n != 4 {
+		t.Fatalf("%d segments after restart, want 4", n)
+	}
+	if stats := s.Stats(); stats.Records != 13 || stats.Bytes != frameSize*13 {
+		t.Fatalf("stats = %+v, want 13 records in %d bytes", stats, frameSize*13)
+	}
+
+	assertFlushed(t, s, 0, 13)
+	if n := len(segmentFiles(t, dir)); n != 1 {
+		t.Fatalf("%d segments after flush, want only the active one", n)
+	}
+	s.Close()
+
+	// Nothing is replayed once everything was flushed.
+	s = openSpool(t, dir, opts)
+	if s.Len() != 0 {
+		t.Fatalf("Len after reopen = %d, want 0", s.Len())
+	}
+	appendRecords(t, s, 13, 14)
+	assertFlushed(t, s, 13, 14)
+	s.Close()
+}
+
+func TestAppendLimits(t *testing.T) {
+	s := openSpool(t, t.TempDir(), Options{MaxBytes: 3 * int64(frameHeaderSize+len(recordName(0)))})
+	defer s.Close()
+
+	appendRecords(t, s, 0, 3)
+	if err := s.Append([]byte(recordName(3))); !errors.Is(err, ErrFull) {
+		t.Fatalf("Append err = %v, want ErrFull", err)
+	}
+	if err := s.Append(make([]byte, maxRecordSize+1)); !errors.Is(err, ErrTooBig) {
+		t.Fatalf("Append err = %v, want ErrTooBig", err)
+	}
+}
+
+func openSpool(t *testing.T, dir string, opts Options) *Spool {
+	t.Helper()
+
+	s, err := Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func recordName(i int) string {
+	return fmt.Sprintf("record-%04d", i)
+}
+
+func appendRecords(t *testing.T, s *Spool, from int, to int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		if err := s.Append([]byte(recordName(i))); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+}
+
+// assertFlushed flushes s and expects the records numbered from to to-1, in
+// order.
+func assertFlushed(t *testing.T, s *Spool, from int, to int) {
+	t.Helper()
+
+	var got []string
+	if _, err := s.Flush(func(data []byte) error {
+		got = append(got, string(data))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != to-from {
+		t.Fatalf("flushed %d records, want %d", len(got), to-from)
+	}
+	for i, data := range got {
+		if data != recordName(from+i) {
+			t.Fatalf("record %d is %q, want %q", i, data, recordName(from+i))
+		}
+	}
+	if s.Len() != 0 {
+		t.Fatalf("Len after flush = %d", s.Len())
+	}
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func appendFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// copyDir copies the spool files, leaving out the lock file.
+func copyDir(t *testing.T, from string, to string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == lockFile || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+
+		src, err := os.Open(filepath.Join(from, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := os.Create(filepath.Join(to, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			t.Fatal(err)
+		}
+		src.Close()
+		dst.Close()
+	}
+}
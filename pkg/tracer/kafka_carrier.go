@@ -5,6 +5,7 @@ import (
 
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -39,17 +40,41 @@ func (c KafkaHeadersCarrier) Keys() []string {
 	return keys
 }
 
-func StartKafkaConsumerTracerSpan(tracer trace.Tracer, consumerCtx context.Context, headers *[]kafka.Header, operationName string) (context.Context, trace.Span) {
-	propagatedCtx := otel.GetTextMapPropagator().Extract(consumerCtx, KafkaHeadersCarrier{Headers: headers})
+func StartKafkaConsumerTracerSpan(tracer trace.Tracer, consumerCtx context.Context, msg *kafka.Message, operationName string) (context.Context, trace.Span) {
+	propagatedCtx := otel.GetTextMapPropagator().Extract(consumerCtx, KafkaHeadersCarrier{Headers: &msg.Headers})
 
 	consumerCtx, span := tracer.Start(
 		trace.ContextWithRemoteSpanContext(consumerCtx, trace.SpanContextFromContext(propagatedCtx)),
 		operationName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingSourceKindTopic,
+			semconv.MessagingSourceNameKey.String(msg.Topic),
+			semconv.MessagingKafkaSourcePartitionKey.Int(msg.Partition),
+			semconv.MessagingKafkaMessageOffsetKey.Int64(msg.Offset),
+			semconv.MessagingMessagePayloadSizeBytesKey.Int(len(msg.Value)),
+		),
 	)
 
 	return consumerCtx, span
 }
 
+func StartKafkaProducerTracerSpan(tracer trace.Tracer, producerCtx context.Context, topic string, operationName string) (context.Context, trace.Span) {
+	return tracer.Start(
+		producerCtx,
+		operationName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("kafka"),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationKindTopic,
+			semconv.MessagingDestinationNameKey.String(topic),
+		),
+	)
+}
+
 func InjectKafkaTracingHeadersToCarrier(producerCtx context.Context, headers *[]kafka.Header) {
 	otel.GetTextMapPropagator().Inject(producerCtx, KafkaHeadersCarrier{Headers: headers})
 }
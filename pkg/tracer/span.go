@@ -0,0 +1,55 @@
+package tracer
+
+import (
+	"git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ChannelKey             = attribute.Key("cns.channel")
+	CategoryKey            = attribute.Key("cns.category")
+	TypeKey                = attribute.Key("cns.type")
+	ProviderKey            = attribute.Key("cns.provider")
+	ProviderResultCodeKey  = attribute.Key("cns.provider.result_code")
+	ProviderMessageIDKey   = attribute.Key("cns.provider.message_id")
+	AttemptKey             = attribute.Key("cns.attempt")
+	RecipientsSucceededKey = attribute.Key("cns.recipients.succeeded")
+	RecipientsFailedKey    = attribute.Key("cns.recipients.failed")
+	RecipientsInvalidKey   = attribute.Key("cns.recipients.invalid")
+	OperatorKey            = attribute.Key("cns.recipient.operator")
+	SmsEncodingKey         = attribute.Key("cns.sms.encoding")
+	SmsSegmentsKey         = attribute.Key("cns.sms.segments")
+	SmsLengthActionKey     = attribute.Key("cns.sms.length_action")
+)
+
+// RecordError marks the span as failed and returns err so it can be used
+// inline in return statements. Phone numbers and email addresses in the
+// message are masked the same way as in the logs.
+func RecordError(span trace.Span, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	masked := &maskedError{err: err, message: logger.MaskText(err.Error())}
+	span.RecordError(masked)
+	span.SetStatus(codes.Error, masked.message)
+
+	return err
+}
+
+// maskedError carries the masked message of err to the span exporter.
+type maskedError struct {
+	err     error
+	message string
+}
+
+func (e *maskedError) Error() string {
+	return e.message
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
@@ -3,9 +3,16 @@ package tracer
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+
+	otlpEndpoint "git.bpjsketenagakerjaan.go.id/centralized-notification-system/dispatch-service/pkg/otlp_endpoint"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
@@ -13,9 +20,20 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	ExporterJaeger   = "jaeger"
+	ExporterOtlpGrpc = "otlp-grpc"
+	ExporterOtlpHttp = "otlp-http"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
 type Config struct {
-	Endpoint string `mapstructure:"ENDPOINT"`
-	Prefix   string `mapstructure:"PREFIX"`
+	Exporter      string `mapstructure:"EXPORTER"`
+	Endpoint      string `mapstructure:"ENDPOINT"`
+	Insecure      string `mapstructure:"INSECURE"`
+	SamplingRatio string `mapstructure:"SAMPLING_RATIO"`
+	Prefix        string `mapstructure:"PREFIX"`
 }
 
 type AppTracer struct {
@@ -23,17 +41,10 @@ type AppTracer struct {
 	Tracer        trace.Tracer
 }
 
+// NewAppTracer never fails because of the exporter, a tracing backend outage
+// falls back to a provider that keeps propagating context without exporting.
 func NewAppTracer(ctx context.Context, cfg *Config, serviceName, version string) (*AppTracer, error) {
-	if cfg.Endpoint == "" {
-		return nil, fmt.Errorf("endpoint is missing in the tracer configuration")
-	}
-
-	exporter, err := jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.Endpoint)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Jaeger exporter: %w", err)
-	}
-
-	resource, err := resource.New(
+	res, err := resource.New(
 		ctx,
 		resource.WithProcess(),
 		resource.WithTelemetrySDK(),
@@ -44,15 +55,27 @@ func NewAppTracer(ctx context.Context, cfg *Config, serviceName, version string)
 		),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+		fmt.Println("Failed to create tracer resource:", err)
+		res = resource.Default()
 	}
 
-	batchSpanProcessor := sdkTrace.NewBatchSpanProcessor(exporter)
+	options := []sdkTrace.TracerProviderOption{
+		sdkTrace.WithResource(res),
+		sdkTrace.WithSampler(newSampler(cfg)),
+	}
 
-	tracerProvider := sdkTrace.NewTracerProvider(
-		sdkTrace.WithSpanProcessor(batchSpanProcessor),
-		sdkTrace.WithResource(resource),
-	)
+	exporter, err := newExporter(ctx, cfg)
+	switch {
+	case err != nil:
+		fmt.Println("Failed to initialize tracer exporter, spans will not be exported:", err)
+	case exporter == nil:
+		fmt.Println("Tracer exporter disabled, spans will not be exported")
+	default:
+		options = append(options, sdkTrace.WithSpanProcessor(sdkTrace.NewBatchSpanProcessor(exporter)))
+		fmt.Println("Tracer initialized successfully. Exporter:", cfg.Exporter, "Endpoint:", cfg.Endpoint)
+	}
+
+	tracerProvider := sdkTrace.NewTracerProvider(options...)
 
 	at := &AppTracer{
 		TraceProvider: tracerProvider,
@@ -62,7 +85,54 @@ func NewAppTracer(ctx context.Context, cfg *Config, serviceName, version string)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	otel.SetTracerProvider(tracerProvider)
 
-	fmt.Println("Tracer initialized successfully. Endpoint:", cfg.Endpoint)
-
 	return at, nil
 }
+
+func newExporter(ctx context.Context, cfg *Config) (sdkTrace.SpanExporter, error) {
+	exporter := strings.ToLower(cfg.Exporter)
+
+	if exporter != "" && exporter != ExporterNone && exporter != ExporterStdout && cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is missing in the tracer configuration")
+	}
+
+	switch exporter {
+	case ExporterJaeger:
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.Endpoint)))
+
+	case ExporterOtlpGrpc:
+		endpoint, _, insecure := otlpEndpoint.Parse(cfg.Endpoint, cfg.Insecure)
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+		if insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, options...)
+
+	case ExporterOtlpHttp:
+		endpoint, path, insecure := otlpEndpoint.Parse(cfg.Endpoint, cfg.Insecure)
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+		if path != "" {
+			options = append(options, otlptracehttp.WithURLPath(path))
+		}
+		if insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+
+	case ExporterNone, "":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unsupported tracer exporter: %s", cfg.Exporter)
+}
+
+func newSampler(cfg *Config) sdkTrace.Sampler {
+	ratio, err := strconv.ParseFloat(cfg.SamplingRatio, 64)
+	if err != nil {
+		ratio = 1
+	}
+
+	return sdkTrace.ParentBased(sdkTrace.TraceIDRatioBased(ratio))
+}
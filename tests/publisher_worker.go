@@ -27,7 +27,7 @@ func main() {
 	defer cancel()
 
 	cfg := config.LoadConfigFromOS()
-	log := logger.NewAppLogger()
+	log := logger.NewAppLogger(cfg.Logger)
 
 	at, err := tracerClient.NewAppTracer(ctx, cfg.Tracer, cfg.Project.ServiceName, cfg.Project.Version)
 	if err != nil {
@@ -48,7 +48,12 @@ func main() {
 	topics := strings.Split("cns_dsp_jmo_email_reg,cns_dsp_jmo_sms_reg,cns_dsp_jmo_inapp_reg,cns_dsp_jmo_push_reg", ",")
 	fmt.Printf("Brokers: %v Topics: %+v\n", producerBroker, topics)
 
-	kafkaConn, err := kafka.DialContext(ctx, "tcp", producerBroker[0])
+	auth, err := kafkaClient.NewAuth(cfg.Kafka.ConsumerSecurity)
+	if err != nil {
+		panic(err)
+	}
+
+	kafkaConn, err := auth.Dialer("").DialContext(ctx, "tcp", producerBroker[0])
 	if err != nil {
 		panic(err)
 	}
@@ -59,7 +64,7 @@ func main() {
 	var producers []*kafkaClient.Producer
 
 	for _, v := range topics {
-		producer := kafkaClient.NewProducer(producerBroker, v)
+		producer := kafkaClient.NewProducer(producerBroker, v, auth)
 		defer producer.Close()
 
 		producers = append(producers, producer)